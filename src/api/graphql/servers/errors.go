@@ -0,0 +1,86 @@
+package servers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// codes are the values of the "code" error extension, keyed by the HTTP
+// status a service reported.
+var codes = map[int]string{
+	http.StatusBadRequest:          "BAD_REQUEST",
+	http.StatusUnauthorized:        "UNAUTHENTICATED",
+	http.StatusForbidden:           "FORBIDDEN",
+	http.StatusNotFound:            "NOT_FOUND",
+	http.StatusConflict:            "CONFLICT",
+	http.StatusUnprocessableEntity: "BAD_REQUEST",
+	http.StatusServiceUnavailable:  "UNAVAILABLE",
+}
+
+// serviceError is a failed service response surfaced as a GraphQL error,
+// carrying the status in its extensions.
+type serviceError struct {
+	message string
+	status  int
+}
+
+func (e *serviceError) Error() string {
+	return e.message
+}
+
+func (e *serviceError) Extensions() map[string]interface{} {
+	code, ok := codes[e.status]
+	if !ok {
+		code = "INTERNAL"
+	}
+	return map[string]interface{}{"code": code, "status": e.status}
+}
+
+// responseError turns a failed service response into a GraphQL error and
+// returns nil for a successful one. The detail only reaches the caller for
+// client errors: for server errors, which may carry driver internals, it is
+// logged instead.
+func responseError(ctx context.Context, httpStatus int, message string, detail string) error {
+	if httpStatus < http.StatusBadRequest {
+		return nil
+	}
+	if detail != "" && detail != "NA" {
+		if httpStatus >= http.StatusInternalServerError {
+			slog.ErrorContext(ctx, "graphql: service failed", "status", httpStatus, "message", message, "error", detail)
+		} else {
+			message += ": " + detail
+		}
+	}
+	return &serviceError{message: message, status: httpStatus}
+}
+
+func isNotFound(err error) bool {
+	serviceErr, ok := err.(*serviceError)
+	return ok && serviceErr.status == http.StatusNotFound
+}
+
+// page is one {total_count, items} document from a paginated list service.
+type page[T any] struct {
+	User_id     string `bson:"user_id"`
+	Total_count int    `bson:"total_count"`
+	Items       []T    `bson:"items"`
+}
+
+// decodePages converts the []bson.M a list service returns into typed pages.
+func decodePages[T any](data any) ([]page[T], error) {
+	if data == nil {
+		return nil, nil
+	}
+	raw, err := bson.Marshal(bson.M{"pages": data})
+	if err != nil {
+		return nil, err
+	}
+	var list struct {
+		Pages []page[T] `bson:"pages"`
+	}
+	err = bson.Unmarshal(raw, &list)
+	return list.Pages, err
+}
@@ -0,0 +1,54 @@
+package servers
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestResponseError(t *testing.T) {
+	const driverErr = "connection(mongo-0:27017[-3]) incomplete read of message header: EOF"
+
+	tests := []struct {
+		name    string
+		status  int
+		detail  string
+		code    string
+		message string
+		logged  bool
+	}{
+		{name: "not found", status: http.StatusNotFound, detail: "NA", code: "NOT_FOUND", message: "Customer not found"},
+		{name: "client error detail", status: http.StatusBadRequest, detail: "first_name is required", code: "BAD_REQUEST", message: "Customer not found: first_name is required"},
+		{name: "server error", status: http.StatusInternalServerError, detail: driverErr, code: "INTERNAL", message: "Customer not found", logged: true},
+		{name: "unavailable", status: http.StatusServiceUnavailable, detail: driverErr, code: "UNAVAILABLE", message: "Customer not found", logged: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			defer slog.SetDefault(slog.Default())
+			slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+
+			err := responseError(context.Background(), test.status, "Customer not found", test.detail)
+			serviceErr, ok := err.(*serviceError)
+			if !ok {
+				t.Fatalf("error = %#v, want a *serviceError", err)
+			}
+			if serviceErr.Error() != test.message {
+				t.Errorf("message = %q, want %q", serviceErr.Error(), test.message)
+			}
+			if code := serviceErr.Extensions()["code"]; code != test.code {
+				t.Errorf("code = %v, want %s", code, test.code)
+			}
+			if logged := strings.Contains(logs.String(), driverErr); logged != test.logged {
+				t.Errorf("driver error logged = %v, want %v", logged, test.logged)
+			}
+		})
+	}
+
+	if err := responseError(context.Background(), http.StatusOK, "Customer Added Successfully", "NA"); err != nil {
+		t.Errorf("a successful response gave %v", err)
+	}
+}
@@ -0,0 +1,167 @@
+package servers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// pageArgument is the argument whose value multiplies the cost of a list
+// field's selection, since each requested record resolves it again.
+const pageArgument = "recordPerPage"
+
+// checkLimits rejects an operation nested deeper than MaxDepth or whose
+// estimated cost exceeds MaxComplexity. Introspection fields are not counted
+// so tooling can still load the schema.
+func checkLimits(document *ast.Document, operationName string, variables map[string]any, options Options) error {
+	fragments := map[string]*ast.FragmentDefinition{}
+	var operation *ast.OperationDefinition
+	for _, definition := range document.Definitions {
+		switch definition := definition.(type) {
+		case *ast.FragmentDefinition:
+			fragments[definition.Name.Value] = definition
+		case *ast.OperationDefinition:
+			if operationName == "" || (definition.Name != nil && definition.Name.Value == operationName) {
+				operation = definition
+			}
+		}
+	}
+	if operation == nil {
+		// Leave reporting an unknown or ambiguous operation to the executor.
+		return nil
+	}
+
+	measure := limitWalker{fragments: fragments, variables: variables}
+	depth, complexity := measure.selectionSet(operation.SelectionSet, map[string]bool{})
+	if options.MaxDepth > 0 && depth > options.MaxDepth {
+		return fmt.Errorf("query depth %d exceeds the limit of %d", depth, options.MaxDepth)
+	}
+	if options.MaxComplexity > 0 && complexity > options.MaxComplexity {
+		return fmt.Errorf("query complexity %d exceeds the limit of %d", complexity, options.MaxComplexity)
+	}
+	return nil
+}
+
+type limitWalker struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]any
+}
+
+// selectionSet returns the depth and complexity of a selection set. visiting
+// holds the fragments on the current path; validation already rejects cycles
+// but the guard keeps the walk safe regardless.
+func (w limitWalker) selectionSet(set *ast.SelectionSet, visiting map[string]bool) (depth int, complexity int) {
+	if set == nil {
+		return 0, 0
+	}
+	for _, selection := range set.Selections {
+		var childDepth, childComplexity int
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(selection.Name.Value, "__") {
+				continue
+			}
+			childDepth, childComplexity = w.selectionSet(selection.SelectionSet, visiting)
+			childDepth++
+			childComplexity = addCost(1, mulCost(childComplexity, w.multiplier(selection)))
+		case *ast.InlineFragment:
+			childDepth, childComplexity = w.selectionSet(selection.SelectionSet, visiting)
+		case *ast.FragmentSpread:
+			name := selection.Name.Value
+			fragment, ok := w.fragments[name]
+			if !ok || visiting[name] {
+				continue
+			}
+			visiting[name] = true
+			childDepth, childComplexity = w.selectionSet(fragment.SelectionSet, visiting)
+			delete(visiting, name)
+		}
+		if childDepth > depth {
+			depth = childDepth
+		}
+		complexity = addCost(complexity, childComplexity)
+	}
+	return depth, complexity
+}
+
+// addCost and mulCost saturate at math.MaxInt rather than wrapping, so a
+// huge estimate cannot overflow into one under the limit. Costs are never
+// negative.
+func addCost(a int, b int) int {
+	if a > math.MaxInt-b {
+		return math.MaxInt
+	}
+	return a + b
+}
+
+func mulCost(a int, b int) int {
+	if a != 0 && b > math.MaxInt/a {
+		return math.MaxInt
+	}
+	return a * b
+}
+
+// multiplier is the number of records a list field asks for, clamped the way
+// paging clamps it for the resolvers.
+func (w limitWalker) multiplier(field *ast.Field) int {
+	for _, argument := range field.Arguments {
+		if argument.Name.Value != pageArgument {
+			continue
+		}
+		var value any
+		switch argumentValue := argument.Value.(type) {
+		case *ast.IntValue:
+			value = argumentValue.Value
+		case *ast.Variable:
+			value = w.variables[argumentValue.Name.Value]
+		}
+		if count := intValue(value); count > 0 {
+			return int(min(count, maxRecordPerPage))
+		}
+	}
+	if _, ok := pageArgumentFields[field.Name.Value]; ok {
+		return defaultRecordPerPage
+	}
+	return 1
+}
+
+// intValue reads an argument value as a literal, as a variable decoded by
+// encoding/json with or without UseNumber, or as a coerced int. Values out of
+// range saturate, anything else is 0.
+func intValue(value any) int64 {
+	switch value := value.(type) {
+	case string:
+		parsed, _ := strconv.ParseInt(value, 10, 64)
+		return parsed
+	case json.Number:
+		parsed, err := value.Int64()
+		if err != nil {
+			float, _ := value.Float64()
+			return floatValue(float)
+		}
+		return parsed
+	case int:
+		return int64(value)
+	case int64:
+		return value
+	case float64:
+		return floatValue(value)
+	}
+	return 0
+}
+
+func floatValue(value float64) int64 {
+	switch {
+	case math.IsNaN(value):
+		return 0
+	case value >= math.MaxInt64:
+		return math.MaxInt64
+	case value <= math.MinInt64:
+		return math.MinInt64
+	}
+	return int64(value)
+}
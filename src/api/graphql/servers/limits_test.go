@@ -0,0 +1,65 @@
+package servers
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+func TestCheckLimits(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]any
+		rejected  bool
+	}{
+		{name: "default page", query: `{ users { items { userId } } }`},
+		{name: "nested default pages", query: `{ users { items { customers { items { customerId } } } } }`},
+		{name: "page sizes overflowing the estimate", query: `{ users(recordPerPage: 2147483647) { items { customers(recordPerPage: 2147483647) { items { customerId firstName lastName } } } } }`, rejected: true},
+		{name: "nested maximum pages", query: `{ users(recordPerPage: 100) { items { customers(recordPerPage: 100) { items { customerId } } } } }`, rejected: true},
+		{name: "json.Number variable", query: `query($n: Int) { users(recordPerPage: $n) { items { customers(recordPerPage: $n) { items { customerId } } } } }`, variables: map[string]any{"n": json.Number("2147483647")}, rejected: true},
+		{name: "float64 variable", query: `query($n: Int) { users(recordPerPage: $n) { items { customers(recordPerPage: $n) { items { customerId } } } } }`, variables: map[string]any{"n": 1e300}, rejected: true},
+		{name: "too deep", query: `{ a { b { c { d { e { f { g { h { i { j { k } } } } } } } } } } }`, rejected: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			document, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(test.query)})})
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = checkLimits(document, "", test.variables, DefaultOptions())
+			if (err != nil) != test.rejected {
+				t.Errorf("checkLimits() = %v, want rejected %v", err, test.rejected)
+			}
+		})
+	}
+}
+
+func TestCostsSaturate(t *testing.T) {
+	if got := mulCost(math.MaxInt/2, 3); got != math.MaxInt {
+		t.Errorf("mulCost = %d, want math.MaxInt", got)
+	}
+	if got := addCost(math.MaxInt, 1); got != math.MaxInt {
+		t.Errorf("addCost = %d, want math.MaxInt", got)
+	}
+}
+
+func TestPagingClampsRecordPerPage(t *testing.T) {
+	tests := []struct {
+		args map[string]interface{}
+		want pageKey
+	}{
+		{args: map[string]interface{}{}, want: pageKey{page: 1, recordPerPage: defaultRecordPerPage}},
+		{args: map[string]interface{}{"page": 3, "recordPerPage": 25}, want: pageKey{page: 3, recordPerPage: 25}},
+		{args: map[string]interface{}{"page": 0, "recordPerPage": -5}, want: pageKey{page: 1, recordPerPage: defaultRecordPerPage}},
+		{args: map[string]interface{}{"recordPerPage": 2147483647}, want: pageKey{page: 1, recordPerPage: maxRecordPerPage}},
+	}
+	for _, test := range tests {
+		if got := paging(test.args); got != test.want {
+			t.Errorf("paging(%v) = %+v, want %+v", test.args, got, test.want)
+		}
+	}
+}
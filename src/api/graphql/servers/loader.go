@@ -0,0 +1,98 @@
+package servers
+
+import (
+	"context"
+	"sync"
+
+	customerInterfaces "somdeep-demo-app/src/customer/interfaces"
+	"somdeep-demo-app/src/customer/models"
+)
+
+// pageKey identifies one page shape; users asking for the same page of their
+// customers are fetched together.
+type pageKey struct {
+	page          int
+	recordPerPage int
+}
+
+// customerLoader batches User.customers lookups. Each Load registers the user
+// and returns a thunk; graphql-go resolves thunks breadth first, so by the
+// time the first one runs every user on the current level has registered and
+// a single GetCustomersByUserIds call serves them all. The loader lives for
+// one request and fetches with that request's context.
+type customerLoader struct {
+	ctx             context.Context
+	customerService customerInterfaces.CustomerService
+
+	mu      sync.Mutex
+	pending map[pageKey][]string
+	loaded  map[pageKey]map[string]*page[models.Customer]
+	errors  map[pageKey]error
+}
+
+func newCustomerLoader(ctx context.Context, customerService customerInterfaces.CustomerService) *customerLoader {
+	return &customerLoader{
+		ctx:             ctx,
+		customerService: customerService,
+		pending:         map[pageKey][]string{},
+		loaded:          map[pageKey]map[string]*page[models.Customer]{},
+		errors:          map[pageKey]error{},
+	}
+}
+
+func (l *customerLoader) Load(userId string, key pageKey) func() (any, error) {
+	l.mu.Lock()
+	l.pending[key] = append(l.pending[key], userId)
+	l.mu.Unlock()
+
+	return func() (any, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if userIds := l.pending[key]; len(userIds) > 0 {
+			delete(l.pending, key)
+			l.fetch(key, userIds)
+		}
+		if err := l.errors[key]; err != nil {
+			return nil, err
+		}
+		customers, ok := l.loaded[key][userId]
+		if !ok {
+			customers = &page[models.Customer]{}
+		}
+		return customerConnection(customers, key), nil
+	}
+}
+
+// fetch must be called with l.mu held.
+func (l *customerLoader) fetch(key pageKey, userIds []string) {
+	startIndex := (key.page - 1) * key.recordPerPage
+	response, _ := l.customerService.GetCustomersByUserIds(l.ctx, unique(userIds), key.recordPerPage, key.page, startIndex)
+	if err := responseError(l.ctx, response.Status, response.Message, response.Error); err != nil {
+		l.errors[key] = err
+		return
+	}
+	pages, err := decodePages[models.Customer](response.Data)
+	if err != nil {
+		l.errors[key] = err
+		return
+	}
+
+	if l.loaded[key] == nil {
+		l.loaded[key] = map[string]*page[models.Customer]{}
+	}
+	for i := range pages {
+		l.loaded[key][pages[i].User_id] = &pages[i]
+	}
+}
+
+func unique(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	return result
+}
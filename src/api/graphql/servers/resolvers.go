@@ -0,0 +1,172 @@
+package servers
+
+import (
+	"context"
+	customerModels "somdeep-demo-app/src/customer/models"
+	userModels "somdeep-demo-app/src/user/models"
+
+	"github.com/graphql-go/graphql"
+)
+
+func (s *Server) resolveUsers(p graphql.ResolveParams) (interface{}, error) {
+	key := paging(p.Args)
+	response, _ := s.userService.GetUsers(p.Context, key.recordPerPage, key.page, (key.page-1)*key.recordPerPage)
+	if err := responseError(p.Context, response.Status, response.Message, response.Error); err != nil {
+		return nil, err
+	}
+	users, err := firstPage[userModels.User](response.Data)
+	if err != nil {
+		return nil, err
+	}
+	return userConnection(users, key), nil
+}
+
+func (s *Server) resolveUser(p graphql.ResolveParams) (interface{}, error) {
+	return s.getUser(p.Context, stringArg(p.Args, "userId"), true)
+}
+
+func (s *Server) resolveUserCustomers(p graphql.ResolveParams) (interface{}, error) {
+	user, _ := p.Source.(map[string]interface{})
+	userId, _ := user["userId"].(string)
+	return stateFrom(p.Context).customers.Load(userId, paging(p.Args)), nil
+}
+
+func (s *Server) resolveCustomers(p graphql.ResolveParams) (interface{}, error) {
+	key := paging(p.Args)
+	response, _ := s.customerService.GetAllCustomers(p.Context, key.recordPerPage, key.page, (key.page-1)*key.recordPerPage)
+	if err := responseError(p.Context, response.Status, response.Message, response.Error); err != nil {
+		return nil, err
+	}
+	customers, err := firstPage[customerModels.Customer](response.Data)
+	if err != nil {
+		return nil, err
+	}
+	return customerConnection(customers, key), nil
+}
+
+func (s *Server) resolveCustomer(p graphql.ResolveParams) (interface{}, error) {
+	return s.getCustomer(p.Context, stringArg(p.Args, "userId"), stringArg(p.Args, "customerId"), true)
+}
+
+func (s *Server) createUser(p graphql.ResolveParams) (interface{}, error) {
+	input, _ := p.Args["input"].(map[string]interface{})
+	user := userModels.User{
+		First_name: optionalString(input, "firstName"),
+		Last_name:  optionalString(input, "lastName"),
+		Password:   optionalString(input, "password"),
+		Email:      optionalString(input, "email"),
+		Phone:      optionalString(input, "phone"),
+	}
+	response, err := s.userService.AddUser(p.Context, stateFrom(p.Context).actor, user)
+	if statusErr := responseError(p.Context, response.Status, response.Message, response.Error); statusErr != nil {
+		return nil, statusErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	created, _ := response.Data.(userModels.UserRef)
+	return s.getUser(p.Context, created.User_id, false)
+}
+
+func (s *Server) updateUser(p graphql.ResolveParams) (interface{}, error) {
+	userId := stringArg(p.Args, "userId")
+	input, _ := p.Args["input"].(map[string]interface{})
+	user := userModels.User{
+		First_name: optionalString(input, "firstName"),
+		Last_name:  optionalString(input, "lastName"),
+	}
+	response, _ := s.userService.UpdateUser(p.Context, stateFrom(p.Context).actor, userId, user)
+	if err := responseError(p.Context, response.Status, response.Message, response.Error); err != nil {
+		return nil, err
+	}
+	return s.getUser(p.Context, userId, false)
+}
+
+func (s *Server) deleteUser(p graphql.ResolveParams) (interface{}, error) {
+	response, _ := s.userService.DeleteUser(p.Context, stateFrom(p.Context).actor, stringArg(p.Args, "userId"))
+	if err := responseError(p.Context, response.Status, response.Message, response.Error); err != nil {
+		return nil, err
+	}
+	return true, nil
+}
+
+func (s *Server) createCustomer(p graphql.ResolveParams) (interface{}, error) {
+	userId := stringArg(p.Args, "userId")
+	input, _ := p.Args["input"].(map[string]interface{})
+	customer := customerModels.Customer{
+		First_name: optionalString(input, "firstName"),
+		Last_name:  optionalString(input, "lastName"),
+	}
+	response, err := s.customerService.AddCustomerByUserId(p.Context, stateFrom(p.Context).actor, userId, customer)
+	if statusErr := responseError(p.Context, response.Status, response.Message, response.Error); statusErr != nil {
+		return nil, statusErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	created, _ := response.Data.(customerModels.CustomerRef)
+	return s.getCustomer(p.Context, userId, created.Customer_id, false)
+}
+
+func (s *Server) updateCustomer(p graphql.ResolveParams) (interface{}, error) {
+	userId := stringArg(p.Args, "userId")
+	customerId := stringArg(p.Args, "customerId")
+	input, _ := p.Args["input"].(map[string]interface{})
+	customer := customerModels.Customer{
+		First_name: optionalString(input, "firstName"),
+		Last_name:  optionalString(input, "lastName"),
+	}
+	response, _ := s.customerService.UpdateCustomerByCustomerId(p.Context, stateFrom(p.Context).actor, userId, customerId, customer)
+	if err := responseError(p.Context, response.Status, response.Message, response.Error); err != nil {
+		return nil, err
+	}
+	return s.getCustomer(p.Context, userId, customerId, false)
+}
+
+func (s *Server) deleteCustomer(p graphql.ResolveParams) (interface{}, error) {
+	response, _ := s.customerService.DeleteCustomerByCustomerId(p.Context, stateFrom(p.Context).actor, stringArg(p.Args, "userId"), stringArg(p.Args, "customerId"))
+	if err := responseError(p.Context, response.Status, response.Message, response.Error); err != nil {
+		return nil, err
+	}
+	return true, nil
+}
+
+func (s *Server) deleteCustomers(p graphql.ResolveParams) (interface{}, error) {
+	response, _ := s.customerService.DeleteCustomersByUserId(p.Context, stateFrom(p.Context).actor, stringArg(p.Args, "userId"))
+	if err := responseError(p.Context, response.Status, response.Message, response.Error); err != nil {
+		return nil, err
+	}
+	return true, nil
+}
+
+// getUser fetches a user for a query or to return from a mutation. Queries
+// resolve a missing user to null; mutations report it.
+func (s *Server) getUser(ctx context.Context, userId string, nullIfMissing bool) (interface{}, error) {
+	response, _ := s.userService.GetUser(ctx, userId)
+	if err := responseError(ctx, response.Status, response.Message, response.Error); err != nil {
+		if nullIfMissing && isNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	user, ok := response.Data.(userModels.User)
+	if !ok {
+		return nil, errNoData
+	}
+	return userObject(user), nil
+}
+
+func (s *Server) getCustomer(ctx context.Context, userId string, customerId string, nullIfMissing bool) (interface{}, error) {
+	response, _ := s.customerService.GetCustomerByCustomerId(ctx, userId, customerId)
+	if err := responseError(ctx, response.Status, response.Message, response.Error); err != nil {
+		if nullIfMissing && isNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	customer, ok := response.Data.(customerModels.Customer)
+	if !ok {
+		return nil, errNoData
+	}
+	return customerObject(customer), nil
+}
@@ -0,0 +1,275 @@
+package servers
+
+import (
+	"errors"
+	"strconv"
+
+	customerModels "somdeep-demo-app/src/customer/models"
+	userModels "somdeep-demo-app/src/user/models"
+
+	"github.com/graphql-go/graphql"
+)
+
+const (
+	defaultRecordPerPage = 10
+	// maxRecordPerPage caps the records a list field returns per page, so a
+	// single query cannot ask for the whole collection.
+	maxRecordPerPage = 100
+)
+
+// pageArgumentFields are the list fields that fall back to
+// defaultRecordPerPage when the query does not say how many records it wants.
+var pageArgumentFields = map[string]struct{}{
+	"users":     {},
+	"customers": {},
+}
+
+func pageArguments() graphql.FieldConfigArgument {
+	return graphql.FieldConfigArgument{
+		"page":          &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 1},
+		"recordPerPage": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultRecordPerPage, Description: "Page size, at most " + strconv.Itoa(maxRecordPerPage)},
+	}
+}
+
+func connectionType(name string, item *graphql.Object) *graphql.Object {
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: name,
+		Fields: graphql.Fields{
+			"totalCount":    &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"page":          &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"recordPerPage": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"hasNextPage":   &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"items":         &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(item)))},
+		},
+	})
+}
+
+func (s *Server) newSchema() (graphql.Schema, error) {
+	customerType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Customer",
+		Fields: graphql.Fields{
+			"customerId": &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"userId":     &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"firstName":  &graphql.Field{Type: graphql.String},
+			"lastName":   &graphql.Field{Type: graphql.String},
+			"createdAt":  &graphql.Field{Type: graphql.DateTime},
+			"updatedAt":  &graphql.Field{Type: graphql.DateTime},
+		},
+	})
+	customerConnectionType := connectionType("CustomerConnection", customerType)
+
+	userType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "User",
+		Fields: graphql.Fields{
+			"userId":    &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"firstName": &graphql.Field{Type: graphql.String},
+			"lastName":  &graphql.Field{Type: graphql.String},
+			"email":     &graphql.Field{Type: graphql.String},
+			"phone":     &graphql.Field{Type: graphql.String},
+			"createdAt": &graphql.Field{Type: graphql.DateTime},
+			"updatedAt": &graphql.Field{Type: graphql.DateTime},
+			"customers": &graphql.Field{
+				Type:    graphql.NewNonNull(customerConnectionType),
+				Args:    pageArguments(),
+				Resolve: s.resolveUserCustomers,
+			},
+		},
+	})
+	userConnectionType := connectionType("UserConnection", userType)
+
+	createUserInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "CreateUserInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"firstName": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"lastName":  &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"password":  &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"email":     &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"phone":     &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+	nameInput := func(name string) *graphql.InputObject {
+		return graphql.NewInputObject(graphql.InputObjectConfig{
+			Name: name,
+			Fields: graphql.InputObjectConfigFieldMap{
+				"firstName": &graphql.InputObjectFieldConfig{Type: graphql.String},
+				"lastName":  &graphql.InputObjectFieldConfig{Type: graphql.String},
+			},
+		})
+	}
+	updateUserInput := nameInput("UpdateUserInput")
+	updateCustomerInput := nameInput("UpdateCustomerInput")
+	createCustomerInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "CreateCustomerInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"firstName": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"lastName":  &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+
+	id := func() *graphql.ArgumentConfig {
+		return &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)}
+	}
+	input := func(inputType *graphql.InputObject) *graphql.ArgumentConfig {
+		return &graphql.ArgumentConfig{Type: graphql.NewNonNull(inputType)}
+	}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"users": &graphql.Field{
+				Type:    graphql.NewNonNull(userConnectionType),
+				Args:    pageArguments(),
+				Resolve: s.resolveUsers,
+			},
+			"user": &graphql.Field{
+				Type:    userType,
+				Args:    graphql.FieldConfigArgument{"userId": id()},
+				Resolve: s.resolveUser,
+			},
+			"customers": &graphql.Field{
+				Type:    graphql.NewNonNull(customerConnectionType),
+				Args:    pageArguments(),
+				Resolve: s.resolveCustomers,
+			},
+			"customer": &graphql.Field{
+				Type:    customerType,
+				Args:    graphql.FieldConfigArgument{"userId": id(), "customerId": id()},
+				Resolve: s.resolveCustomer,
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createUser": &graphql.Field{
+				Type:    graphql.NewNonNull(userType),
+				Args:    graphql.FieldConfigArgument{"input": input(createUserInput)},
+				Resolve: s.createUser,
+			},
+			"updateUser": &graphql.Field{
+				Type:    graphql.NewNonNull(userType),
+				Args:    graphql.FieldConfigArgument{"userId": id(), "input": input(updateUserInput)},
+				Resolve: s.updateUser,
+			},
+			"deleteUser": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.Boolean),
+				Args:    graphql.FieldConfigArgument{"userId": id()},
+				Resolve: s.deleteUser,
+			},
+			"createCustomer": &graphql.Field{
+				Type:    graphql.NewNonNull(customerType),
+				Args:    graphql.FieldConfigArgument{"userId": id(), "input": input(createCustomerInput)},
+				Resolve: s.createCustomer,
+			},
+			"updateCustomer": &graphql.Field{
+				Type:    graphql.NewNonNull(customerType),
+				Args:    graphql.FieldConfigArgument{"userId": id(), "customerId": id(), "input": input(updateCustomerInput)},
+				Resolve: s.updateCustomer,
+			},
+			"deleteCustomer": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.Boolean),
+				Args:    graphql.FieldConfigArgument{"userId": id(), "customerId": id()},
+				Resolve: s.deleteCustomer,
+			},
+			"deleteCustomers": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.Boolean),
+				Args:    graphql.FieldConfigArgument{"userId": id()},
+				Resolve: s.deleteCustomers,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:    query,
+		Mutation: mutation,
+	})
+}
+
+// paging reads the page arguments, clamping them the way the HTTP handlers
+// clamp their query parameters, and recordPerPage to maxRecordPerPage.
+func paging(args map[string]interface{}) pageKey {
+	key := pageKey{page: 1, recordPerPage: defaultRecordPerPage}
+	if page, ok := args["page"].(int); ok && page > 0 {
+		key.page = page
+	}
+	if recordPerPage, ok := args["recordPerPage"].(int); ok && recordPerPage > 0 {
+		key.recordPerPage = min(recordPerPage, maxRecordPerPage)
+	}
+	return key
+}
+
+func connection[T any](p *page[T], key pageKey, item func(T) map[string]interface{}) map[string]interface{} {
+	items := make([]interface{}, len(p.Items))
+	for i, value := range p.Items {
+		items[i] = item(value)
+	}
+	return map[string]interface{}{
+		"totalCount":    p.Total_count,
+		"page":          key.page,
+		"recordPerPage": key.recordPerPage,
+		"hasNextPage":   key.page*key.recordPerPage < p.Total_count,
+		"items":         items,
+	}
+}
+
+func userConnection(p *page[userModels.User], key pageKey) map[string]interface{} {
+	return connection(p, key, userObject)
+}
+
+func customerConnection(p *page[customerModels.Customer], key pageKey) map[string]interface{} {
+	return connection(p, key, customerObject)
+}
+
+func userObject(user userModels.User) map[string]interface{} {
+	return map[string]interface{}{
+		"userId":    user.User_id,
+		"firstName": user.First_name,
+		"lastName":  user.Last_name,
+		"email":     user.Email,
+		"phone":     user.Phone,
+		"createdAt": user.Created_at,
+		"updatedAt": user.Updated_at,
+	}
+}
+
+func customerObject(customer customerModels.Customer) map[string]interface{} {
+	return map[string]interface{}{
+		"customerId": customer.Customer_id,
+		"userId":     customer.User_id,
+		"firstName":  customer.First_name,
+		"lastName":   customer.Last_name,
+		"createdAt":  customer.Created_at,
+		"updatedAt":  customer.Updated_at,
+	}
+}
+
+// firstPage picks the single {total_count, items} page a list service
+// returns, treating no data as an empty page.
+func firstPage[T any](data any) (*page[T], error) {
+	pages, err := decodePages[T](data)
+	if err != nil {
+		return nil, err
+	}
+	if len(pages) == 0 {
+		return &page[T]{}, nil
+	}
+	return &pages[0], nil
+}
+
+func stringArg(args map[string]interface{}, name string) string {
+	value, _ := args[name].(string)
+	return value
+}
+
+// optionalString returns nil for an input field that was left out, so
+// updates only touch the fields the caller sent.
+func optionalString(input map[string]interface{}, name string) *string {
+	value, ok := input[name].(string)
+	if !ok {
+		return nil
+	}
+	return &value
+}
+
+var errNoData = errors.New("unexpected service response")
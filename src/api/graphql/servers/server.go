@@ -0,0 +1,104 @@
+package servers
+
+import (
+	"context"
+
+	auditModels "somdeep-demo-app/src/audit/models"
+	customerInterfaces "somdeep-demo-app/src/customer/interfaces"
+	userInterfaces "somdeep-demo-app/src/user/interfaces"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// Options bounds how expensive a single query may be.
+type Options struct {
+	// MaxDepth is the deepest selection set a query may nest.
+	MaxDepth int
+	// MaxComplexity caps the estimated number of fields a query resolves,
+	// with list fields counted once per requested record.
+	MaxComplexity int
+}
+
+func DefaultOptions() Options {
+	return Options{
+		MaxDepth:      10,
+		MaxComplexity: 2000,
+	}
+}
+
+// Request is the standard GraphQL-over-HTTP request body.
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// Server executes GraphQL requests against the user and customer services.
+type Server struct {
+	schema          graphql.Schema
+	options         Options
+	userService     userInterfaces.UserService
+	customerService customerInterfaces.CustomerService
+}
+
+func NewServer(userService userInterfaces.UserService, customerService customerInterfaces.CustomerService, options Options) (*Server, error) {
+	s := &Server{
+		options:         options,
+		userService:     userService,
+		customerService: customerService,
+	}
+	schema, err := s.newSchema()
+	if err != nil {
+		return nil, err
+	}
+	s.schema = schema
+	return s, nil
+}
+
+// Execute parses, validates and runs a request. Depth and complexity are
+// checked after validation so the limits only ever see well-formed documents.
+func (s *Server) Execute(ctx context.Context, actor auditModels.Actor, request Request) *graphql.Result {
+	document, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(request.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+	validation := graphql.ValidateDocument(&s.schema, document, nil)
+	if !validation.IsValid {
+		return &graphql.Result{Errors: validation.Errors}
+	}
+	if err = checkLimits(document, request.OperationName, request.Variables, s.options); err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+
+	ctx = context.WithValue(ctx, requestKey{}, &requestState{
+		actor:     actor,
+		customers: newCustomerLoader(ctx, s.customerService),
+	})
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        s.schema,
+		AST:           document,
+		OperationName: request.OperationName,
+		Args:          request.Variables,
+		Context:       ctx,
+	})
+}
+
+type requestKey struct{}
+
+// requestState is what resolvers share for the lifetime of one request.
+type requestState struct {
+	actor     auditModels.Actor
+	customers *customerLoader
+}
+
+func stateFrom(ctx context.Context) *requestState {
+	if state, ok := ctx.Value(requestKey{}).(*requestState); ok {
+		return state
+	}
+	return &requestState{}
+}
@@ -0,0 +1,156 @@
+package servers
+
+import (
+	"context"
+
+	"somdeep-demo-app/src/api/grpc/pb"
+	"somdeep-demo-app/src/customer/interfaces"
+	"somdeep-demo-app/src/customer/models"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// CustomerServer serves pb.CustomerService with the same CustomerService the
+// HTTP controllers use.
+type CustomerServer struct {
+	pb.UnimplementedCustomerServiceServer
+	customerService interfaces.CustomerService
+}
+
+func NewCustomerServer(customerService interfaces.CustomerService) *CustomerServer {
+	return &CustomerServer{
+		customerService: customerService,
+	}
+}
+
+func (s *CustomerServer) ListCustomers(ctx context.Context, request *pb.ListCustomersRequest) (*pb.ListCustomersResponse, error) {
+	recordPerPage, page, startIndex := paging(request.Page, request.RecordPerPage)
+	customers, err := s.listCustomers(ctx, request.UserId, recordPerPage, page, startIndex)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ListCustomersResponse{Customers: customers}, nil
+}
+
+// StreamCustomers sends the customers of a user, or of every user, from a
+// single cursor, so the stream costs one query however many there are.
+func (s *CustomerServer) StreamCustomers(request *pb.StreamCustomersRequest, stream pb.CustomerService_StreamCustomersServer) error {
+	var sendErr error
+	response, _ := s.customerService.StreamCustomers(stream.Context(), request.UserId, func(customer models.Customer) error {
+		sendErr = stream.Send(customerMessage(customer))
+		return sendErr
+	})
+	return streamError(stream.Context(), response.Status, response.Message, response.Error, sendErr)
+}
+
+func (s *CustomerServer) GetCustomer(ctx context.Context, request *pb.GetCustomerRequest) (*pb.Customer, error) {
+	if err := required("user_id", request.UserId); err != nil {
+		return nil, err
+	}
+	if err := required("customer_id", request.CustomerId); err != nil {
+		return nil, err
+	}
+	return s.getCustomer(ctx, request.UserId, request.CustomerId)
+}
+
+func (s *CustomerServer) CreateCustomer(ctx context.Context, request *pb.CreateCustomerRequest) (*pb.Customer, error) {
+	if err := required("user_id", request.UserId); err != nil {
+		return nil, err
+	}
+	customer := models.Customer{
+		First_name: &request.FirstName,
+		Last_name:  &request.LastName,
+	}
+	response, err := s.customerService.AddCustomerByUserId(ctx, requestActor(ctx), request.UserId, customer)
+	if statusErr := responseError(ctx, response.Status, response.Message, response.Error); statusErr != nil {
+		return nil, statusErr
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	created, ok := response.Data.(models.CustomerRef)
+	if !ok {
+		return nil, status.Error(codes.Internal, "missing customer_id in service response")
+	}
+	return s.getCustomer(ctx, created.User_id, created.Customer_id)
+}
+
+func (s *CustomerServer) UpdateCustomer(ctx context.Context, request *pb.UpdateCustomerRequest) (*pb.Customer, error) {
+	if err := required("user_id", request.UserId); err != nil {
+		return nil, err
+	}
+	if err := required("customer_id", request.CustomerId); err != nil {
+		return nil, err
+	}
+	customer := models.Customer{
+		First_name: request.FirstName,
+		Last_name:  request.LastName,
+	}
+	response, _ := s.customerService.UpdateCustomerByCustomerId(ctx, requestActor(ctx), request.UserId, request.CustomerId, customer)
+	if err := responseError(ctx, response.Status, response.Message, response.Error); err != nil {
+		return nil, err
+	}
+	return s.getCustomer(ctx, request.UserId, request.CustomerId)
+}
+
+func (s *CustomerServer) DeleteCustomer(ctx context.Context, request *pb.DeleteCustomerRequest) (*emptypb.Empty, error) {
+	if err := required("user_id", request.UserId); err != nil {
+		return nil, err
+	}
+	if err := required("customer_id", request.CustomerId); err != nil {
+		return nil, err
+	}
+	response, _ := s.customerService.DeleteCustomerByCustomerId(ctx, requestActor(ctx), request.UserId, request.CustomerId)
+	if err := responseError(ctx, response.Status, response.Message, response.Error); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *CustomerServer) listCustomers(ctx context.Context, userId string, recordPerPage int, page int, startIndex int) ([]*pb.Customer, error) {
+	var response interfaces.Response
+	if userId == "" {
+		response, _ = s.customerService.GetAllCustomers(ctx, recordPerPage, page, startIndex)
+	} else {
+		response, _ = s.customerService.GetCustomersByUserId(ctx, userId, recordPerPage, page, startIndex)
+	}
+	if err := responseError(ctx, response.Status, response.Message, response.Error); err != nil {
+		return nil, err
+	}
+
+	customers, err := decodeList[models.Customer](response.Data)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	messages := make([]*pb.Customer, len(customers))
+	for i, customer := range customers {
+		messages[i] = customerMessage(customer)
+	}
+	return messages, nil
+}
+
+func (s *CustomerServer) getCustomer(ctx context.Context, userId string, customerId string) (*pb.Customer, error) {
+	response, _ := s.customerService.GetCustomerByCustomerId(ctx, userId, customerId)
+	if err := responseError(ctx, response.Status, response.Message, response.Error); err != nil {
+		return nil, err
+	}
+	customer, ok := response.Data.(models.Customer)
+	if !ok {
+		return nil, status.Error(codes.Internal, "unexpected customer service response")
+	}
+	return customerMessage(customer), nil
+}
+
+func customerMessage(customer models.Customer) *pb.Customer {
+	return &pb.Customer{
+		CustomerId: customer.Customer_id,
+		UserId:     customer.User_id,
+		FirstName:  deref(customer.First_name),
+		LastName:   deref(customer.Last_name),
+		CreatedAt:  timestamp(customer.Created_at),
+		UpdatedAt:  timestamp(customer.Updated_at),
+	}
+}
@@ -0,0 +1,68 @@
+package servers
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	healthInterfaces "somdeep-demo-app/src/health/interfaces"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// readinessInterval is how often the gRPC health status is brought in line
+// with the readiness checks.
+const readinessInterval = 5 * time.Second
+
+// newHealthServer returns a health server reporting the server as a whole and
+// each of services as NOT_SERVING until followReadiness says otherwise.
+func newHealthServer(services []string) *health.Server {
+	healthServer := health.NewServer()
+	setStatus(healthServer, services, healthpb.HealthCheckResponse_NOT_SERVING)
+	return healthServer
+}
+
+func setStatus(healthServer *health.Server, services []string, status healthpb.HealthCheckResponse_ServingStatus) {
+	healthServer.SetServingStatus("", status)
+	for _, service := range services {
+		healthServer.SetServingStatus(service, status)
+	}
+}
+
+// followReadiness sets the status of every service, and of the server as a
+// whole, to the result of the readiness checks. Once the service starts
+// draining every status becomes NOT_SERVING for good and followReadiness
+// returns.
+func followReadiness(healthServer *health.Server, healthService healthInterfaces.HealthService, services []string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	serving := false
+	for {
+		_, err := healthService.GetReadiness(context.Background())
+		select {
+		case <-healthService.Draining():
+			// Shutdown ignores later updates, so a check that was in flight
+			// cannot flip the status back
+			healthServer.Shutdown()
+			return
+		default:
+		}
+		if (err == nil) != serving {
+			serving = err == nil
+			if serving {
+				setStatus(healthServer, services, healthpb.HealthCheckResponse_SERVING)
+			} else {
+				slog.Warn("grpc: not serving, readiness checks failed", "error", err)
+				setStatus(healthServer, services, healthpb.HealthCheckResponse_NOT_SERVING)
+			}
+		}
+
+		select {
+		case <-healthService.Draining():
+			healthServer.Shutdown()
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package servers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	healthInterfaces "somdeep-demo-app/src/health/interfaces"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// stubHealthService is ready unless failing is set, and drains like the real
+// one.
+type stubHealthService struct {
+	healthInterfaces.HealthService
+
+	mu       sync.Mutex
+	failing  error
+	drained  chan struct{}
+	drainOne sync.Once
+}
+
+func (s *stubHealthService) GetReadiness(ctx context.Context) (healthInterfaces.Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return healthInterfaces.Response{}, s.failing
+}
+
+func (s *stubHealthService) fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failing = err
+}
+
+func (s *stubHealthService) StartDraining() {
+	s.drainOne.Do(func() { close(s.drained) })
+}
+
+func (s *stubHealthService) Draining() <-chan struct{} {
+	return s.drained
+}
+
+// waitForStatus polls service on healthServer until it reports want.
+func waitForStatus(t *testing.T, healthServer *health.Server, service string, want healthpb.HealthCheckResponse_ServingStatus) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	var got healthpb.HealthCheckResponse_ServingStatus
+	for time.Now().Before(deadline) {
+		response, err := healthServer.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			t.Fatalf("Check(%q): %v", service, err)
+		}
+		if got = response.Status; got == want {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("status of %q = %v, want %v", service, got, want)
+}
+
+func TestHealthStatusFollowsReadiness(t *testing.T) {
+	healthService := &stubHealthService{drained: make(chan struct{})}
+	healthServer := newHealthServer([]string{"api.UserService"})
+	waitForStatus(t, healthServer, "", healthpb.HealthCheckResponse_NOT_SERVING)
+	waitForStatus(t, healthServer, "api.UserService", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	done := make(chan struct{})
+	go func() {
+		followReadiness(healthServer, healthService, []string{"api.UserService"}, 10*time.Millisecond)
+		close(done)
+	}()
+
+	waitForStatus(t, healthServer, "", healthpb.HealthCheckResponse_SERVING)
+	waitForStatus(t, healthServer, "api.UserService", healthpb.HealthCheckResponse_SERVING)
+
+	healthService.fail(errors.New("failing checks: mongo"))
+	waitForStatus(t, healthServer, "", healthpb.HealthCheckResponse_NOT_SERVING)
+	waitForStatus(t, healthServer, "api.UserService", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	healthService.fail(nil)
+	waitForStatus(t, healthServer, "api.UserService", healthpb.HealthCheckResponse_SERVING)
+
+	healthService.StartDraining()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("followReadiness did not return after draining started")
+	}
+	for _, service := range []string{"", "api.UserService"} {
+		waitForStatus(t, healthServer, service, healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+	// later updates are ignored once draining
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	waitForStatus(t, healthServer, "", healthpb.HealthCheckResponse_NOT_SERVING)
+}
@@ -0,0 +1,112 @@
+package servers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+
+	"somdeep-demo-app/src/api/grpc/pb"
+	customerInterfaces "somdeep-demo-app/src/customer/interfaces"
+	healthInterfaces "somdeep-demo-app/src/health/interfaces"
+	"somdeep-demo-app/src/logging"
+	"somdeep-demo-app/src/tenant"
+	userInterfaces "somdeep-demo-app/src/user/interfaces"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// NewServer returns a gRPC server exposing the user and customer services
+// alongside the standard health and reflection services. The health status
+// follows the readiness checks of healthService and turns NOT_SERVING when it
+// starts draining. With tenants set, every user and customer call is scoped
+// to the tenant it names.
+func NewServer(userService userInterfaces.UserService, customerService customerInterfaces.CustomerService, healthService healthInterfaces.HealthService, tenants *tenant.Resolver) *grpc.Server {
+	unary := []grpc.UnaryServerInterceptor{requestIdUnary, recoverUnary}
+	stream := []grpc.StreamServerInterceptor{requestIdStream, recoverStream}
+	if tenants != nil {
+		tenantUnary, tenantStream := tenantInterceptors(tenants)
+		unary = append(unary, tenantUnary)
+		stream = append(stream, tenantStream)
+	}
+	server := grpc.NewServer(
+		// continues the caller's W3C trace context and traces every call
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	)
+	pb.RegisterUserServiceServer(server, NewUserServer(userService))
+	pb.RegisterCustomerServiceServer(server, NewCustomerServer(customerService))
+
+	var services []string
+	for name := range server.GetServiceInfo() {
+		services = append(services, name)
+	}
+	healthServer := newHealthServer(services)
+	go followReadiness(healthServer, healthService, services, readinessInterval)
+	healthpb.RegisterHealthServer(server, healthServer)
+	reflection.Register(server)
+	return server
+}
+
+// maxRequestIdLength bounds a caller supplied request ID, as the HTTP
+// middleware does.
+const maxRequestIdLength = 128
+
+// requestIdUnary accepts the caller's x-request-id or generates one, sends it
+// back in the response header and carries it in the context.
+func requestIdUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	return handler(withRequestId(ctx), req)
+}
+
+func requestIdStream(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, &contextStream{ServerStream: stream, ctx: withRequestId(stream.Context())})
+}
+
+func withRequestId(ctx context.Context) context.Context {
+	requestId := incoming(ctx, requestIdKey)
+	if requestId == "" || len(requestId) > maxRequestIdLength {
+		requestId = uuid.New().String()
+	}
+	grpc.SetHeader(ctx, metadata.Pairs(requestIdKey, requestId))
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("app.request_id", requestId))
+	return logging.WithRequestId(ctx, requestId)
+}
+
+// contextStream replaces the context of a server stream.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+// recoverUnary keeps a panicking handler from taking the whole process down,
+// which grpc-go does not guard against on its own.
+func recoverUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer recoverHandler(ctx, info.FullMethod, &err)
+	return handler(ctx, req)
+}
+
+func recoverStream(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer recoverHandler(stream.Context(), info.FullMethod, &err)
+	return handler(srv, stream)
+}
+
+func recoverHandler(ctx context.Context, method string, err *error) {
+	if recovered := recover(); recovered != nil {
+		slog.ErrorContext(ctx, "grpc: handler panicked", "method", method, "panic", fmt.Sprint(recovered), "stack", string(debug.Stack()))
+		*err = status.Error(codes.Internal, fmt.Sprint("internal error in ", method))
+	}
+}
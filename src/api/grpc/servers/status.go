@@ -0,0 +1,146 @@
+package servers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	auditModels "somdeep-demo-app/src/audit/models"
+	"somdeep-demo-app/src/logging"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Metadata keys that identify the caller, the gRPC spelling of the X-Actor and
+// X-Request-ID HTTP headers.
+const (
+	actorKey     = "x-actor"
+	requestIdKey = "x-request-id"
+)
+
+const (
+	defaultRecordPerPage = 10
+)
+
+// httpToCode maps the HTTP status in a service response to a gRPC code.
+var httpToCode = map[int]codes.Code{
+	http.StatusBadRequest:          codes.InvalidArgument,
+	http.StatusUnauthorized:        codes.Unauthenticated,
+	http.StatusForbidden:           codes.PermissionDenied,
+	http.StatusNotFound:            codes.NotFound,
+	http.StatusConflict:            codes.AlreadyExists,
+	http.StatusPreconditionFailed:  codes.FailedPrecondition,
+	http.StatusUnprocessableEntity: codes.InvalidArgument,
+	http.StatusTooManyRequests:     codes.ResourceExhausted,
+	http.StatusNotImplemented:      codes.Unimplemented,
+	http.StatusServiceUnavailable:  codes.Unavailable,
+	http.StatusGatewayTimeout:      codes.DeadlineExceeded,
+}
+
+// responseError turns a failed service response into a gRPC status error and
+// returns nil for a successful one. The detail only reaches the caller for
+// client errors: for server errors, which may carry driver internals, it is
+// logged instead.
+func responseError(ctx context.Context, httpStatus int, message string, detail string) error {
+	if httpStatus < http.StatusBadRequest {
+		return nil
+	}
+	code, ok := httpToCode[httpStatus]
+	if !ok {
+		code = codes.Internal
+	}
+	if detail != "" && detail != "NA" {
+		if httpStatus >= http.StatusInternalServerError {
+			slog.ErrorContext(ctx, "grpc: service failed", "status", httpStatus, "message", message, "error", detail)
+		} else {
+			message += ": " + detail
+		}
+	}
+	return status.Error(code, message)
+}
+
+// requestActor identifies the caller for the audit trail, with the request ID
+// the requestId interceptors accepted or generated.
+func requestActor(ctx context.Context) auditModels.Actor {
+	return auditModels.Actor{ID: incoming(ctx, actorKey), Request_id: logging.RequestId(ctx)}
+}
+
+// incoming returns the first value of a metadata key the caller sent.
+func incoming(ctx context.Context, key string) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func paging(page int32, recordPerPage int32) (int, int, int) {
+	if recordPerPage < 1 {
+		recordPerPage = defaultRecordPerPage
+	}
+	if page < 1 {
+		page = 1
+	}
+	return int(recordPerPage), int(page), int(page-1) * int(recordPerPage)
+}
+
+// streamError is the error a server stream ends with: the error of the send
+// that stopped it, the client going away, or the status of the response of
+// the service that fed it.
+func streamError(ctx context.Context, httpStatus int, message string, detail string, sendErr error) error {
+	if sendErr != nil {
+		return sendErr
+	}
+	if err := ctx.Err(); err != nil {
+		return status.FromContextError(err).Err()
+	}
+	return responseError(ctx, httpStatus, message, detail)
+}
+
+// decodeList converts the paginated aggregate a list service returns, a
+// []bson.M holding {total_count, items}, into typed models.
+func decodeList[T any](data any) ([]T, error) {
+	raw, err := bson.Marshal(bson.M{"pages": data})
+	if err != nil {
+		return nil, err
+	}
+	var list struct {
+		Pages []struct {
+			Items []T `bson:"items"`
+		} `bson:"pages"`
+	}
+	if err = bson.Unmarshal(raw, &list); err != nil {
+		return nil, err
+	}
+	items := []T{}
+	for _, page := range list.Pages {
+		items = append(items, page.Items...)
+	}
+	return items, nil
+}
+
+func required(name string, value string) error {
+	if value == "" {
+		return status.Error(codes.InvalidArgument, name+" is required")
+	}
+	return nil
+}
+
+func deref(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
@@ -0,0 +1,53 @@
+package servers
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestResponseError(t *testing.T) {
+	const driverErr = "connection(mongo-0:27017[-3]) incomplete read of message header: EOF"
+
+	tests := []struct {
+		name    string
+		status  int
+		detail  string
+		code    codes.Code
+		message string
+		logged  bool
+	}{
+		{name: "not found", status: http.StatusNotFound, detail: "NA", code: codes.NotFound, message: "User not found"},
+		{name: "client error detail", status: http.StatusBadRequest, detail: "first_name is required", code: codes.InvalidArgument, message: "User not found: first_name is required"},
+		{name: "server error", status: http.StatusInternalServerError, detail: driverErr, code: codes.Internal, message: "User not found", logged: true},
+		{name: "unavailable", status: http.StatusServiceUnavailable, detail: driverErr, code: codes.Unavailable, message: "User not found", logged: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			defer slog.SetDefault(slog.Default())
+			slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+
+			err := responseError(context.Background(), test.status, "User not found", test.detail)
+			if status.Code(err) != test.code {
+				t.Errorf("code = %v, want %v", status.Code(err), test.code)
+			}
+			if message := status.Convert(err).Message(); message != test.message {
+				t.Errorf("message = %q, want %q", message, test.message)
+			}
+			if logged := strings.Contains(logs.String(), driverErr); logged != test.logged {
+				t.Errorf("driver error logged = %v, want %v", logged, test.logged)
+			}
+		})
+	}
+
+	if err := responseError(context.Background(), http.StatusOK, "User Added Successfully", "NA"); err != nil {
+		t.Errorf("a successful response gave %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package servers
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"somdeep-demo-app/src/apperrors"
+	"somdeep-demo-app/src/tenant"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// untenantedServices serve the whole deployment rather than one tenant.
+var untenantedServices = []string{"/grpc.health.v1.Health/", "/grpc.reflection."}
+
+// tenantInterceptors resolve the tenant of each call from its metadata, the
+// gRPC spelling of the HTTP headers, and the :authority it was sent to, and
+// reject calls whose tenant cannot be resolved.
+func tenantInterceptors(resolver *tenant.Resolver) (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+	unary := func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := withTenant(ctx, resolver, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+	stream := func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := withTenant(stream.Context(), resolver, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+	}
+	return unary, stream
+}
+
+func withTenant(ctx context.Context, resolver *tenant.Resolver, method string) (context.Context, error) {
+	for _, prefix := range untenantedServices {
+		if strings.HasPrefix(method, prefix) {
+			return ctx, nil
+		}
+	}
+	header := func(name string) string { return incoming(ctx, name) }
+	tenantId, err := resolver.Resolve(header, incoming(ctx, ":authority"))
+	if err != nil {
+		code := codes.InvalidArgument
+		var typed apperrors.Error
+		if errors.As(err, &typed) {
+			if mapped, ok := httpToCode[typed.Status()]; ok {
+				code = mapped
+			}
+		}
+		return ctx, status.Error(code, err.Error())
+	}
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("app.tenant_id", tenantId))
+	return tenant.WithTenant(ctx, tenantId), nil
+}
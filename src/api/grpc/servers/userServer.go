@@ -0,0 +1,143 @@
+package servers
+
+import (
+	"context"
+
+	"somdeep-demo-app/src/api/grpc/pb"
+	"somdeep-demo-app/src/user/interfaces"
+	"somdeep-demo-app/src/user/models"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// UserServer serves pb.UserService with the same UserService the HTTP
+// controllers use.
+type UserServer struct {
+	pb.UnimplementedUserServiceServer
+	userService interfaces.UserService
+}
+
+func NewUserServer(userService interfaces.UserService) *UserServer {
+	return &UserServer{
+		userService: userService,
+	}
+}
+
+func (s *UserServer) ListUsers(ctx context.Context, request *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	recordPerPage, page, startIndex := paging(request.Page, request.RecordPerPage)
+	users, err := s.listUsers(ctx, recordPerPage, page, startIndex)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ListUsersResponse{Users: users}, nil
+}
+
+// StreamUsers sends every user from a single cursor, so the stream costs one
+// query however many users there are.
+func (s *UserServer) StreamUsers(request *pb.StreamUsersRequest, stream pb.UserService_StreamUsersServer) error {
+	var sendErr error
+	response, _ := s.userService.StreamUsers(stream.Context(), func(user models.User) error {
+		sendErr = stream.Send(userMessage(user))
+		return sendErr
+	})
+	return streamError(stream.Context(), response.Status, response.Message, response.Error, sendErr)
+}
+
+func (s *UserServer) GetUser(ctx context.Context, request *pb.GetUserRequest) (*pb.User, error) {
+	if err := required("user_id", request.UserId); err != nil {
+		return nil, err
+	}
+	return s.getUser(ctx, request.UserId)
+}
+
+func (s *UserServer) CreateUser(ctx context.Context, request *pb.CreateUserRequest) (*pb.User, error) {
+	user := models.User{
+		First_name: &request.FirstName,
+		Last_name:  &request.LastName,
+		Password:   &request.Password,
+		Email:      &request.Email,
+		Phone:      &request.Phone,
+	}
+	response, err := s.userService.AddUser(ctx, requestActor(ctx), user)
+	if statusErr := responseError(ctx, response.Status, response.Message, response.Error); statusErr != nil {
+		return nil, statusErr
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	created, ok := response.Data.(models.UserRef)
+	if !ok {
+		return nil, status.Error(codes.Internal, "missing user_id in service response")
+	}
+	return s.getUser(ctx, created.User_id)
+}
+
+func (s *UserServer) UpdateUser(ctx context.Context, request *pb.UpdateUserRequest) (*pb.User, error) {
+	if err := required("user_id", request.UserId); err != nil {
+		return nil, err
+	}
+	user := models.User{
+		First_name: request.FirstName,
+		Last_name:  request.LastName,
+	}
+	response, _ := s.userService.UpdateUser(ctx, requestActor(ctx), request.UserId, user)
+	if err := responseError(ctx, response.Status, response.Message, response.Error); err != nil {
+		return nil, err
+	}
+	return s.getUser(ctx, request.UserId)
+}
+
+func (s *UserServer) DeleteUser(ctx context.Context, request *pb.DeleteUserRequest) (*emptypb.Empty, error) {
+	if err := required("user_id", request.UserId); err != nil {
+		return nil, err
+	}
+	response, _ := s.userService.DeleteUser(ctx, requestActor(ctx), request.UserId)
+	if err := responseError(ctx, response.Status, response.Message, response.Error); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *UserServer) listUsers(ctx context.Context, recordPerPage int, page int, startIndex int) ([]*pb.User, error) {
+	response, _ := s.userService.GetUsers(ctx, recordPerPage, page, startIndex)
+	if err := responseError(ctx, response.Status, response.Message, response.Error); err != nil {
+		return nil, err
+	}
+
+	users, err := decodeList[models.User](response.Data)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	messages := make([]*pb.User, len(users))
+	for i, user := range users {
+		messages[i] = userMessage(user)
+	}
+	return messages, nil
+}
+
+func (s *UserServer) getUser(ctx context.Context, userId string) (*pb.User, error) {
+	response, _ := s.userService.GetUser(ctx, userId)
+	if err := responseError(ctx, response.Status, response.Message, response.Error); err != nil {
+		return nil, err
+	}
+	user, ok := response.Data.(models.User)
+	if !ok {
+		return nil, status.Error(codes.Internal, "unexpected user service response")
+	}
+	return userMessage(user), nil
+}
+
+func userMessage(user models.User) *pb.User {
+	return &pb.User{
+		UserId:    user.User_id,
+		FirstName: deref(user.First_name),
+		LastName:  deref(user.Last_name),
+		Email:     deref(user.Email),
+		Phone:     deref(user.Phone),
+		CreatedAt: timestamp(user.Created_at),
+		UpdatedAt: timestamp(user.Updated_at),
+	}
+}
@@ -0,0 +1,129 @@
+package servers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"somdeep-demo-app/src/api/grpc/pb"
+	auditModels "somdeep-demo-app/src/audit/models"
+	"somdeep-demo-app/src/user/interfaces"
+	"somdeep-demo-app/src/user/models"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// createdUserService creates users under the ID it is given and returns data
+// from AddUser as is.
+type createdUserService struct {
+	interfaces.UserService
+	userId string
+	data   any
+}
+
+func (s *createdUserService) AddUser(ctx context.Context, actor auditModels.Actor, user models.User) (interfaces.Response, error) {
+	return interfaces.Response{Status: http.StatusOK, Error: "NA", Message: "User Added Successfully", Data: s.data}, nil
+}
+
+func (s *createdUserService) GetUser(ctx context.Context, userId string) (interfaces.Response, error) {
+	if userId != s.userId {
+		return interfaces.Response{Status: http.StatusNotFound, Error: "NA", Message: "User not found"}, nil
+	}
+	firstName := "Ada"
+	return interfaces.Response{Status: http.StatusOK, Error: "NA", Data: models.User{User_id: userId, First_name: &firstName}}, nil
+}
+
+func TestCreateUserReadsTheCreatedId(t *testing.T) {
+	server := NewUserServer(&createdUserService{userId: "u-1", data: models.UserRef{User_id: "u-1"}})
+	user, err := server.CreateUser(context.Background(), &pb.CreateUserRequest{FirstName: "Ada"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.UserId != "u-1" || user.FirstName != "Ada" {
+		t.Errorf("user = %v, want u-1 as created", user)
+	}
+
+	// a response without a ref is a server error, not a lookup of ""
+	server = NewUserServer(&createdUserService{userId: "u-1", data: "user_id: u-1"})
+	if _, err = server.CreateUser(context.Background(), &pb.CreateUserRequest{FirstName: "Ada"}); status.Code(err) != codes.Internal {
+		t.Errorf("error = %v, want Internal", err)
+	}
+}
+
+// streamedUserService streams users once and fails with status after them.
+type streamedUserService struct {
+	interfaces.UserService
+	users  []models.User
+	status int
+	calls  int
+}
+
+func (s *streamedUserService) StreamUsers(ctx context.Context, send func(user models.User) error) (interfaces.Response, error) {
+	s.calls++
+	for _, user := range s.users {
+		if err := send(user); err != nil {
+			return interfaces.Response{Status: http.StatusInternalServerError, Error: err.Error(), Message: "Stream was interrupted"}, err
+		}
+	}
+	if s.status != http.StatusOK {
+		return interfaces.Response{Status: s.status, Error: "(InterruptedAtShutdown) cursor killed", Message: "Stream was interrupted"}, errors.New("cursor killed")
+	}
+	return interfaces.Response{Status: http.StatusOK, Error: "NA", Data: len(s.users)}, nil
+}
+
+// userStream collects what a server stream sends, failing after failAfter
+// messages when it is set.
+type userStream struct {
+	grpc.ServerStream
+	ctx       context.Context
+	sent      []*pb.User
+	failAfter int
+}
+
+func (s *userStream) Context() context.Context { return s.ctx }
+
+func (s *userStream) Send(user *pb.User) error {
+	if s.failAfter > 0 && len(s.sent) == s.failAfter {
+		return io.EOF
+	}
+	s.sent = append(s.sent, user)
+	return nil
+}
+
+func TestStreamUsers(t *testing.T) {
+	users := []models.User{{User_id: "u-1"}, {User_id: "u-2"}, {User_id: "u-3"}}
+	tests := []struct {
+		name      string
+		status    int
+		failAfter int
+		sent      int
+		code      codes.Code
+		err       error
+	}{
+		{name: "every user", status: http.StatusOK, sent: 3, code: codes.OK},
+		{name: "send fails", status: http.StatusOK, failAfter: 1, sent: 1, err: io.EOF},
+		{name: "cursor fails", status: http.StatusInternalServerError, sent: 3, code: codes.Internal},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := &streamedUserService{users: users, status: test.status}
+			stream := &userStream{ctx: context.Background(), failAfter: test.failAfter}
+			err := NewUserServer(service).StreamUsers(&pb.StreamUsersRequest{PageSize: 1}, stream)
+			if test.err != nil {
+				if err != test.err {
+					t.Errorf("error = %v, want the send error", err)
+				}
+			} else if status.Code(err) != test.code || strings.Contains(status.Convert(err).Message(), "cursor killed") {
+				t.Errorf("error = %v, want %v without the driver's details", err, test.code)
+			}
+			if len(stream.sent) != test.sent || service.calls != 1 {
+				t.Errorf("sent %d users in %d calls, want %d in one", len(stream.sent), service.calls, test.sent)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package controllers
+
+import (
+	"somdeep-demo-app/src/api/http/headers"
+	auditModels "somdeep-demo-app/src/audit/models"
+	"somdeep-demo-app/src/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requestActor identifies the caller for the audit trail. There is no
+// authentication yet, so the gateway is trusted to set the actor header. The
+// request ID is the one the RequestId middleware accepted or generated.
+func requestActor(c *gin.Context) auditModels.Actor {
+	return auditModels.Actor{
+		ID:         c.GetHeader(headers.Actor),
+		Request_id: logging.RequestId(c.Request.Context()),
+	}
+}
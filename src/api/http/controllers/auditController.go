@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"somdeep-demo-app/src/api/http/problems"
+	"somdeep-demo-app/src/audit/interfaces"
+	"somdeep-demo-app/src/audit/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditController struct {
+	auditService interfaces.AuditService
+}
+
+func NewAuditController(auditService interfaces.AuditService) *AuditController {
+	return &AuditController{
+		auditService: auditService,
+	}
+}
+
+func (s *AuditController) GetAuditEntriesHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter := models.AuditFilter{
+			Entity_type: c.Query("entity"),
+			Entity_id:   c.Query("id"),
+			Actor:       c.Query("actor"),
+		}
+		s.listAuditEntries(c, filter)
+	}
+}
+
+func (s *AuditController) GetCustomerHistoryHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter := models.AuditFilter{
+			Entity_type: models.EntityCustomer,
+			Entity_id:   c.Param("customer_id"),
+			Parent_id:   c.Param("user_id"),
+		}
+		s.listAuditEntries(c, filter)
+	}
+}
+
+func (s *AuditController) listAuditEntries(c *gin.Context, filter models.AuditFilter) {
+	recordPerPage, page, startIndex := paging(c)
+	response, err := s.auditService.GetAuditEntries(c.Request.Context(), filter, recordPerPage, page, startIndex)
+
+	if err != nil {
+		problems.Write(c, problems.New(response.Status, response.Message, err).WithData(response.Data))
+		return
+	}
+
+	c.JSON(response.Status, response)
+}
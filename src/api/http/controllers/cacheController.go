@@ -0,0 +1,44 @@
+package controllers
+
+import (
+	"somdeep-demo-app/src/api/http/problems"
+	"somdeep-demo-app/src/cache/interfaces"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CacheController struct {
+	cacheService interfaces.CacheService
+}
+
+func NewCacheController(cacheService interfaces.CacheService) *CacheController {
+	return &CacheController{
+		cacheService: cacheService,
+	}
+}
+
+func (s *CacheController) GetCacheStatsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		response, err := s.cacheService.GetCacheStats()
+
+		if err != nil {
+			problems.Write(c, problems.New(response.Status, response.Message, err).WithData(response.Data))
+			return
+		}
+
+		c.JSON(response.Status, response)
+	}
+}
+
+func (s *CacheController) ClearCacheHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		response, err := s.cacheService.ClearCache()
+
+		if err != nil {
+			problems.Write(c, problems.New(response.Status, response.Message, err).WithData(response.Data))
+			return
+		}
+
+		c.JSON(response.Status, response)
+	}
+}
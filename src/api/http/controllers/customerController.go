@@ -1,10 +1,15 @@
 package controllers
 
 import (
+	"io"
 	"net/http"
+	"path/filepath"
+	"somdeep-demo-app/src/api/http/problems"
 	"somdeep-demo-app/src/customer/interfaces"
 	"somdeep-demo-app/src/customer/models"
+	"somdeep-demo-app/src/export"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -21,20 +26,11 @@ func NewCustomerController(customerService interfaces.CustomerService) *Customer
 
 func (s *CustomerController) GetCustomersHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		recordPerPage, err := strconv.Atoi(c.Query("recordPerPage"))
-		if err != nil || recordPerPage < 1 {
-			recordPerPage = 10
-		}
-		page, err := strconv.Atoi(c.Query("page"))
-		if err != nil || page < 1 {
-			page = 1
-		}
-
-		startIndex := (page - 1) * recordPerPage
-		response, err := s.customerService.GetAllCustomers(recordPerPage, page, startIndex)
+		recordPerPage, page, startIndex := paging(c)
+		response, err := s.customerService.GetAllCustomers(c.Request.Context(), recordPerPage, page, startIndex)
 
 		if err != nil {
-			c.JSON(response.Status, response)
+			problems.Write(c, problems.New(response.Status, response.Message, err).WithData(response.Data))
 			return
 		}
 
@@ -47,20 +43,11 @@ func (s *CustomerController) GetCustomersByUserIdHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
 
 		userId := c.Param("user_id")
-		recordPerPage, err := strconv.Atoi(c.Query("recordPerPage"))
-		if err != nil || recordPerPage < 1 {
-			recordPerPage = 10
-		}
-		page, err := strconv.Atoi(c.Query("page"))
-		if err != nil || page < 1 {
-			page = 1
-		}
-
-		startIndex := (page - 1) * recordPerPage
-		response, err := s.customerService.GetCustomersByUserId(userId, recordPerPage, page, startIndex)
+		recordPerPage, page, startIndex := paging(c)
+		response, err := s.customerService.GetCustomersByUserId(c.Request.Context(), userId, recordPerPage, page, startIndex)
 
 		if err != nil {
-			c.JSON(response.Status, response)
+			problems.Write(c, problems.New(response.Status, response.Message, err).WithData(response.Data))
 			return
 		}
 
@@ -74,10 +61,10 @@ func (s *CustomerController) GetCustomerByCustomerIdHandler() gin.HandlerFunc {
 		customerId := c.Param("customer_id")
 		userId := c.Param("user_id")
 
-		response, err := s.customerService.GetCustomerByCustomerId(userId, customerId)
+		response, err := s.customerService.GetCustomerByCustomerId(c.Request.Context(), userId, customerId)
 
 		if err != nil {
-			c.JSON(response.Status, response)
+			problems.Write(c, problems.New(response.Status, response.Message, err).WithData(response.Data))
 			return
 		}
 
@@ -86,6 +73,21 @@ func (s *CustomerController) GetCustomerByCustomerIdHandler() gin.HandlerFunc {
 	}
 }
 
+func (s *CustomerController) StreamCustomerChangesHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId := c.Param("user_id")
+
+		changes, response, err := s.customerService.StreamCustomerChanges(c.Request.Context(), userId, lastEventId(c))
+
+		if err != nil {
+			problems.Write(c, problems.New(response.Status, response.Message, err).WithData(response.Data))
+			return
+		}
+
+		streamEvents(c, changes)
+	}
+}
+
 func (s *CustomerController) AddCustomerByUserIdHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
 
@@ -95,14 +97,14 @@ func (s *CustomerController) AddCustomerByUserIdHandler() gin.HandlerFunc {
 		// convert the JSON data coming from FE to something that golang understands
 
 		if err := c.BindJSON(&customer); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "message": "Error occured while binding JSON"})
+			problems.Write(c, problems.New(http.StatusBadRequest, "Error occured while binding JSON", err))
 			return
 		}
 
-		response, err := s.customerService.AddCustomerByUserId(userId, customer)
+		response, err := s.customerService.AddCustomerByUserId(c.Request.Context(), requestActor(c), userId, customer)
 
 		if err != nil {
-			c.JSON(response.Status, response)
+			problems.Write(c, problems.New(response.Status, response.Message, err).WithData(response.Data))
 			return
 		}
 
@@ -119,14 +121,14 @@ func (s *CustomerController) UpdateCustomerByCustomerIdHandler() gin.HandlerFunc
 		customerId := c.Param("customer_id")
 
 		if err := c.BindJSON(&customer); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "message": "Error occured while binding JSON"})
+			problems.Write(c, problems.New(http.StatusBadRequest, "Error occured while binding JSON", err))
 			return
 		}
 
-		response, err := s.customerService.UpdateCustomerByCustomerId(userId, customerId, customer)
+		response, err := s.customerService.UpdateCustomerByCustomerId(c.Request.Context(), requestActor(c), userId, customerId, customer)
 
 		if err != nil {
-			c.JSON(response.Status, response)
+			problems.Write(c, problems.New(response.Status, response.Message, err).WithData(response.Data))
 			return
 		}
 
@@ -137,12 +139,13 @@ func (s *CustomerController) UpdateCustomerByCustomerIdHandler() gin.HandlerFunc
 
 func (s *CustomerController) DeleteCustomerByCustomerIdHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		userId := c.Param("user_id")
 		customerId := c.Param("customer_id")
 
-		response, err := s.customerService.DeleteCustomerByCustomerId(customerId)
+		response, err := s.customerService.DeleteCustomerByCustomerId(c.Request.Context(), requestActor(c), userId, customerId)
 
 		if err != nil {
-			c.JSON(response.Status, response)
+			problems.Write(c, problems.New(response.Status, response.Message, err).WithData(response.Data))
 			return
 		}
 
@@ -155,10 +158,10 @@ func (s *CustomerController) DeleteCustomersByUserId() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userId := c.Param("user_id")
 
-		response, err := s.customerService.DeleteCustomersByUserId(userId)
+		response, err := s.customerService.DeleteCustomersByUserId(c.Request.Context(), requestActor(c), userId)
 
 		if err != nil {
-			c.JSON(response.Status, response)
+			problems.Write(c, problems.New(response.Status, response.Message, err).WithData(response.Data))
 			return
 		}
 
@@ -166,3 +169,127 @@ func (s *CustomerController) DeleteCustomersByUserId() gin.HandlerFunc {
 		c.JSON(response.Status, response)
 	}
 }
+
+func (s *CustomerController) BatchCustomersByUserIdHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId := c.Param("user_id")
+		var batch models.CustomerBatchRequest
+
+		if err := c.BindJSON(&batch); err != nil {
+			problems.Write(c, problems.New(http.StatusBadRequest, "Error occured while binding JSON", err))
+			return
+		}
+
+		response, err := s.customerService.BatchCustomersByUserId(c.Request.Context(), requestActor(c), userId, batch)
+
+		if err != nil {
+			problems.Write(c, problems.New(response.Status, response.Message, err).WithData(response.Data))
+			return
+		}
+
+		c.JSON(response.Status, response)
+	}
+}
+
+func (s *CustomerController) ExportCustomersHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		format := c.DefaultQuery("format", export.FormatCSV)
+		contentType, err := export.ContentType(format)
+		if err != nil {
+			problems.Write(c, problems.New(http.StatusBadRequest, "Validation Error", err))
+			return
+		}
+
+		var filter models.CustomerExportFilter
+		filter.User_id = c.Query("user_id")
+		filter.StartIndex, filter.RecordPerPage = exportPaging(c)
+		filter.Fields = exportFields(c)
+
+		writer := newExportWriter(c, contentType, "customers", format)
+		response, err := s.customerService.ExportCustomers(c.Request.Context(), filter, format, writer)
+
+		if err != nil && !c.Writer.Written() {
+			problems.Write(c, problems.New(response.Status, response.Message, err).WithData(response.Data))
+			return
+		}
+		if err != nil {
+			// the download has already started, all we can do is cut it short
+			c.Abort()
+		}
+	}
+}
+
+// ImportCustomersByUserIdHandler accepts either a multipart upload in the
+// "file" field or the raw CSV/NDJSON document as the request body.
+func (s *CustomerController) ImportCustomersByUserIdHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		withoutDeadlines(c)
+		userId := c.Param("user_id")
+		dryRun, _ := strconv.ParseBool(c.Query("dry_run"))
+
+		var body io.Reader = c.Request.Body
+		name := ""
+		if strings.HasPrefix(c.ContentType(), "multipart/") {
+			fileHeader, err := c.FormFile("file")
+			if err != nil {
+				problems.Write(c, problems.New(http.StatusBadRequest, "Error occured while reading the uploaded file", err))
+				return
+			}
+			file, err := fileHeader.Open()
+			if err != nil {
+				problems.Write(c, problems.New(http.StatusBadRequest, "Error occured while reading the uploaded file", err))
+				return
+			}
+			defer file.Close()
+			body = file
+			name = fileHeader.Filename
+		}
+
+		format := ImportFormat(c.Query("format"), name, c.ContentType())
+		response, err := s.customerService.ImportCustomersByUserId(c.Request.Context(), requestActor(c), userId, body, format, dryRun)
+
+		if err != nil {
+			problems.Write(c, problems.New(response.Status, response.Message, err).WithData(response.Data))
+			return
+		}
+
+		c.JSON(response.Status, response)
+	}
+}
+
+// ImportFormat picks the import format from an explicit value, falling back to
+// the file extension and then the content type. CSV is the default.
+func ImportFormat(format string, filename string, contentType string) string {
+	if format != "" {
+		return strings.ToLower(format)
+	}
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".ndjson", ".jsonl":
+		return models.ImportFormatNDJSON
+	case ".csv":
+		return models.ImportFormatCSV
+	}
+	switch contentType {
+	case "application/x-ndjson", "application/ndjson", "application/jsonl":
+		return models.ImportFormatNDJSON
+	}
+	return models.ImportFormatCSV
+}
+
+// UserActionHandler dispatches custom-method style routes such as
+// POST /users/:user_id/customers:batch, which gin cannot register as a static
+// path because of the colon.
+func (s *CustomerController) UserActionHandler() gin.HandlerFunc {
+	batchHandler := s.BatchCustomersByUserIdHandler()
+	importHandler := s.ImportCustomersByUserIdHandler()
+	return func(c *gin.Context) {
+		switch c.Param("action") {
+		case "customers:batch":
+			batchHandler(c)
+		case "customers:import":
+			importHandler(c)
+		default:
+			problems.RouteNotFound(c)
+		}
+	}
+}
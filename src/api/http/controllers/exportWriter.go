@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"somdeep-demo-app/src/api/http/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// exportWriter defers the download headers until the service writes its first
+// byte, so a failure before streaming starts can still be answered with JSON.
+type exportWriter struct {
+	c           *gin.Context
+	contentType string
+	filename    string
+}
+
+func newExportWriter(c *gin.Context, contentType string, name string, format string) *exportWriter {
+	withoutDeadlines(c)
+	return &exportWriter{
+		c:           c,
+		contentType: contentType,
+		filename:    name + "-" + time.Now().UTC().Format("20060102T150405Z") + "." + format,
+	}
+}
+
+func (w *exportWriter) Write(p []byte) (int, error) {
+	if !w.c.Writer.Written() {
+		header := w.c.Writer.Header()
+		header.Set("Content-Type", w.contentType)
+		header.Set("Content-Disposition", `attachment; filename="`+w.filename+`"`)
+		header.Set("Cache-Control", "no-store")
+		w.c.Status(http.StatusOK)
+	}
+	return w.c.Writer.Write(p)
+}
+
+func (w *exportWriter) Flush() {
+	w.c.Writer.Flush()
+}
+
+// exportPaging returns a zero recordPerPage, meaning "everything", unless the
+// caller asks for a specific page.
+func exportPaging(c *gin.Context) (startIndex int, recordPerPage int) {
+	recordPerPage = openapi.QueryInt(c, "recordPerPage", 0)
+	if recordPerPage == 0 {
+		return 0, 0
+	}
+	page := openapi.QueryInt(c, "page", 1)
+	return (page - 1) * recordPerPage, recordPerPage
+}
+
+func exportFields(c *gin.Context) []string {
+	if fields := c.Query("fields"); fields != "" {
+		return strings.Split(fields, ",")
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package controllers
+
+import (
+	"net/http"
+	"somdeep-demo-app/src/api/graphql/servers"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GraphQLController struct {
+	graphqlServer *servers.Server
+}
+
+func NewGraphQLController(graphqlServer *servers.Server) *GraphQLController {
+	return &GraphQLController{
+		graphqlServer: graphqlServer,
+	}
+}
+
+// GraphQLHandler answers with the bare GraphQL result rather than the usual
+// Response envelope, since that is what GraphQL clients expect. Errors raised
+// while resolving are reported in the result with a 200, as the spec asks.
+func (s *GraphQLController) GraphQLHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request servers.Request
+
+		if err := c.BindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"errors": []gin.H{{"message": "Error occured while binding JSON: " + err.Error()}}})
+			return
+		}
+		if request.Query == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"errors": []gin.H{{"message": "query is required"}}})
+			return
+		}
+
+		result := s.graphqlServer.Execute(c.Request.Context(), requestActor(c), request)
+
+		c.JSON(http.StatusOK, result)
+	}
+}
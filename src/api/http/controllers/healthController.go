@@ -0,0 +1,44 @@
+package controllers
+
+import (
+	"somdeep-demo-app/src/api/http/problems"
+	"somdeep-demo-app/src/health/interfaces"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthController struct {
+	healthService interfaces.HealthService
+}
+
+func NewHealthController(healthService interfaces.HealthService) *HealthController {
+	return &HealthController{
+		healthService: healthService,
+	}
+}
+
+func (s *HealthController) LivenessHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		response, err := s.healthService.GetLiveness()
+
+		if err != nil {
+			problems.Write(c, problems.New(response.Status, response.Message, err).WithData(response.Data))
+			return
+		}
+
+		c.JSON(response.Status, response)
+	}
+}
+
+func (s *HealthController) ReadinessHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		response, err := s.healthService.GetReadiness(c.Request.Context())
+
+		if err != nil {
+			problems.Write(c, problems.New(response.Status, response.Message, err).WithData(response.Data))
+			return
+		}
+
+		c.JSON(response.Status, response)
+	}
+}
@@ -0,0 +1,30 @@
+package controllers
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	closeStreams     = make(chan struct{})
+	closeStreamsOnce sync.Once
+)
+
+// CloseStreams ends every open event stream, so a shutdown does not wait on
+// clients that would otherwise stay connected until the drain deadline.
+// Clients reconnect elsewhere and resume with Last-Event-ID.
+func CloseStreams() {
+	closeStreamsOnce.Do(func() { close(closeStreams) })
+}
+
+// withoutDeadlines lifts the server's read and write timeouts for a request
+// that legitimately runs longer, such as an export, an import or an event
+// stream, which are bounded by their service timeouts instead.
+func withoutDeadlines(c *gin.Context) {
+	controller := http.NewResponseController(c.Writer)
+	controller.SetReadDeadline(time.Time{})
+	controller.SetWriteDeadline(time.Time{})
+}
@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"net/http"
+	"somdeep-demo-app/src/api/http/problems"
+	"somdeep-demo-app/src/logging/interfaces"
+	"somdeep-demo-app/src/logging/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LoggingController struct {
+	loggingService interfaces.LoggingService
+}
+
+func NewLoggingController(loggingService interfaces.LoggingService) *LoggingController {
+	return &LoggingController{
+		loggingService: loggingService,
+	}
+}
+
+func (s *LoggingController) GetLogLevelHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		response, err := s.loggingService.GetLogLevel()
+
+		if err != nil {
+			problems.Write(c, problems.New(response.Status, response.Message, err).WithData(response.Data))
+			return
+		}
+
+		c.JSON(response.Status, response)
+	}
+}
+
+func (s *LoggingController) SetLogLevelHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var level models.LogLevel
+
+		if err := c.BindJSON(&level); err != nil {
+			problems.Write(c, problems.New(http.StatusBadRequest, "Error occured while binding JSON", err))
+			return
+		}
+
+		response, err := s.loggingService.SetLogLevel(c.Request.Context(), level)
+
+		if err != nil {
+			problems.Write(c, problems.New(response.Status, response.Message, err).WithData(response.Data))
+			return
+		}
+
+		c.JSON(response.Status, response)
+	}
+}
@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"somdeep-demo-app/src/api/http/problems"
+	"somdeep-demo-app/src/outbox/interfaces"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OutboxController struct {
+	outboxService interfaces.OutboxService
+}
+
+func NewOutboxController(outboxService interfaces.OutboxService) *OutboxController {
+	return &OutboxController{
+		outboxService: outboxService,
+	}
+}
+
+func (s *OutboxController) GetOutboxStatsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		response, err := s.outboxService.GetOutboxStats(c.Request.Context())
+
+		if err != nil {
+			problems.Write(c, problems.New(response.Status, response.Message, err).WithData(response.Data))
+			return
+		}
+
+		c.JSON(response.Status, response)
+	}
+}
+
+func (s *OutboxController) RequeueDeadMessageHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		eventId := c.Param("event_id")
+
+		response, err := s.outboxService.RequeueDeadMessage(c.Request.Context(), eventId)
+
+		if err != nil {
+			problems.Write(c, problems.New(response.Status, response.Message, err).WithData(response.Data))
+			return
+		}
+
+		c.JSON(response.Status, response)
+	}
+}
@@ -0,0 +1,23 @@
+package controllers
+
+import (
+	"somdeep-demo-app/src/api/http/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultRecordPerPage is the page size of the HTTP lists when a request does
+// not set recordPerPage, and MaxRecordPerPage the largest the OpenAPI
+// document accepts.
+const (
+	DefaultRecordPerPage = 10
+	MaxRecordPerPage     = 100
+)
+
+// paging returns the page size, the 1-based page and the offset of a list
+// request, from the page and recordPerPage the OpenAPI validator checked.
+func paging(c *gin.Context) (recordPerPage int, page int, startIndex int) {
+	recordPerPage = openapi.QueryInt(c, "recordPerPage", DefaultRecordPerPage)
+	page = openapi.QueryInt(c, "page", 1)
+	return recordPerPage, page, (page - 1) * recordPerPage
+}
@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	eventModels "somdeep-demo-app/src/events/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// sseHeartbeat keeps idle connections from being closed by proxies.
+	sseHeartbeat = 15 * time.Second
+	// sseRetry is the reconnect delay suggested to EventSource clients.
+	sseRetry = 3 * time.Second
+)
+
+// lastEventId reads the resume position. Browsers send the Last-Event-ID header
+// when reconnecting; the query parameter covers the first connection.
+func lastEventId(c *gin.Context) string {
+	if id := c.GetHeader("Last-Event-ID"); id != "" {
+		return id
+	}
+	return c.Query("lastEventId")
+}
+
+// streamEvents writes events as Server-Sent Events until the channel closes,
+// the client goes away or the server shuts down. A reset is sent as a "reset" event without an ID, so
+// the client's resume position is left alone.
+func streamEvents(c *gin.Context, events <-chan eventModels.StreamedEvent) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	withoutDeadlines(c)
+
+	w := c.Writer
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", sseRetry.Milliseconds()); err != nil {
+		return
+	}
+	w.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		var err error
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-closeStreams:
+			return
+		case <-heartbeat.C:
+			_, err = io.WriteString(w, ": heartbeat\n\n")
+		case message, ok := <-events:
+			if !ok {
+				return
+			}
+			err = writeSSE(w, message)
+		}
+		if err != nil {
+			return
+		}
+		w.Flush()
+	}
+}
+
+func writeSSE(w io.Writer, message eventModels.StreamedEvent) error {
+	if message.Reset {
+		_, err := io.WriteString(w, "event: reset\ndata: {}\n\n")
+		return err
+	}
+
+	var b strings.Builder
+	if message.ID != "" {
+		fmt.Fprintf(&b, "id: %s\n", message.ID)
+	}
+	fmt.Fprintf(&b, "event: %s\n", message.Event.Type)
+	data, err := json.Marshal(message.Event)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(&b, "data: %s\n\n", data)
+	_, err = io.WriteString(w, b.String())
+	return err
+}
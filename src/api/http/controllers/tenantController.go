@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"net/http"
+	"somdeep-demo-app/src/api/http/problems"
+	"somdeep-demo-app/src/tenant/interfaces"
+	"somdeep-demo-app/src/tenant/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TenantController struct {
+	tenantService interfaces.TenantService
+}
+
+func NewTenantController(tenantService interfaces.TenantService) *TenantController {
+	return &TenantController{
+		tenantService: tenantService,
+	}
+}
+
+func (s *TenantController) GetTenantsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		response, err := s.tenantService.GetTenants(c.Request.Context())
+
+		if err != nil {
+			problems.Write(c, problems.New(response.Status, response.Message, err).WithData(response.Data))
+			return
+		}
+
+		c.JSON(response.Status, response)
+	}
+}
+
+func (s *TenantController) GetTenantHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantId := c.Param("tenant_id")
+
+		response, err := s.tenantService.GetTenant(c.Request.Context(), tenantId)
+
+		if err != nil {
+			problems.Write(c, problems.New(response.Status, response.Message, err).WithData(response.Data))
+			return
+		}
+
+		c.JSON(response.Status, response)
+	}
+}
+
+func (s *TenantController) ProvisionTenantHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var tenant models.Tenant
+
+		if err := c.BindJSON(&tenant); err != nil {
+			problems.Write(c, problems.New(http.StatusBadRequest, "Error occured while binding JSON", err))
+			return
+		}
+
+		response, err := s.tenantService.ProvisionTenant(c.Request.Context(), tenant)
+
+		if err != nil {
+			problems.Write(c, problems.New(response.Status, response.Message, err).WithData(response.Data))
+			return
+		}
+
+		c.JSON(response.Status, response)
+	}
+}
+
+func (s *TenantController) DeprovisionTenantHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantId := c.Param("tenant_id")
+
+		response, err := s.tenantService.DeprovisionTenant(c.Request.Context(), tenantId)
+
+		if err != nil {
+			problems.Write(c, problems.New(response.Status, response.Message, err).WithData(response.Data))
+			return
+		}
+
+		c.JSON(response.Status, response)
+	}
+}
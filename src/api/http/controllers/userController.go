@@ -2,9 +2,10 @@ package controllers
 
 import (
 	"net/http"
+	"somdeep-demo-app/src/api/http/problems"
+	"somdeep-demo-app/src/export"
 	"somdeep-demo-app/src/user/interfaces"
 	"somdeep-demo-app/src/user/models"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -21,20 +22,11 @@ func NewUserController(userService interfaces.UserService) *UserController {
 
 func (s *UserController) GetUsersHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		recordPerPage, err := strconv.Atoi(c.Query("recordPerPage"))
-		if err != nil || recordPerPage < 1 {
-			recordPerPage = 10
-		}
-		page, err := strconv.Atoi(c.Query("page"))
-		if err != nil || page < 1 {
-			page = 1
-		}
-
-		startIndex := (page - 1) * recordPerPage
-		response, err := s.userService.GetUsers(recordPerPage, page, startIndex)
+		recordPerPage, page, startIndex := paging(c)
+		response, err := s.userService.GetUsers(c.Request.Context(), recordPerPage, page, startIndex)
 
 		if err != nil {
-			c.JSON(response.Status, response)
+			problems.Write(c, problems.New(response.Status, response.Message, err).WithData(response.Data))
 			return
 		}
 
@@ -47,10 +39,10 @@ func (s *UserController) GetUserHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userId := c.Param("user_id")
 
-		response, err := s.userService.GetUser(userId)
+		response, err := s.userService.GetUser(c.Request.Context(), userId)
 
 		if err != nil {
-			c.JSON(response.Status, response)
+			problems.Write(c, problems.New(response.Status, response.Message, err).WithData(response.Data))
 			return
 		}
 
@@ -66,14 +58,14 @@ func (s *UserController) AddUserHandler() gin.HandlerFunc {
 		// convert the JSON data coming from FE to something that golang understands
 
 		if err := c.BindJSON(&user); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "message": "Error occured while binding JSON"})
+			problems.Write(c, problems.New(http.StatusBadRequest, "Error occured while binding JSON", err))
 			return
 		}
 
-		response, err := s.userService.AddUser(user)
+		response, err := s.userService.AddUser(c.Request.Context(), requestActor(c), user)
 
 		if err != nil {
-			c.JSON(response.Status, response)
+			problems.Write(c, problems.New(response.Status, response.Message, err).WithData(response.Data))
 			return
 		}
 
@@ -89,14 +81,14 @@ func (s *UserController) UpdateUserHandler() gin.HandlerFunc {
 		userId := c.Param("user_id")
 
 		if err := c.BindJSON(&user); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "message": "Error occured while binding JSON"})
+			problems.Write(c, problems.New(http.StatusBadRequest, "Error occured while binding JSON", err))
 			return
 		}
 
-		response, err := s.userService.UpdateUser(userId, user)
+		response, err := s.userService.UpdateUser(c.Request.Context(), requestActor(c), userId, user)
 
 		if err != nil {
-			c.JSON(response.Status, response)
+			problems.Write(c, problems.New(response.Status, response.Message, err).WithData(response.Data))
 			return
 		}
 
@@ -109,10 +101,10 @@ func (s *UserController) DeleteUserHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userId := c.Param("user_id")
 
-		response, err := s.userService.DeleteUser(userId)
+		response, err := s.userService.DeleteUser(c.Request.Context(), requestActor(c), userId)
 
 		if err != nil {
-			c.JSON(response.Status, response)
+			problems.Write(c, problems.New(response.Status, response.Message, err).WithData(response.Data))
 			return
 		}
 
@@ -120,3 +112,30 @@ func (s *UserController) DeleteUserHandler() gin.HandlerFunc {
 		c.JSON(response.Status, response)
 	}
 }
+
+func (s *UserController) ExportUsersHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		format := c.DefaultQuery("format", export.FormatCSV)
+		contentType, err := export.ContentType(format)
+		if err != nil {
+			problems.Write(c, problems.New(http.StatusBadRequest, "Validation Error", err))
+			return
+		}
+
+		var filter models.UserExportFilter
+		filter.StartIndex, filter.RecordPerPage = exportPaging(c)
+		filter.Fields = exportFields(c)
+
+		writer := newExportWriter(c, contentType, "users", format)
+		response, err := s.userService.ExportUsers(c.Request.Context(), filter, format, writer)
+
+		if err != nil && !c.Writer.Written() {
+			problems.Write(c, problems.New(response.Status, response.Message, err).WithData(response.Data))
+			return
+		}
+		if err != nil {
+			// the download has already started, all we can do is cut it short
+			c.Abort()
+		}
+	}
+}
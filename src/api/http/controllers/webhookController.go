@@ -0,0 +1,177 @@
+package controllers
+
+import (
+	"net/http"
+	"somdeep-demo-app/src/api/http/problems"
+	"somdeep-demo-app/src/webhook/interfaces"
+	"somdeep-demo-app/src/webhook/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookController struct {
+	webhookService interfaces.WebhookService
+}
+
+func NewWebhookController(webhookService interfaces.WebhookService) *WebhookController {
+	return &WebhookController{
+		webhookService: webhookService,
+	}
+}
+
+func (s *WebhookController) AddWebhookHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId := c.Param("user_id")
+		var webhook models.Webhook
+
+		if err := c.BindJSON(&webhook); err != nil {
+			problems.Write(c, problems.New(http.StatusBadRequest, "Error occured while binding JSON", err))
+			return
+		}
+
+		response, err := s.webhookService.AddWebhook(c.Request.Context(), userId, webhook)
+
+		if err != nil {
+			problems.Write(c, problems.New(response.Status, response.Message, err).WithData(response.Data))
+			return
+		}
+
+		c.JSON(response.Status, response)
+	}
+}
+
+func (s *WebhookController) GetWebhooksHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId := c.Param("user_id")
+
+		response, err := s.webhookService.GetWebhooks(c.Request.Context(), userId)
+
+		if err != nil {
+			problems.Write(c, problems.New(response.Status, response.Message, err).WithData(response.Data))
+			return
+		}
+
+		c.JSON(response.Status, response)
+	}
+}
+
+func (s *WebhookController) GetWebhookHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId := c.Param("user_id")
+		webhookId := c.Param("webhook_id")
+
+		response, err := s.webhookService.GetWebhook(c.Request.Context(), userId, webhookId)
+
+		if err != nil {
+			problems.Write(c, problems.New(response.Status, response.Message, err).WithData(response.Data))
+			return
+		}
+
+		c.JSON(response.Status, response)
+	}
+}
+
+func (s *WebhookController) UpdateWebhookHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var webhook models.Webhook
+
+		userId := c.Param("user_id")
+		webhookId := c.Param("webhook_id")
+
+		if err := c.BindJSON(&webhook); err != nil {
+			problems.Write(c, problems.New(http.StatusBadRequest, "Error occured while binding JSON", err))
+			return
+		}
+
+		response, err := s.webhookService.UpdateWebhook(c.Request.Context(), userId, webhookId, webhook)
+
+		if err != nil {
+			problems.Write(c, problems.New(response.Status, response.Message, err).WithData(response.Data))
+			return
+		}
+
+		c.JSON(response.Status, response)
+	}
+}
+
+func (s *WebhookController) DeleteWebhookHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId := c.Param("user_id")
+		webhookId := c.Param("webhook_id")
+
+		response, err := s.webhookService.DeleteWebhook(c.Request.Context(), userId, webhookId)
+
+		if err != nil {
+			problems.Write(c, problems.New(response.Status, response.Message, err).WithData(response.Data))
+			return
+		}
+
+		c.JSON(response.Status, response)
+	}
+}
+
+func (s *WebhookController) PingWebhookHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId := c.Param("user_id")
+		webhookId := c.Param("webhook_id")
+
+		response, err := s.webhookService.PingWebhook(c.Request.Context(), userId, webhookId)
+
+		if err != nil {
+			problems.Write(c, problems.New(response.Status, response.Message, err).WithData(response.Data))
+			return
+		}
+
+		c.JSON(response.Status, response)
+	}
+}
+
+func (s *WebhookController) GetDeliveriesHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId := c.Param("user_id")
+		webhookId := c.Param("webhook_id")
+		recordPerPage, page, startIndex := paging(c)
+		response, err := s.webhookService.GetDeliveries(c.Request.Context(), userId, webhookId, c.Query("status"), recordPerPage, page, startIndex)
+
+		if err != nil {
+			problems.Write(c, problems.New(response.Status, response.Message, err).WithData(response.Data))
+			return
+		}
+
+		c.JSON(response.Status, response)
+	}
+}
+
+func (s *WebhookController) GetDeliveryHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId := c.Param("user_id")
+		webhookId := c.Param("webhook_id")
+		deliveryId := c.Param("delivery_id")
+
+		response, err := s.webhookService.GetDelivery(c.Request.Context(), userId, webhookId, deliveryId)
+
+		if err != nil {
+			problems.Write(c, problems.New(response.Status, response.Message, err).WithData(response.Data))
+			return
+		}
+
+		c.JSON(response.Status, response)
+	}
+}
+
+func (s *WebhookController) RedeliverHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId := c.Param("user_id")
+		webhookId := c.Param("webhook_id")
+		deliveryId := c.Param("delivery_id")
+
+		response, err := s.webhookService.Redeliver(c.Request.Context(), userId, webhookId, deliveryId)
+
+		if err != nil {
+			problems.Write(c, problems.New(response.Status, response.Message, err).WithData(response.Data))
+			return
+		}
+
+		c.JSON(response.Status, response)
+	}
+}
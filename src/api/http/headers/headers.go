@@ -0,0 +1,14 @@
+// Package headers names the HTTP headers shared by the middleware, the
+// controllers and the OpenAPI document.
+package headers
+
+const (
+	// Actor names the caller in the audit trail. It is not verified: the
+	// gateway in front of the API is trusted to set it, and a client that
+	// reaches the API directly can claim to be anyone.
+	Actor = "X-Actor"
+	// RequestId carries the ID of a request: it is taken from the client when
+	// set, generated otherwise, and echoed on the response and in every log
+	// line of the request.
+	RequestId = "X-Request-ID"
+)
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"somdeep-demo-app/src/api/http/problems"
+	"somdeep-demo-app/src/apperrors"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// adminPrefix is where the routes operating on the whole deployment live:
+// tenant provisioning, the log level, the cache and the outbox.
+const adminPrefix = "/admin/"
+
+// Admin requires the bearer token token on every /admin route. Without a
+// token the admin routes are refused altogether, so they are never open by
+// accident.
+func Admin(token []byte) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !strings.HasPrefix(c.Request.URL.Path, adminPrefix) {
+			c.Next()
+			return
+		}
+		if len(token) == 0 {
+			problems.Write(c, problems.New(http.StatusUnauthorized, "", apperrors.Unauthorized("The admin API is disabled, set admin.token_file to enable it")))
+			return
+		}
+		presented, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !found || subtle.ConstantTimeCompare([]byte(strings.TrimSpace(presented)), token) != 1 {
+			problems.Write(c, problems.New(http.StatusUnauthorized, "", apperrors.Unauthorized("The admin API requires the admin bearer token")))
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name          string
+		token         string
+		path          string
+		authorization string
+		status        int
+	}{
+		{name: "valid token", token: "s3cret", path: "/admin/tenants", authorization: "Bearer s3cret", status: http.StatusOK},
+		{name: "wrong token", token: "s3cret", path: "/admin/tenants", authorization: "Bearer guess", status: http.StatusUnauthorized},
+		{name: "no token", token: "s3cret", path: "/admin/cache", status: http.StatusUnauthorized},
+		{name: "not a bearer token", token: "s3cret", path: "/admin/log-level", authorization: "Basic s3cret", status: http.StatusUnauthorized},
+		{name: "admin disabled", path: "/admin/tenants", authorization: "Bearer ", status: http.StatusUnauthorized},
+		{name: "other routes are open", token: "s3cret", path: "/users", status: http.StatusOK},
+		{name: "prefix is a whole segment", token: "s3cret", path: "/administrators", status: http.StatusOK},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(Admin([]byte(test.token)))
+			router.NoRoute(func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			request := httptest.NewRequest("GET", test.path, nil)
+			if test.authorization != "" {
+				request.Header.Set("Authorization", test.authorization)
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			if recorder.Code != test.status {
+				t.Errorf("status = %d, want %d", recorder.Code, test.status)
+			}
+		})
+	}
+}
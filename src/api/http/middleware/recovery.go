@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"somdeep-demo-app/src/api/http/problems"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Recovery turns a panic in a later handler into a 500 problem response,
+// logging the panic and its stack with the request ID. Nothing about the panic
+// is sent to the client. http.ErrAbortHandler is passed on so the server can
+// drop the connection as asked.
+func Recovery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if err, ok := recovered.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(recovered)
+			}
+
+			slog.ErrorContext(c.Request.Context(), "http: handler panicked",
+				"method", c.Request.Method,
+				"route", c.FullPath(),
+				"panic", fmt.Sprint(recovered),
+				"stack", string(debug.Stack()))
+			if c.Writer.Written() {
+				// the response has started, all we can do is cut it short
+				c.Abort()
+				return
+			}
+			problems.Write(c, problems.New(http.StatusInternalServerError, "The server hit an unexpected error", nil))
+		}()
+		c.Next()
+	}
+}
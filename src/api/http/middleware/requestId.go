@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"somdeep-demo-app/src/api/http/headers"
+	"somdeep-demo-app/src/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxRequestIdLength bounds a caller supplied request ID, which ends up in
+// every log line and audit entry of the request.
+const maxRequestIdLength = 128
+
+// RequestId accepts the caller's X-Request-ID or generates one, echoes it in
+// the response and carries it in the request context, where the services,
+// the repositories and the logger pick it up. It is also recorded on the
+// request's span.
+func RequestId() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestId := c.GetHeader(headers.RequestId)
+		if requestId == "" || len(requestId) > maxRequestIdLength {
+			requestId = uuid.New().String()
+		}
+		c.Header(headers.RequestId, requestId)
+		trace.SpanFromContext(c.Request.Context()).SetAttributes(attribute.String("app.request_id", requestId))
+		c.Request = c.Request.WithContext(logging.WithRequestId(c.Request.Context(), requestId))
+		c.Next()
+	}
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+	"somdeep-demo-app/src/api/http/problems"
+	"somdeep-demo-app/src/tenant"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// untenanted are the path prefixes that serve the whole deployment rather
+// than one tenant: tenant provisioning, process-wide admin, probes, metrics
+// and the API documentation.
+var untenanted = []string{
+	"/admin/tenants",
+	"/admin/log-level",
+	"/admin/cache",
+	"/metrics",
+	"/healthz",
+	"/readyz",
+	"/openapi.json",
+	"/docs",
+}
+
+// Tenant resolves the tenant of every other request and carries it in the
+// request context, which the repositories scope each query to. A request
+// whose tenant cannot be resolved is rejected before any handler runs. The
+// tenant is also recorded on the request's span.
+func Tenant(resolver *tenant.Resolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, prefix := range untenanted {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				c.Next()
+				return
+			}
+		}
+
+		tenantId, err := resolver.Resolve(c.GetHeader, c.Request.Host)
+		if err != nil {
+			problems.Write(c, problems.New(http.StatusBadRequest, "The tenant could not be resolved", err))
+			return
+		}
+		trace.SpanFromContext(c.Request.Context()).SetAttributes(attribute.String("app.tenant_id", tenantId))
+		c.Request = c.Request.WithContext(tenant.WithTenant(c.Request.Context(), tenantId))
+		c.Next()
+	}
+}
@@ -0,0 +1,373 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"somdeep-demo-app/src/api/http/problems"
+	"somdeep-demo-app/src/apperrors"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ValidatorOptions struct {
+	// ValidateResponses checks JSON responses against the document as well.
+	// A mismatch cannot be undone once the response is written, so it is
+	// reported to OnResponseError; meant for tests and staging.
+	ValidateResponses bool
+	// OnResponseError receives response mismatches; they are logged when nil.
+	OnResponseError func(c *gin.Context, errs []ValidationError)
+	// MaxBodyBytes bounds the JSON bodies read for validation; larger ones
+	// are rejected with 413. DefaultMaxBodyBytes applies when zero.
+	MaxBodyBytes int64
+}
+
+// DefaultMaxBodyBytes is the JSON body limit when none is configured.
+const DefaultMaxBodyBytes = 1 << 20
+
+// checkedQueryKey is the gin context key of the query parameters a request
+// passed validation with.
+const checkedQueryKey = "openapi.query"
+
+// QueryInt returns the integer query parameter name as the validator checked
+// it against the document, or fallback when the request does not set it.
+// Handlers read their parameters through it rather than parsing the query
+// again, so they only ever see values the document allows.
+func QueryInt(c *gin.Context, name string, fallback int) int {
+	value, _ := c.Get(checkedQueryKey)
+	text, ok := value.(map[string]string)[name]
+	if !ok {
+		return fallback
+	}
+	number, err := strconv.Atoi(text)
+	if err != nil {
+		return fallback
+	}
+	return number
+}
+
+// Validator is middleware that rejects requests which do not match the
+// document. It is installed before the routes are registered and armed with
+// SetDocument once the document describing them is built; until then it lets
+// everything through.
+type Validator struct {
+	options ValidatorOptions
+	routes  atomic.Pointer[routeIndex]
+}
+
+// routeIndex holds the documented path templates by method.
+type routeIndex struct {
+	document  *Document
+	templates map[string][]template
+}
+
+type template struct {
+	segments []string
+	item     *pathItem
+}
+
+func NewValidator(options ValidatorOptions) *Validator {
+	if options.MaxBodyBytes <= 0 {
+		options.MaxBodyBytes = DefaultMaxBodyBytes
+	}
+	return &Validator{options: options}
+}
+
+func (v *Validator) SetDocument(document *Document) {
+	index := &routeIndex{document: document, templates: map[string][]template{}}
+	for path, methods := range document.Paths {
+		for method, item := range methods {
+			method = strings.ToUpper(method)
+			index.templates[method] = append(index.templates[method], template{segments: strings.Split(path, "/"), item: item})
+		}
+	}
+	v.routes.Store(index)
+}
+
+func (v *Validator) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		index := v.routes.Load()
+		if index == nil {
+			c.Next()
+			return
+		}
+		item, pathValues := index.match(c.Request.Method, c.Request.URL.Path)
+		if item == nil {
+			c.Next()
+			return
+		}
+
+		validator := &schemaValidator{schemas: index.document.Components.Schemas, strict: true}
+		status := validator.request(c, index, item, pathValues, v.options.MaxBodyBytes)
+		if len(validator.errors) > 0 {
+			problems.Write(c, rejection(status, validator.errors))
+			return
+		}
+
+		if !v.options.ValidateResponses || !jsonResponse(item) {
+			c.Next()
+			return
+		}
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+		if errs := validateResponse(index, item, c.Writer.Status(), c.Writer.Header().Get("Content-Type"), recorder.body.Bytes()); len(errs) > 0 {
+			v.reportResponse(c, errs)
+		}
+	}
+}
+
+func (v *Validator) reportResponse(c *gin.Context, errs []ValidationError) {
+	if v.options.OnResponseError != nil {
+		v.options.OnResponseError(c, errs)
+		return
+	}
+	slog.WarnContext(c.Request.Context(), "openapi: response outside the contract",
+		"method", c.Request.Method,
+		"path", c.Request.URL.Path,
+		"status", c.Writer.Status(),
+		"errors", summary(errs))
+}
+
+// match finds the template for a request path, preferring the one with the
+// most literal segments so /users/export wins over /users/{user_id}.
+func (index *routeIndex) match(method string, path string) (*pathItem, map[string]string) {
+	segments := strings.Split(path, "/")
+	var best *template
+	bestLiterals := -1
+	for i := range index.templates[method] {
+		candidate := &index.templates[method][i]
+		if len(candidate.segments) != len(segments) {
+			continue
+		}
+		literals := 0
+		matched := true
+		for j, segment := range candidate.segments {
+			if strings.HasPrefix(segment, "{") {
+				continue
+			}
+			if segment != segments[j] {
+				matched = false
+				break
+			}
+			literals++
+		}
+		if matched && literals > bestLiterals {
+			best, bestLiterals = candidate, literals
+		}
+	}
+	if best == nil {
+		return nil, nil
+	}
+	values := map[string]string{}
+	for j, segment := range best.segments {
+		if strings.HasPrefix(segment, "{") {
+			values[strings.Trim(segment, "{}")] = segments[j]
+		}
+	}
+	return best.item, values
+}
+
+// request validates the parameters and body, returning the status to reject
+// with if anything failed. Only JSON bodies are read, up to maxBody bytes;
+// other media types, such as CSV and NDJSON imports, are streamed to the
+// handler unread once their content type is checked.
+func (v *schemaValidator) request(c *gin.Context, index *routeIndex, item *pathItem, pathValues map[string]string, maxBody int64) int {
+	query := c.Request.URL.Query()
+	known := map[string]bool{}
+	checked := map[string]string{}
+	for _, parameter := range item.Parameters {
+		if parameter.Ref != "" {
+			parameter = index.document.Components.Parameters[strings.TrimPrefix(parameter.Ref, "#/components/parameters/")]
+		}
+		if parameter == nil {
+			continue
+		}
+
+		var value string
+		var present bool
+		switch parameter.In {
+		case "path":
+			value, present = pathValues[parameter.Name]
+		case "query":
+			known[parameter.Name] = true
+			if values, ok := query[parameter.Name]; ok {
+				value, present = values[len(values)-1], true
+				checked[parameter.Name] = value
+			}
+		case "header":
+			value = c.GetHeader(parameter.Name)
+			present = value != ""
+		}
+		if !present {
+			if parameter.Required {
+				v.fail(parameter.In, parameter.Name, "is required")
+			}
+			continue
+		}
+		if parameter.Schema != nil {
+			v.parameter(parameter.In, parameter.Name, parameter.Schema, value)
+		}
+	}
+	for name := range query {
+		if !known[name] {
+			v.fail("query", name, "is not a known parameter")
+		}
+	}
+	if len(v.errors) == 0 {
+		c.Set(checkedQueryKey, checked)
+	}
+	if len(v.errors) > 0 || item.RequestBody == nil {
+		return http.StatusBadRequest
+	}
+
+	contentType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	if contentType == "" && item.RequestBody.Content["application/json"] != nil {
+		contentType = "application/json"
+	}
+	media, ok := item.RequestBody.Content[contentType]
+	if !ok {
+		if c.Request.ContentLength == 0 {
+			if item.RequestBody.Required {
+				v.fail("body", "", "is required")
+			}
+			return http.StatusBadRequest
+		}
+		v.fail("header", "Content-Type", "must be one of %s", strings.Join(mediaTypes(item.RequestBody.Content), ", "))
+		return http.StatusUnsupportedMediaType
+	}
+	if contentType != "application/json" {
+		return http.StatusBadRequest
+	}
+
+	raw, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBody))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			v.fail("body", "", "is larger than %d bytes", tooLarge.Limit)
+			return http.StatusRequestEntityTooLarge
+		}
+		v.fail("body", "", "could not be read: %v", err)
+		return http.StatusBadRequest
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(raw))
+	if len(raw) == 0 {
+		if item.RequestBody.Required {
+			v.fail("body", "", "is required")
+		}
+		return http.StatusBadRequest
+	}
+
+	value, err := decodeJSON(raw)
+	if err != nil {
+		v.fail("body", "", "is not valid JSON: %v", err)
+		return http.StatusBadRequest
+	}
+	v.value("body", "", media.Schema, value)
+	return http.StatusBadRequest
+}
+
+func validateResponse(index *routeIndex, item *pathItem, status int, contentType string, body []byte) []ValidationError {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if (mediaType != "application/json" && mediaType != problems.ContentType) || len(body) == 0 {
+		return nil
+	}
+	documented, ok := item.Responses[strconv.Itoa(status)]
+	if !ok {
+		documented = item.Responses["default"]
+	}
+	if documented == nil || documented.Content[mediaType] == nil {
+		return []ValidationError{{In: "response", Name: strconv.Itoa(status), Error: "status is not documented as " + mediaType}}
+	}
+
+	validator := &schemaValidator{schemas: index.document.Components.Schemas}
+	value, err := decodeJSON(body)
+	if err != nil {
+		validator.fail("response", "", "is not valid JSON: %v", err)
+		return validator.errors
+	}
+	validator.value("response", "", documented.Content[mediaType].Schema, value)
+	return validator.errors
+}
+
+// jsonResponse reports whether the operation answers with JSON; streams and
+// exports are not buffered for response validation.
+func jsonResponse(item *pathItem) bool {
+	for _, response := range item.Responses {
+		if response.Content["application/json"] != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func decodeJSON(raw []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, errors.New("unexpected data after the JSON value")
+	}
+	return value, nil
+}
+
+func mediaTypes(content map[string]*mediaType) []string {
+	types := make([]string, 0, len(content))
+	for contentType := range content {
+		types = append(types, contentType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// rejection describes a request the contract rejects. Mismatches are reported
+// as validation problems listing each value; anything else, such as an
+// unsupported content type, keeps its own status.
+func rejection(status int, errs []ValidationError) *problems.Problem {
+	const message = "Request does not match the API contract"
+	if status != http.StatusBadRequest {
+		return problems.New(status, message, errors.New(summary(errs)))
+	}
+	fields := make([]apperrors.FieldError, len(errs))
+	for i, err := range errs {
+		fields[i] = apperrors.FieldError{Field: join(err.In, err.Name), Message: err.Error}
+	}
+	return problems.New(status, message, apperrors.Validation(message, fields...))
+}
+
+func summary(errs []ValidationError) string {
+	parts := make([]string, len(errs))
+	for i, err := range errs {
+		name := join(err.In, err.Name)
+		parts[i] = name + " " + err.Error
+	}
+	return strings.Join(parts, "; ")
+}
+
+// responseRecorder keeps a copy of what the handler writes.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(data string) (int, error) {
+	w.body.WriteString(data)
+	return w.ResponseWriter.WriteString(data)
+}
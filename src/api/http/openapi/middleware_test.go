@@ -0,0 +1,113 @@
+package openapi
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type widget struct {
+	Name string `json:"name"`
+}
+
+// bodyRouter serves a JSON endpoint and a CSV upload behind a validator
+// limited to maxBody bytes. The upload answers with what the handler read.
+func bodyRouter(t *testing.T, maxBody int64) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	validator := NewValidator(ValidatorOptions{MaxBodyBytes: maxBody})
+	router := gin.New()
+	router.Use(validator.Middleware())
+	router.POST("/widgets", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	router.POST("/uploads", func(c *gin.Context) {
+		data, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, "%d", len(data))
+	})
+	document, err := Build(Info{Title: "test", Version: "1"}, router.Routes(), []Operation{
+		{Method: "POST", Path: "/widgets", Id: "addWidget", Request: widget{}, Status: http.StatusNoContent},
+		{Method: "POST", Path: "/uploads", Id: "upload", RequestTypes: []string{"text/csv"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	validator.SetDocument(document)
+	return router
+}
+
+func TestValidatorBodies(t *testing.T) {
+	upload := strings.Repeat("a,b\n", 1000)
+
+	tests := []struct {
+		name        string
+		path        string
+		contentType string
+		body        string
+		status      int
+		response    string
+	}{
+		{name: "json within the limit", path: "/widgets", contentType: "application/json", body: `{"name":"w"}`, status: http.StatusNoContent},
+		{name: "json over the limit", path: "/widgets", contentType: "application/json", body: `{"name":"` + strings.Repeat("w", 100) + `"}`, status: http.StatusRequestEntityTooLarge},
+		{name: "invalid json", path: "/widgets", contentType: "application/json", body: `{"name":`, status: http.StatusBadRequest},
+		{name: "csv is passed through unread", path: "/uploads", contentType: "text/csv", body: upload, status: http.StatusOK, response: "4000"},
+		{name: "undocumented media type", path: "/uploads", contentType: "application/xml", body: "<a/>", status: http.StatusUnsupportedMediaType},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest("POST", test.path, strings.NewReader(test.body))
+			request.Header.Set("Content-Type", test.contentType)
+			recorder := httptest.NewRecorder()
+			bodyRouter(t, 64).ServeHTTP(recorder, request)
+
+			if recorder.Code != test.status {
+				t.Errorf("status = %d, want %d: %s", recorder.Code, test.status, recorder.Body)
+			}
+			if test.response != "" && recorder.Body.String() != test.response {
+				t.Errorf("handler read %s bytes, want %s", recorder.Body, test.response)
+			}
+		})
+	}
+}
+
+func TestQueryIntReadsCheckedParameters(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	one, hundred := 1.0, 100.0
+	validator := NewValidator(ValidatorOptions{})
+	router := gin.New()
+	router.Use(validator.Middleware())
+	router.GET("/widgets", func(c *gin.Context) {
+		c.String(http.StatusOK, "%d/%d", QueryInt(c, "page", 1), QueryInt(c, "recordPerPage", 10))
+	})
+	document, err := Build(Info{Title: "test", Version: "1"}, router.Routes(), []Operation{
+		{Method: "GET", Path: "/widgets", Id: "listWidgets", Response: []widget{}, Query: []Parameter{
+			{Name: "page", Type: "integer", Minimum: &one},
+			{Name: "recordPerPage", Type: "integer", Minimum: &one, Maximum: &hundred},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	validator.SetDocument(document)
+
+	tests := []struct {
+		query    string
+		status   int
+		response string
+	}{
+		{query: "", status: http.StatusOK, response: "1/10"},
+		{query: "?page=3&recordPerPage=100", status: http.StatusOK, response: "3/100"},
+		{query: "?recordPerPage=101", status: http.StatusBadRequest},
+		{query: "?recordPerPage=0", status: http.StatusBadRequest},
+		{query: "?page=two", status: http.StatusBadRequest},
+	}
+	for _, test := range tests {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest("GET", "/widgets"+test.query, nil))
+		if recorder.Code != test.status || (test.response != "" && recorder.Body.String() != test.response) {
+			t.Errorf("GET /widgets%s = %d %s, want %d %s", test.query, recorder.Code, recorder.Body, test.status, test.response)
+		}
+	}
+}
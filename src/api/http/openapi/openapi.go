@@ -0,0 +1,312 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"somdeep-demo-app/src/api/http/headers"
+	"somdeep-demo-app/src/api/http/problems"
+
+	"github.com/gin-gonic/gin"
+)
+
+const Version = "3.1.0"
+
+// Operation documents one endpoint. Method and Path are the gin registration
+// the operation is served by; the generated path uses {param} syntax.
+type Operation struct {
+	Method      string
+	Path        string
+	Id          string
+	Tag         string
+	Summary     string
+	Description string
+	// SpecPath overrides the documented path when the gin route is a
+	// placeholder, like the /users/:user_id/:action custom methods.
+	SpecPath string
+	Query    []Parameter
+	// Request is a value of the JSON body type, wrapped in Partial for
+	// updates where every field is optional.
+	Request any
+	// RequestTypes lists raw (non-JSON model) request media types.
+	RequestTypes []string
+	// Response is a value of the type the service puts in Response.Data.
+	Response any
+	// ResponseTypes replaces the JSON envelope with raw media types, as for
+	// exports and event streams.
+	ResponseTypes []string
+	// Status is the success status, 200 when zero.
+	Status int
+}
+
+// Parameter is a query string parameter.
+type Parameter struct {
+	Name        string
+	Type        string
+	Description string
+	Enum        []string
+	Minimum     *float64
+	Maximum     *float64
+}
+
+// Partial wraps a request model whose fields are all optional.
+type Partial struct {
+	Of any
+}
+
+// Page is the {total_count, items} document paginated list services return.
+type Page[T any] struct {
+	Total_count int `json:"total_count"`
+	Items       []T `json:"items"`
+}
+
+type Document struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       Info                            `json:"info"`
+	Paths      map[string]map[string]*pathItem `json:"paths"`
+	Components Components                      `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type Components struct {
+	Schemas    map[string]*Schema    `json:"schemas"`
+	Parameters map[string]*parameter `json:"parameters"`
+}
+
+type pathItem struct {
+	OperationId string               `json:"operationId"`
+	Tags        []string             `json:"tags,omitempty"`
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Parameters  []*parameter         `json:"parameters,omitempty"`
+	RequestBody *body                `json:"requestBody,omitempty"`
+	Responses   map[string]*response `json:"responses"`
+}
+
+type parameter struct {
+	Ref         string  `json:"$ref,omitempty"`
+	Name        string  `json:"name,omitempty"`
+	In          string  `json:"in,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema,omitempty"`
+}
+
+type body struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*mediaType `json:"content"`
+}
+
+type response struct {
+	Description string                `json:"description"`
+	Content     map[string]*mediaType `json:"content,omitempty"`
+}
+
+type mediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Undocumented lists the registered routes that no operation covers.
+func Undocumented(routes gin.RoutesInfo, operations []Operation) []string {
+	documented := map[string]bool{}
+	for _, operation := range operations {
+		documented[operation.Method+" "+operation.Path] = true
+	}
+	var missing []string
+	for _, route := range routes {
+		if !documented[route.Method+" "+route.Path] {
+			missing = append(missing, route.Method+" "+route.Path)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// Build describes the registered routes. It fails when a route has no
+// operation, or an operation has no route, so the document cannot drift from
+// the router.
+func Build(info Info, routes gin.RoutesInfo, operations []Operation) (*Document, error) {
+	if missing := Undocumented(routes, operations); len(missing) > 0 {
+		return nil, fmt.Errorf("openapi: routes without an operation: %s", strings.Join(missing, ", "))
+	}
+	registered := map[string]bool{}
+	for _, route := range routes {
+		registered[route.Method+" "+route.Path] = true
+	}
+
+	g := newGenerator()
+	document := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   map[string]map[string]*pathItem{},
+		Components: Components{
+			Schemas:    g.schemas,
+			Parameters: headerParameters(),
+		},
+	}
+	for _, operation := range operations {
+		if !registered[operation.Method+" "+operation.Path] {
+			return nil, fmt.Errorf("openapi: operation %s documents %s %s, which is not registered", operation.Id, operation.Method, operation.Path)
+		}
+		path := operation.SpecPath
+		if path == "" {
+			path = operation.Path
+		}
+		path, pathParameters := specPath(path)
+		if document.Paths[path] == nil {
+			document.Paths[path] = map[string]*pathItem{}
+		}
+		document.Paths[path][strings.ToLower(operation.Method)] = g.operation(operation, pathParameters)
+	}
+	return document, nil
+}
+
+func (g *generator) operation(operation Operation, pathParameters []*parameter) *pathItem {
+	item := &pathItem{
+		OperationId: operation.Id,
+		Summary:     operation.Summary,
+		Description: operation.Description,
+		Parameters:  pathParameters,
+		Responses:   map[string]*response{},
+	}
+	if operation.Tag != "" {
+		item.Tags = []string{operation.Tag}
+	}
+	for _, query := range operation.Query {
+		item.Parameters = append(item.Parameters, &parameter{
+			Name:        query.Name,
+			In:          "query",
+			Description: query.Description,
+			Schema:      &Schema{Type: query.Type, Enum: query.Enum, Minimum: query.Minimum, Maximum: query.Maximum},
+		})
+	}
+	if operation.Method != http.MethodGet {
+		item.Parameters = append(item.Parameters,
+			&parameter{Ref: "#/components/parameters/Actor"},
+			&parameter{Ref: "#/components/parameters/RequestId"})
+	}
+
+	switch {
+	case operation.Request != nil:
+		item.RequestBody = &body{Required: true, Content: map[string]*mediaType{
+			"application/json": {Schema: g.request(operation.Request)},
+		}}
+	case len(operation.RequestTypes) > 0:
+		item.RequestBody = &body{Required: true, Content: map[string]*mediaType{}}
+		for _, contentType := range operation.RequestTypes {
+			item.RequestBody.Content[contentType] = &mediaType{Schema: rawSchema(contentType)}
+		}
+	}
+
+	status := operation.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := &response{Description: http.StatusText(status), Content: map[string]*mediaType{}}
+	if len(operation.ResponseTypes) > 0 {
+		for _, contentType := range operation.ResponseTypes {
+			success.Content[contentType] = &mediaType{Schema: rawSchema(contentType)}
+		}
+	} else {
+		success.Content["application/json"] = &mediaType{Schema: g.envelope(operation.Response)}
+	}
+	item.Responses[strconv.Itoa(status)] = success
+	item.Responses["default"] = &response{
+		Description: "Error",
+		Content: map[string]*mediaType{
+			problems.ContentType: {Schema: g.schema(reflect.TypeOf(problems.Problem{}))},
+		},
+	}
+	return item
+}
+
+// request describes a request body, registering a copy of the model without
+// required fields for Partial bodies.
+func (g *generator) request(value any) *Schema {
+	partial, ok := value.(Partial)
+	if !ok {
+		return g.schema(reflect.TypeOf(value))
+	}
+	full := g.schema(reflect.TypeOf(partial.Of))
+	if full.Ref == "" {
+		full.Required = nil
+		return full
+	}
+	name := strings.TrimPrefix(full.Ref, "#/components/schemas/")
+	if _, ok := g.schemas[name+"Update"]; !ok {
+		update := *g.schemas[name]
+		update.Required = nil
+		g.schemas[name+"Update"] = &update
+	}
+	return &Schema{Ref: "#/components/schemas/" + name + "Update"}
+}
+
+// envelope describes the {status, message, error, data} Response every
+// service returns.
+func (g *generator) envelope(data any) *Schema {
+	dataSchema := &Schema{}
+	if data != nil {
+		dataSchema = g.schema(reflect.TypeOf(data))
+	}
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"status":  {Type: "integer"},
+			"message": {Type: "string"},
+			"error":   {Type: "string"},
+			"data":    dataSchema,
+		},
+	}
+}
+
+func rawSchema(contentType string) *Schema {
+	switch {
+	case contentType == "multipart/form-data":
+		return &Schema{Type: "object", Properties: map[string]*Schema{"file": {Type: "string", Format: "binary"}}}
+	case contentType == "application/json":
+		return &Schema{Type: "object"}
+	case strings.HasPrefix(contentType, "application/vnd.") || contentType == "application/octet-stream":
+		return &Schema{Type: "string", Format: "binary"}
+	}
+	return &Schema{Type: "string"}
+}
+
+// specPath converts gin's :param segments to {param} and describes them.
+func specPath(path string) (string, []*parameter) {
+	var parameters []*parameter
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			name := segment[1:]
+			segments[i] = "{" + name + "}"
+			parameters = append(parameters, &parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+		}
+	}
+	return strings.Join(segments, "/"), parameters
+}
+
+func headerParameters() map[string]*parameter {
+	return map[string]*parameter{
+		"Actor": {
+			Name:        headers.Actor,
+			In:          "header",
+			Description: "Who is making the change, recorded in the audit trail. Not verified: the gateway in front of the API is expected to set it.",
+			Schema:      &Schema{Type: "string"},
+		},
+		"RequestId": {
+			Name:        headers.RequestId,
+			In:          "header",
+			Description: "Correlation id recorded with the change.",
+			Schema:      &Schema{Type: "string"},
+		},
+	}
+}
@@ -0,0 +1,237 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Schema is the subset of JSON Schema (2020-12, as used by OpenAPI 3.1) the
+// generator emits.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	rawJSONType  = reflect.TypeOf(json.RawMessage{})
+	objectIdType = reflect.TypeOf(primitive.ObjectID{})
+	// genericName splits "Page[somdeep-demo-app/src/user/models.User]".
+	genericName = regexp.MustCompile(`^(\w+)\[(?:.*\.)?(\w+)\]$`)
+)
+
+// generator turns Go types into schemas, collecting named structs under
+// components/schemas so each model is described once and referenced.
+type generator struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func newGenerator() *generator {
+	return &generator{
+		schemas: map[string]*Schema{},
+		names:   map[reflect.Type]string{},
+	}
+}
+
+func (g *generator) schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case objectIdType:
+		return &Schema{Type: "string", Pattern: "^[0-9a-f]{24}$"}
+	case rawJSONType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + g.component(t)}
+	}
+	// interface{} and anything else: any JSON value.
+	return &Schema{}
+}
+
+// component registers a named struct and returns its component name.
+func (g *generator) component(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	if match := genericName.FindStringSubmatch(name); match != nil {
+		name = match[2] + match[1]
+	}
+	if _, taken := g.schemas[name]; taken {
+		name = domainName(t.PkgPath()) + name
+	}
+	g.names[t] = name
+	// Reserve the name before recursing so self-referencing types terminate.
+	g.schemas[name] = &Schema{}
+	*g.schemas[name] = *g.object(t)
+	return name
+}
+
+func (g *generator) object(t reflect.Type) *Schema {
+	object := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, omitEmpty := jsonName(field)
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && field.Tag.Get("json") == "" && field.Type.Kind() == reflect.Struct {
+			embedded := g.object(field.Type)
+			for key, value := range embedded.Properties {
+				object.Properties[key] = value
+			}
+			object.Required = append(object.Required, embedded.Required...)
+			continue
+		}
+
+		property := g.schema(field.Type)
+		if applyValidation(property, field.Tag.Get("validate")) && !omitEmpty {
+			object.Required = append(object.Required, name)
+		}
+		object.Properties[name] = property
+	}
+	return object
+}
+
+func jsonName(field reflect.StructField) (name string, omitEmpty bool) {
+	tag := field.Tag.Get("json")
+	if tag == "" {
+		return field.Name, false
+	}
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	if name == "" {
+		name = field.Name
+	}
+	for _, option := range parts[1:] {
+		if option == "omitempty" {
+			omitEmpty = true
+		}
+	}
+	return name, omitEmpty
+}
+
+// applyValidation maps validator tags onto schema keywords and reports
+// whether the field is required. References are left alone since the
+// keywords would have to live on the shared component.
+func applyValidation(property *Schema, tag string) (required bool) {
+	if tag == "" {
+		return false
+	}
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "dive":
+			// Rules after dive apply to elements, which we do not describe.
+			return required
+		case "required":
+			required = true
+		case "email":
+			property.Format = "email"
+		case "url":
+			property.Format = "uri"
+		case "uuid", "uuid4":
+			property.Format = "uuid"
+		case "oneof":
+			property.Enum = strings.Fields(param)
+		case "startswith":
+			property.Pattern = "^" + regexp.QuoteMeta(param)
+		case "min", "max", "len":
+			limit, err := strconv.Atoi(param)
+			if err != nil {
+				continue
+			}
+			applyLimit(property, name, limit)
+		}
+	}
+	return required
+}
+
+func applyLimit(property *Schema, rule string, limit int) {
+	lower, upper := rule == "min" || rule == "len", rule == "max" || rule == "len"
+	switch property.Type {
+	case "string":
+		if lower {
+			property.MinLength = &limit
+		}
+		if upper {
+			property.MaxLength = &limit
+		}
+	case "array":
+		if lower {
+			property.MinItems = &limit
+		}
+		if upper {
+			property.MaxItems = &limit
+		}
+	case "integer", "number":
+		value := float64(limit)
+		if lower {
+			property.Minimum = &value
+		}
+		if upper {
+			property.Maximum = &value
+		}
+	}
+}
+
+// domainName turns ".../src/user/models" into "User" to tell apart models
+// that share a type name.
+func domainName(pkgPath string) string {
+	segments := strings.Split(pkgPath, "/")
+	name := segments[len(segments)-1]
+	if name == "models" && len(segments) > 1 {
+		name = segments[len(segments)-2]
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
@@ -0,0 +1,257 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// ValidationError describes one value that does not match the document. In is
+// path, query, header or body; Name locates the value, e.g. operations[0].op.
+type ValidationError struct {
+	In    string `json:"in"`
+	Name  string `json:"name"`
+	Error string `json:"error"`
+}
+
+var patterns sync.Map // pattern -> *regexp.Regexp
+
+// schemaValidator checks decoded JSON values (json.Number for numbers)
+// against schemas, resolving references through the document's components.
+type schemaValidator struct {
+	schemas map[string]*Schema
+	// strict rejects object properties the schema does not declare. Requests
+	// are strict; responses are not, since list services return whole stored
+	// documents.
+	strict bool
+	errors []ValidationError
+}
+
+func (v *schemaValidator) fail(in string, name string, format string, args ...any) {
+	v.errors = append(v.errors, ValidationError{In: in, Name: name, Error: fmt.Sprintf(format, args...)})
+}
+
+func (v *schemaValidator) resolve(schema *Schema) *Schema {
+	for schema != nil && schema.Ref != "" {
+		schema = v.schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+	}
+	return schema
+}
+
+func (v *schemaValidator) value(in string, name string, schema *Schema, value any) {
+	schema = v.resolve(schema)
+	if schema == nil || value == nil {
+		return
+	}
+
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			v.fail(in, name, "must be an object")
+			return
+		}
+		v.object(in, name, schema, object)
+	case "array":
+		array, ok := value.([]any)
+		if !ok {
+			v.fail(in, name, "must be an array")
+			return
+		}
+		if schema.MinItems != nil && len(array) < *schema.MinItems {
+			v.fail(in, name, "must have at least %d items", *schema.MinItems)
+		}
+		if schema.MaxItems != nil && len(array) > *schema.MaxItems {
+			v.fail(in, name, "must have at most %d items", *schema.MaxItems)
+		}
+		for i, item := range array {
+			v.value(in, fmt.Sprintf("%s[%d]", name, i), schema.Items, item)
+		}
+	case "string":
+		text, ok := value.(string)
+		if !ok {
+			v.fail(in, name, "must be a string")
+			return
+		}
+		v.string(in, name, schema, text)
+	case "integer", "number":
+		number, ok := value.(json.Number)
+		if !ok {
+			v.fail(in, name, "must be a %s", schema.Type)
+			return
+		}
+		v.number(in, name, schema, number)
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			v.fail(in, name, "must be a boolean")
+		}
+	}
+}
+
+func (v *schemaValidator) object(in string, name string, schema *Schema, object map[string]any) {
+	for _, required := range schema.Required {
+		if lookupValue(object, required) == nil {
+			v.fail(in, join(name, required), "is required")
+		}
+	}
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := object[key]
+		property, declared := lookupProperty(schema.Properties, key)
+		switch {
+		case declared:
+			v.value(in, join(name, key), property, value)
+		case schema.AdditionalProperties != nil:
+			v.value(in, join(name, key), schema.AdditionalProperties, value)
+		case v.strict && schema.Properties != nil:
+			v.fail(in, join(name, key), "is not a known field")
+		}
+	}
+}
+
+func (v *schemaValidator) string(in string, name string, schema *Schema, text string) {
+	length := utf8.RuneCountInString(text)
+	if schema.MinLength != nil && length < *schema.MinLength {
+		v.fail(in, name, "must be at least %d characters", *schema.MinLength)
+	}
+	if schema.MaxLength != nil && length > *schema.MaxLength {
+		v.fail(in, name, "must be at most %d characters", *schema.MaxLength)
+	}
+	if len(schema.Enum) > 0 && !contains(schema.Enum, text) {
+		v.fail(in, name, "must be one of %s", strings.Join(schema.Enum, ", "))
+	}
+	if schema.Pattern != "" && !matches(schema.Pattern, text) {
+		v.fail(in, name, "must match %s", schema.Pattern)
+	}
+	if err := checkFormat(schema.Format, text); err != "" {
+		v.fail(in, name, "%s", err)
+	}
+}
+
+func (v *schemaValidator) number(in string, name string, schema *Schema, number json.Number) {
+	if schema.Type == "integer" {
+		if _, err := strconv.ParseInt(number.String(), 10, 64); err != nil {
+			v.fail(in, name, "must be an integer")
+			return
+		}
+	}
+	value, err := number.Float64()
+	if err != nil {
+		v.fail(in, name, "must be a number")
+		return
+	}
+	if schema.Minimum != nil && value < *schema.Minimum {
+		v.fail(in, name, "must be at least %v", *schema.Minimum)
+	}
+	if schema.Maximum != nil && value > *schema.Maximum {
+		v.fail(in, name, "must be at most %v", *schema.Maximum)
+	}
+}
+
+// parameter checks a path, query or header value, which always arrives as
+// text, against a scalar schema.
+func (v *schemaValidator) parameter(in string, name string, schema *Schema, text string) {
+	switch schema.Type {
+	case "integer", "number":
+		v.number(in, name, schema, json.Number(text))
+	case "boolean":
+		if _, err := strconv.ParseBool(text); err != nil {
+			v.fail(in, name, "must be a boolean")
+		}
+	default:
+		v.string(in, name, schema, text)
+	}
+}
+
+// lookupProperty matches a key the way encoding/json does when binding, which
+// prefers an exact match but falls back to a case-insensitive one.
+func lookupProperty(properties map[string]*Schema, key string) (*Schema, bool) {
+	if property, ok := properties[key]; ok {
+		return property, true
+	}
+	for name, property := range properties {
+		if strings.EqualFold(name, key) {
+			return property, true
+		}
+	}
+	return nil, false
+}
+
+func lookupValue(object map[string]any, name string) any {
+	if value, ok := object[name]; ok {
+		return value
+	}
+	for key, value := range object {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	return nil
+}
+
+func checkFormat(format string, text string) string {
+	switch format {
+	case "email":
+		if address, err := mail.ParseAddress(text); err != nil || address.Address != text {
+			return "must be an email address"
+		}
+	case "uri":
+		if parsed, err := url.ParseRequestURI(text); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return "must be an absolute URL"
+		}
+	case "uuid":
+		if _, err := uuid.Parse(text); err != nil {
+			return "must be a UUID"
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, text); err != nil {
+			return "must be an RFC 3339 date-time"
+		}
+	}
+	return ""
+}
+
+func matches(pattern string, text string) bool {
+	compiled, ok := patterns.Load(pattern)
+	if !ok {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return true
+		}
+		compiled, _ = patterns.LoadOrStore(pattern, re)
+	}
+	return compiled.(*regexp.Regexp).MatchString(text)
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+func join(prefix string, name string) string {
+	if prefix == "" {
+		return name
+	}
+	if name == "" {
+		return prefix
+	}
+	return prefix + "." + name
+}
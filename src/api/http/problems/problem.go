@@ -0,0 +1,108 @@
+package problems
+
+import (
+	"errors"
+	"net/http"
+	"somdeep-demo-app/src/apperrors"
+	"somdeep-demo-app/src/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+const ContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details document. Errors lists the fields a
+// validation failure rejected; Data carries the partial result some services
+// return alongside an error, such as a rejected batch.
+type Problem struct {
+	Type       string                 `json:"type"`
+	Title      string                 `json:"title"`
+	Status     int                    `json:"status"`
+	Detail     string                 `json:"detail,omitempty"`
+	Instance   string                 `json:"instance,omitempty"`
+	Request_id string                 `json:"request_id,omitempty"`
+	Errors     []apperrors.FieldError `json:"errors,omitempty"`
+	Data       any                    `json:"data,omitempty"`
+
+	// cause is the error behind a server error. It is logged, never sent.
+	cause error
+}
+
+var titles = map[string]string{
+	"not-found":    "Resource not found",
+	"conflict":     "Conflict with the current state",
+	"validation":   "Validation failed",
+	"unauthorized": "Authentication required",
+}
+
+// New describes a failed call. A typed error decides the status and detail;
+// for any other error the service's status and message are kept, and a
+// status below 400 becomes 500. The error text only reaches the client for
+// client errors: for server errors, which may carry driver internals, the
+// detail is the service's message alone and Write logs the error instead.
+func New(status int, message string, err error) *Problem {
+	problem := &Problem{Type: "about:blank", Status: status, Detail: message}
+
+	var typed apperrors.Error
+	if errors.As(err, &typed) {
+		problem.Type = "/problems/" + typed.Kind()
+		problem.Title = titles[typed.Kind()]
+		problem.Status = typed.Status()
+		problem.Detail = typed.Error()
+		var validation *apperrors.ValidationError
+		if errors.As(err, &validation) {
+			problem.Detail = validation.Message
+			problem.Errors = validation.Fields
+		}
+		return problem
+	}
+
+	if problem.Status < http.StatusBadRequest {
+		problem.Status = http.StatusInternalServerError
+	}
+	problem.Title = http.StatusText(problem.Status)
+	if problem.Status >= http.StatusInternalServerError {
+		problem.cause = err
+		if problem.Detail == "" {
+			problem.Detail = "The server could not complete the request"
+		}
+		return problem
+	}
+	if err != nil && err.Error() != message {
+		if problem.Detail == "" {
+			problem.Detail = err.Error()
+		} else {
+			problem.Detail += ": " + err.Error()
+		}
+	}
+	return problem
+}
+
+// WithData attaches a partial result, ignoring nil.
+func (p *Problem) WithData(data any) *Problem {
+	p.Data = data
+	return p
+}
+
+// Write renders the problem and aborts the rest of the handler chain. The
+// cause of a server error is attached to the request, so the request's log
+// line carries it.
+func Write(c *gin.Context, problem *Problem) {
+	if problem.cause != nil {
+		c.Error(problem.cause)
+	}
+	if problem.Instance == "" {
+		problem.Instance = c.Request.URL.Path
+	}
+	if problem.Request_id == "" {
+		problem.Request_id = logging.RequestId(c.Request.Context())
+	}
+	c.Header("Content-Type", ContentType)
+	c.AbortWithStatusJSON(problem.Status, problem)
+}
+
+// RouteNotFound answers a request no route handles, including the actions
+// the custom-method routes do not know.
+func RouteNotFound(c *gin.Context) {
+	Write(c, New(http.StatusNotFound, "Route not found", apperrors.NotFound("Route not found")))
+}
@@ -0,0 +1,82 @@
+package problems
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"somdeep-demo-app/src/apperrors"
+)
+
+func TestNew(t *testing.T) {
+	field := apperrors.FieldError{Field: "email", Rule: "email", Message: "must be a valid email address"}
+
+	tests := []struct {
+		name    string
+		status  int
+		message string
+		err     error
+		want    Problem
+	}{
+		{
+			name: "not found", status: http.StatusInternalServerError, message: "ignored",
+			err:  apperrors.NotFound("User not found"),
+			want: Problem{Type: "/problems/not-found", Title: "Resource not found", Status: http.StatusNotFound, Detail: "User not found"},
+		},
+		{
+			name: "conflict", status: http.StatusBadRequest, message: "ignored",
+			err:  apperrors.Conflict("A record with the same key already exists"),
+			want: Problem{Type: "/problems/conflict", Title: "Conflict with the current state", Status: http.StatusConflict, Detail: "A record with the same key already exists"},
+		},
+		{
+			name: "validation", status: http.StatusBadRequest, message: "ignored",
+			err:  apperrors.Validation("Validation Error", field),
+			want: Problem{Type: "/problems/validation", Title: "Validation failed", Status: http.StatusBadRequest, Detail: "Validation Error", Errors: []apperrors.FieldError{field}},
+		},
+		{
+			name: "unauthorized", status: http.StatusOK, message: "ignored",
+			err:  apperrors.Unauthorized("Missing bearer token"),
+			want: Problem{Type: "/problems/unauthorized", Title: "Authentication required", Status: http.StatusUnauthorized, Detail: "Missing bearer token"},
+		},
+		{
+			name: "client error keeps the error text", status: http.StatusBadRequest, message: "Error occured while binding JSON",
+			err:  errors.New("unexpected EOF"),
+			want: Problem{Type: "about:blank", Title: "Bad Request", Status: http.StatusBadRequest, Detail: "Error occured while binding JSON: unexpected EOF"},
+		},
+		{
+			name: "server error hides the error text", status: http.StatusInternalServerError, message: "error occured while listing users",
+			err:  errors.New("(BadValue) unknown operator: $foo"),
+			want: Problem{Type: "about:blank", Title: "Internal Server Error", Status: http.StatusInternalServerError, Detail: "error occured while listing users"},
+		},
+		{
+			name: "server error without a message", status: http.StatusServiceUnavailable,
+			err:  errors.New("server selection error: context deadline exceeded"),
+			want: Problem{Type: "about:blank", Title: "Service Unavailable", Status: http.StatusServiceUnavailable, Detail: "The server could not complete the request"},
+		},
+		{
+			name: "success status becomes a server error", status: http.StatusOK, message: "Something went wrong",
+			err:  errors.New("boom"),
+			want: Problem{Type: "about:blank", Title: "Internal Server Error", Status: http.StatusInternalServerError, Detail: "Something went wrong"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := *New(test.status, test.message, test.err)
+			got.cause = nil
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("New() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestNewKeepsTheCauseOfServerErrors(t *testing.T) {
+	err := errors.New("connection reset by peer")
+	if problem := New(http.StatusInternalServerError, "failed", err); problem.cause != err {
+		t.Errorf("cause = %v, want %v", problem.cause, err)
+	}
+	if problem := New(http.StatusBadRequest, "failed", err); problem.cause != nil {
+		t.Errorf("cause = %v, want none for a client error", problem.cause)
+	}
+}
@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"somdeep-demo-app/src/api/http/controllers"
+	"somdeep-demo-app/src/api/http/openapi"
+	"somdeep-demo-app/src/audit/interfaces"
+	"somdeep-demo-app/src/audit/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func AuditRoutes(incomingRoutes *gin.Engine, auditService interfaces.AuditService) {
+	auditController := controllers.NewAuditController(auditService)
+	incomingRoutes.GET("/audit", auditController.GetAuditEntriesHandler())
+	incomingRoutes.GET("/users/:user_id/customers/:customer_id/history", auditController.GetCustomerHistoryHandler())
+}
+
+// auditOperations documents the routes AuditRoutes registers.
+var auditOperations = []openapi.Operation{
+	{Method: "GET", Path: "/audit", Id: "listAuditEntries", Tag: "audit", Summary: "List audit entries",
+		Query: append([]openapi.Parameter{
+			{Name: "entity", Type: "string", Enum: []string{models.EntityUser, models.EntityCustomer}},
+			{Name: "id", Type: "string", Description: "Entity id"},
+			{Name: "actor", Type: "string"},
+		}, pagingQuery...), Response: []models.AuditEntry{}},
+	{Method: "GET", Path: "/users/:user_id/customers/:customer_id/history", Id: "getCustomerHistory", Tag: "audit", Summary: "List a customer's changes", Query: pagingQuery, Response: []models.AuditEntry{}},
+}
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"somdeep-demo-app/src/api/http/controllers"
+	"somdeep-demo-app/src/api/http/openapi"
+	"somdeep-demo-app/src/cache/interfaces"
+	"somdeep-demo-app/src/cache/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func CacheRoutes(incomingRoutes *gin.Engine, cacheService interfaces.CacheService) {
+	cacheController := controllers.NewCacheController(cacheService)
+	incomingRoutes.GET("/admin/cache", cacheController.GetCacheStatsHandler())
+	incomingRoutes.DELETE("/admin/cache", cacheController.ClearCacheHandler())
+}
+
+// cacheOperations documents the routes CacheRoutes registers.
+var cacheOperations = []openapi.Operation{
+	{Method: "GET", Path: "/admin/cache", Id: "getCacheStats", Tag: "admin", Summary: "Cache hit ratios and size", Response: models.CacheReport{}},
+	{Method: "DELETE", Path: "/admin/cache", Id: "clearCache", Tag: "admin", Summary: "Drop every cached entry"},
+}
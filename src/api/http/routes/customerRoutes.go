@@ -1,20 +1,51 @@
 package routes
 
 import (
+	"net/http"
 	"somdeep-demo-app/src/api/http/controllers"
+	"somdeep-demo-app/src/api/http/openapi"
 	"somdeep-demo-app/src/customer/interfaces"
+	"somdeep-demo-app/src/customer/models"
 
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 func CustomerRoutes(incomingRoutes *gin.Engine, customerService interfaces.CustomerService) {
 	// Create controller instances with the userService dependency
 	customerController := controllers.NewCustomerController(customerService)
 	incomingRoutes.GET("/customers", customerController.GetCustomersHandler())
+	incomingRoutes.GET("/customers/export", customerController.ExportCustomersHandler())
 	incomingRoutes.GET("/users/:user_id/customers", customerController.GetCustomersByUserIdHandler())
+	incomingRoutes.GET("/users/:user_id/customers/stream", customerController.StreamCustomerChangesHandler())
 	incomingRoutes.GET("/users/:user_id/customers/:customer_id", customerController.GetCustomerByCustomerIdHandler())
 	incomingRoutes.POST("/users/:user_id/customers", customerController.AddCustomerByUserIdHandler())
 	incomingRoutes.PATCH("/users/:user_id/customers/:customer_id", customerController.UpdateCustomerByCustomerIdHandler())
 	incomingRoutes.DELETE("/users/:user_id/customers/:customer_id", customerController.DeleteCustomerByCustomerIdHandler())
 	incomingRoutes.DELETE("/users/:user_id/customers", customerController.DeleteCustomersByUserId())
+	incomingRoutes.POST("/users/:user_id/:action", customerController.UserActionHandler())
+}
+
+// customerOperations documents the routes CustomerRoutes registers.
+var customerOperations = []openapi.Operation{
+	{Method: "GET", Path: "/customers", Id: "listCustomers", Tag: "customers", Summary: "List customers of all users", Query: pagingQuery, Response: []openapi.Page[models.Customer]{}},
+	{Method: "GET", Path: "/customers/export", Id: "exportCustomers", Tag: "customers", Summary: "Export customers as CSV, NDJSON or XLSX",
+		Query: append([]openapi.Parameter{{Name: "user_id", Type: "string", Description: "Only export this user's customers"}}, exportQuery...), ResponseTypes: exportTypes},
+	{Method: "GET", Path: "/users/:user_id/customers", Id: "listUserCustomers", Tag: "customers", Summary: "List a user's customers", Query: pagingQuery, Response: []openapi.Page[models.Customer]{}},
+	{Method: "GET", Path: "/users/:user_id/customers/stream", Id: "streamUserCustomers", Tag: "customers", Summary: "Stream changes to a user's customers as server-sent events",
+		Description: "Resumes after the Last-Event-ID header or lastEventId query parameter; a reset event means the client must refetch.",
+		Query:       []openapi.Parameter{{Name: "lastEventId", Type: "string", Description: "Resume after this event id"}}, ResponseTypes: []string{"text/event-stream"}},
+	{Method: "GET", Path: "/users/:user_id/customers/:customer_id", Id: "getCustomer", Tag: "customers", Summary: "Get a customer", Response: models.Customer{}},
+	{Method: "POST", Path: "/users/:user_id/customers", Id: "createCustomer", Tag: "customers", Summary: "Create a customer for a user", Request: models.Customer{}, Response: models.CustomerRef{}},
+	{Method: "PATCH", Path: "/users/:user_id/customers/:customer_id", Id: "updateCustomer", Tag: "customers", Summary: "Update a customer's name", Request: openapi.Partial{Of: models.Customer{}}, Response: mongo.UpdateResult{}},
+	{Method: "DELETE", Path: "/users/:user_id/customers/:customer_id", Id: "deleteCustomer", Tag: "customers", Summary: "Delete a customer", Response: models.CustomerRef{}},
+	{Method: "DELETE", Path: "/users/:user_id/customers", Id: "deleteUserCustomers", Tag: "customers", Summary: "Delete all of a user's customers", Response: models.CustomerRef{}},
+	{Method: "POST", Path: "/users/:user_id/:action", SpecPath: "/users/:user_id/customers:batch", Id: "batchCustomers", Tag: "customers", Summary: "Create, update and delete customers in one request",
+		Request: models.CustomerBatchRequest{}, Response: models.CustomerBatchResult{}, Status: http.StatusMultiStatus},
+	{Method: "POST", Path: "/users/:user_id/:action", SpecPath: "/users/:user_id/customers:import", Id: "importCustomers", Tag: "customers", Summary: "Import customers from CSV or NDJSON",
+		Query: []openapi.Parameter{
+			{Name: "format", Type: "string", Enum: []string{models.ImportFormatCSV, models.ImportFormatNDJSON}, Description: "Defaults to the file extension, then the content type"},
+			{Name: "dry_run", Type: "boolean", Description: "Validate without inserting"},
+		},
+		RequestTypes: []string{"text/csv", "application/x-ndjson", "application/ndjson", "application/jsonl", "multipart/form-data"}, Response: models.CustomerImportReport{}, Status: http.StatusCreated},
 }
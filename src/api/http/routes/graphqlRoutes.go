@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"somdeep-demo-app/src/api/graphql/servers"
+	"somdeep-demo-app/src/api/http/controllers"
+	"somdeep-demo-app/src/api/http/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GraphQLRoutes(incomingRoutes *gin.Engine, graphqlServer *servers.Server) {
+	graphqlController := controllers.NewGraphQLController(graphqlServer)
+	incomingRoutes.POST("/graphql", graphqlController.GraphQLHandler())
+}
+
+// graphqlOperations documents the routes GraphQLRoutes registers.
+var graphqlOperations = []openapi.Operation{
+	{Method: "POST", Path: "/graphql", Id: "graphql", Tag: "graphql", Summary: "Run a GraphQL query or mutation",
+		Description: "Responds with a standard GraphQL result; introspect the schema for the available types.",
+		Request:     servers.Request{}, ResponseTypes: []string{"application/json"}},
+}
@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"somdeep-demo-app/src/api/http/controllers"
+	"somdeep-demo-app/src/api/http/openapi"
+	"somdeep-demo-app/src/health/interfaces"
+	"somdeep-demo-app/src/health/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func HealthRoutes(incomingRoutes *gin.Engine, healthService interfaces.HealthService) {
+	healthController := controllers.NewHealthController(healthService)
+	incomingRoutes.GET("/healthz", healthController.LivenessHandler())
+	incomingRoutes.GET("/readyz", healthController.ReadinessHandler())
+}
+
+// healthOperations documents the routes HealthRoutes registers.
+var healthOperations = []openapi.Operation{
+	{Method: "GET", Path: "/healthz", Id: "getLiveness", Tag: "monitoring", Summary: "Liveness probe",
+		Description: "Succeeds while the process serves requests, whatever the state of its dependencies.",
+		Response:    models.HealthReport{}},
+	{Method: "GET", Path: "/readyz", Id: "getReadiness", Tag: "monitoring", Summary: "Readiness probe",
+		Description: "Checks MongoDB and every registered dependency, reporting each check's latency. Answers 503 with the report when a check fails or the service is shutting down.",
+		Response:    models.HealthReport{}},
+}
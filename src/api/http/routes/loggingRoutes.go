@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"somdeep-demo-app/src/api/http/controllers"
+	"somdeep-demo-app/src/api/http/openapi"
+	"somdeep-demo-app/src/logging/interfaces"
+	"somdeep-demo-app/src/logging/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func LoggingRoutes(incomingRoutes *gin.Engine, loggingService interfaces.LoggingService) {
+	loggingController := controllers.NewLoggingController(loggingService)
+	incomingRoutes.GET("/admin/log-level", loggingController.GetLogLevelHandler())
+	incomingRoutes.PUT("/admin/log-level", loggingController.SetLogLevelHandler())
+}
+
+// loggingOperations documents the routes LoggingRoutes registers.
+var loggingOperations = []openapi.Operation{
+	{Method: "GET", Path: "/admin/log-level", Id: "getLogLevel", Tag: "admin", Summary: "Current log level", Response: models.LogLevel{}},
+	{Method: "PUT", Path: "/admin/log-level", Id: "setLogLevel", Tag: "admin", Summary: "Change the log level without a restart", Request: models.LogLevel{}, Response: models.LogLevel{}},
+}
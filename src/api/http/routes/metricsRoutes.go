@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"somdeep-demo-app/src/api/http/openapi"
+	"somdeep-demo-app/src/metrics"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+func MetricsRoutes(incomingRoutes *gin.Engine) {
+	incomingRoutes.GET("/metrics", gin.WrapH(promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{})))
+}
+
+// metricsOperations documents the routes MetricsRoutes registers.
+var metricsOperations = []openapi.Operation{
+	{Method: "GET", Path: "/metrics", Id: "getMetrics", Tag: "monitoring", Summary: "Prometheus metrics",
+		Description:   "HTTP, service, MongoDB and business metrics in the Prometheus text format.",
+		ResponseTypes: []string{"text/plain"}},
+}
@@ -0,0 +1,76 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"somdeep-demo-app/src/api/http/controllers"
+	"somdeep-demo-app/src/api/http/openapi"
+	"somdeep-demo-app/src/export"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swaggest/swgui/v5emb"
+)
+
+var (
+	one              = 1.0
+	maxRecordPerPage = float64(controllers.MaxRecordPerPage)
+)
+
+var pagingQuery = []openapi.Parameter{
+	{Name: "page", Type: "integer", Minimum: &one, Description: "1-based page number, defaults to 1"},
+	{Name: "recordPerPage", Type: "integer", Minimum: &one, Maximum: &maxRecordPerPage,
+		Description: fmt.Sprintf("Page size, defaults to %d and at most %d", controllers.DefaultRecordPerPage, controllers.MaxRecordPerPage)},
+}
+
+var exportQuery = []openapi.Parameter{
+	{Name: "format", Type: "string", Enum: []string{export.FormatCSV, export.FormatNDJSON, export.FormatXLSX}, Description: "Defaults to csv"},
+	{Name: "fields", Type: "string", Description: "Comma separated columns to include"},
+	{Name: "page", Type: "integer", Minimum: &one, Description: "Export a single page instead of everything"},
+	{Name: "recordPerPage", Type: "integer", Minimum: &one},
+}
+
+var exportTypes = []string{
+	"text/csv",
+	"application/x-ndjson",
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// Operations documents every route the *Routes functions register.
+func Operations() []openapi.Operation {
+	var operations []openapi.Operation
+	for _, group := range [][]openapi.Operation{
+		userOperations,
+		customerOperations,
+		auditOperations,
+		outboxOperations,
+		webhookOperations,
+		cacheOperations,
+		tenantOperations,
+		loggingOperations,
+		metricsOperations,
+		healthOperations,
+		graphqlOperations,
+	} {
+		operations = append(operations, group...)
+	}
+	return operations
+}
+
+// OpenAPIRoutes serves the document describing the routes registered so far
+// at /openapi.json and Swagger UI at /docs, so it must be registered last. It
+// fails when a route is missing from Operations.
+func OpenAPIRoutes(incomingRoutes *gin.Engine) (*openapi.Document, error) {
+	document, err := openapi.Build(openapi.Info{
+		Title:   "somdeep-demo-app",
+		Version: "1.0.0",
+	}, incomingRoutes.Routes(), Operations())
+	if err != nil {
+		return nil, err
+	}
+
+	incomingRoutes.GET("/openapi.json", func(c *gin.Context) {
+		c.JSON(http.StatusOK, document)
+	})
+	incomingRoutes.GET("/docs/*any", gin.WrapH(v5emb.New("somdeep-demo-app", "/openapi.json", "/docs/")))
+	return document, nil
+}
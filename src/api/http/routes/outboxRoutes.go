@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"somdeep-demo-app/src/api/http/controllers"
+	"somdeep-demo-app/src/api/http/openapi"
+	"somdeep-demo-app/src/outbox/interfaces"
+	"somdeep-demo-app/src/outbox/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func OutboxRoutes(incomingRoutes *gin.Engine, outboxService interfaces.OutboxService) {
+	outboxController := controllers.NewOutboxController(outboxService)
+	incomingRoutes.GET("/admin/outbox", outboxController.GetOutboxStatsHandler())
+	incomingRoutes.POST("/admin/outbox/dead/:event_id/requeue", outboxController.RequeueDeadMessageHandler())
+}
+
+// outboxOperations documents the routes OutboxRoutes registers.
+var outboxOperations = []openapi.Operation{
+	{Method: "GET", Path: "/admin/outbox", Id: "getOutboxStats", Tag: "admin", Summary: "Outbox backlog and delivery counters", Response: models.OutboxStats{}},
+	{Method: "POST", Path: "/admin/outbox/dead/:event_id/requeue", Id: "requeueDeadEvent", Tag: "admin", Summary: "Requeue an event that exhausted its retries", Response: models.EventRef{}},
+}
@@ -0,0 +1,95 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"somdeep-demo-app/src/api/http/problems"
+	"somdeep-demo-app/src/apperrors"
+	auditModels "somdeep-demo-app/src/audit/models"
+	"somdeep-demo-app/src/database"
+	"somdeep-demo-app/src/user/interfaces"
+	"somdeep-demo-app/src/user/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// failingUserService fails every lookup with err, classified the way the
+// real service does, and validates new users like the real service.
+type failingUserService struct {
+	interfaces.UserService
+	err error
+}
+
+func (s failingUserService) GetUser(ctx context.Context, userId string) (interfaces.Response, error) {
+	res := interfaces.Response{Error: s.err.Error(), Message: "User not found"}
+	res.Status, s.err = database.ClassifyError(s.err, res.Message)
+	return res, s.err
+}
+
+func (s failingUserService) AddUser(ctx context.Context, actor auditModels.Actor, user models.User) (interfaces.Response, error) {
+	if err := validator.New().Struct(user); err != nil {
+		return interfaces.Response{Status: http.StatusBadRequest, Message: "Validation Error"}, apperrors.FromValidator("Validation Error", err, user)
+	}
+	return interfaces.Response{Status: http.StatusCreated}, nil
+}
+
+func TestUserRoutesWriteProblems(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	duplicate := mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 11000, Message: "E11000 duplicate key error"}}}
+
+	tests := []struct {
+		name   string
+		err    error
+		method string
+		body   string
+		status int
+		typ    string
+	}{
+		{name: "not found", err: mongo.ErrNoDocuments, method: "GET", status: http.StatusNotFound, typ: "/problems/not-found"},
+		{name: "duplicate key", err: duplicate, method: "GET", status: http.StatusConflict, typ: "/problems/conflict"},
+		{name: "timeout", err: context.DeadlineExceeded, method: "GET", status: http.StatusServiceUnavailable, typ: "about:blank"},
+		{name: "server error", err: errors.New("(BadValue) unknown operator: $foo"), method: "GET", status: http.StatusInternalServerError, typ: "about:blank"},
+		{name: "validation", method: "POST", body: `{"first_name":"A","email":"nope"}`, status: http.StatusBadRequest, typ: "/problems/validation"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			router := gin.New()
+			UserRoutes(router, failingUserService{err: test.err})
+
+			path := "/users/u-1"
+			if test.method == "POST" {
+				path = "/users"
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(test.method, path, strings.NewReader(test.body)))
+
+			if recorder.Code != test.status {
+				t.Errorf("status = %d, want %d", recorder.Code, test.status)
+			}
+			if contentType := recorder.Header().Get("Content-Type"); !strings.HasPrefix(contentType, problems.ContentType) {
+				t.Errorf("Content-Type = %q, want %q", contentType, problems.ContentType)
+			}
+			var problem problems.Problem
+			if err := json.Unmarshal(recorder.Body.Bytes(), &problem); err != nil {
+				t.Fatalf("decoding the problem: %v", err)
+			}
+			if problem.Type != test.typ || problem.Status != test.status || problem.Instance != path {
+				t.Errorf("problem = %+v, want type %q, status %d, instance %q", problem, test.typ, test.status, path)
+			}
+			if test.err != nil && test.status >= http.StatusInternalServerError && strings.Contains(recorder.Body.String(), test.err.Error()) {
+				t.Errorf("body %s leaks the error %q", recorder.Body, test.err)
+			}
+			if test.typ == "/problems/validation" && len(problem.Errors) == 0 {
+				t.Errorf("problem lists no field errors")
+			}
+		})
+	}
+}
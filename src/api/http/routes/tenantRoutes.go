@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"net/http"
+	"somdeep-demo-app/src/api/http/controllers"
+	"somdeep-demo-app/src/api/http/openapi"
+	"somdeep-demo-app/src/tenant/interfaces"
+	"somdeep-demo-app/src/tenant/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TenantRoutes(incomingRoutes *gin.Engine, tenantService interfaces.TenantService) {
+	tenantController := controllers.NewTenantController(tenantService)
+	incomingRoutes.GET("/admin/tenants", tenantController.GetTenantsHandler())
+	incomingRoutes.POST("/admin/tenants", tenantController.ProvisionTenantHandler())
+	incomingRoutes.GET("/admin/tenants/:tenant_id", tenantController.GetTenantHandler())
+	incomingRoutes.DELETE("/admin/tenants/:tenant_id", tenantController.DeprovisionTenantHandler())
+}
+
+// tenantOperations documents the routes TenantRoutes registers.
+var tenantOperations = []openapi.Operation{
+	{Method: "GET", Path: "/admin/tenants", Id: "listTenants", Tag: "admin", Summary: "List the provisioned tenants", Response: []models.Tenant{}},
+	{Method: "POST", Path: "/admin/tenants", Id: "provisionTenant", Tag: "admin", Summary: "Provision a tenant",
+		Description: "Creates the tenant's collections and indexes, then accepts requests for it.", Request: models.Tenant{}, Response: models.Tenant{}, Status: http.StatusCreated},
+	{Method: "GET", Path: "/admin/tenants/:tenant_id", Id: "getTenant", Tag: "admin", Summary: "Get a tenant", Response: models.Tenant{}},
+	{Method: "DELETE", Path: "/admin/tenants/:tenant_id", Id: "deprovisionTenant", Tag: "admin", Summary: "Deprovision a tenant",
+		Description: "Stops accepting requests for the tenant and deletes all of its data.", Response: models.Tenant{}},
+}
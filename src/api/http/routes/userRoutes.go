@@ -2,9 +2,12 @@ package routes
 
 import (
 	"somdeep-demo-app/src/api/http/controllers"
+	"somdeep-demo-app/src/api/http/openapi"
 	"somdeep-demo-app/src/user/interfaces"
+	"somdeep-demo-app/src/user/models"
 
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 func UserRoutes(incomingRoutes *gin.Engine, userService interfaces.UserService) {
@@ -12,8 +15,19 @@ func UserRoutes(incomingRoutes *gin.Engine, userService interfaces.UserService)
 	userController := controllers.NewUserController(userService)
 
 	incomingRoutes.GET("/users", userController.GetUsersHandler())
+	incomingRoutes.GET("/users/export", userController.ExportUsersHandler())
 	incomingRoutes.GET("/users/:user_id", userController.GetUserHandler())
 	incomingRoutes.POST("/users", userController.AddUserHandler())
 	incomingRoutes.PATCH("/users/:user_id", userController.UpdateUserHandler())
 	incomingRoutes.DELETE("/users/:user_id", userController.DeleteUserHandler())
 }
+
+// userOperations documents the routes UserRoutes registers.
+var userOperations = []openapi.Operation{
+	{Method: "GET", Path: "/users", Id: "listUsers", Tag: "users", Summary: "List users", Query: pagingQuery, Response: []openapi.Page[models.User]{}},
+	{Method: "GET", Path: "/users/export", Id: "exportUsers", Tag: "users", Summary: "Export users as CSV, NDJSON or XLSX", Query: exportQuery, ResponseTypes: exportTypes},
+	{Method: "GET", Path: "/users/:user_id", Id: "getUser", Tag: "users", Summary: "Get a user", Response: models.User{}},
+	{Method: "POST", Path: "/users", Id: "createUser", Tag: "users", Summary: "Create a user", Request: models.User{}, Response: models.UserRef{}},
+	{Method: "PATCH", Path: "/users/:user_id", Id: "updateUser", Tag: "users", Summary: "Update a user's name", Request: openapi.Partial{Of: models.User{}}, Response: mongo.UpdateResult{}},
+	{Method: "DELETE", Path: "/users/:user_id", Id: "deleteUser", Tag: "users", Summary: "Delete a user", Response: models.UserRef{}},
+}
@@ -0,0 +1,40 @@
+package routes
+
+import (
+	"net/http"
+	"somdeep-demo-app/src/api/http/controllers"
+	"somdeep-demo-app/src/api/http/openapi"
+	"somdeep-demo-app/src/webhook/interfaces"
+	"somdeep-demo-app/src/webhook/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func WebhookRoutes(incomingRoutes *gin.Engine, webhookService interfaces.WebhookService) {
+	webhookController := controllers.NewWebhookController(webhookService)
+	incomingRoutes.GET("/users/:user_id/webhooks", webhookController.GetWebhooksHandler())
+	incomingRoutes.POST("/users/:user_id/webhooks", webhookController.AddWebhookHandler())
+	incomingRoutes.GET("/users/:user_id/webhooks/:webhook_id", webhookController.GetWebhookHandler())
+	incomingRoutes.PATCH("/users/:user_id/webhooks/:webhook_id", webhookController.UpdateWebhookHandler())
+	incomingRoutes.DELETE("/users/:user_id/webhooks/:webhook_id", webhookController.DeleteWebhookHandler())
+	incomingRoutes.POST("/users/:user_id/webhooks/:webhook_id/ping", webhookController.PingWebhookHandler())
+	incomingRoutes.GET("/users/:user_id/webhooks/:webhook_id/deliveries", webhookController.GetDeliveriesHandler())
+	incomingRoutes.GET("/users/:user_id/webhooks/:webhook_id/deliveries/:delivery_id", webhookController.GetDeliveryHandler())
+	incomingRoutes.POST("/users/:user_id/webhooks/:webhook_id/deliveries/:delivery_id/redeliver", webhookController.RedeliverHandler())
+}
+
+// webhookOperations documents the routes WebhookRoutes registers.
+var webhookOperations = []openapi.Operation{
+	{Method: "GET", Path: "/users/:user_id/webhooks", Id: "listWebhooks", Tag: "webhooks", Summary: "List a user's webhooks", Response: []models.Webhook{}},
+	{Method: "POST", Path: "/users/:user_id/webhooks", Id: "createWebhook", Tag: "webhooks", Summary: "Register a webhook",
+		Description: "The signing secret is only returned here.", Request: models.Webhook{}, Response: models.Webhook{}, Status: http.StatusCreated},
+	{Method: "GET", Path: "/users/:user_id/webhooks/:webhook_id", Id: "getWebhook", Tag: "webhooks", Summary: "Get a webhook", Response: models.Webhook{}},
+	{Method: "PATCH", Path: "/users/:user_id/webhooks/:webhook_id", Id: "updateWebhook", Tag: "webhooks", Summary: "Update a webhook", Request: openapi.Partial{Of: models.Webhook{}}, Response: ""},
+	{Method: "DELETE", Path: "/users/:user_id/webhooks/:webhook_id", Id: "deleteWebhook", Tag: "webhooks", Summary: "Delete a webhook", Response: models.WebhookRef{}},
+	{Method: "POST", Path: "/users/:user_id/webhooks/:webhook_id/ping", Id: "pingWebhook", Tag: "webhooks", Summary: "Send a webhook.ping delivery", Response: models.DeliveryRef{}, Status: http.StatusAccepted},
+	{Method: "GET", Path: "/users/:user_id/webhooks/:webhook_id/deliveries", Id: "listWebhookDeliveries", Tag: "webhooks", Summary: "List deliveries",
+		Query:    append([]openapi.Parameter{{Name: "status", Type: "string", Enum: []string{models.DeliveryPending, models.DeliverySucceeded, models.DeliveryDead}}}, pagingQuery...),
+		Response: []models.WebhookDelivery{}},
+	{Method: "GET", Path: "/users/:user_id/webhooks/:webhook_id/deliveries/:delivery_id", Id: "getWebhookDelivery", Tag: "webhooks", Summary: "Get a delivery and its attempt log", Response: models.WebhookDelivery{}},
+	{Method: "POST", Path: "/users/:user_id/webhooks/:webhook_id/deliveries/:delivery_id/redeliver", Id: "redeliverWebhook", Tag: "webhooks", Summary: "Queue a delivery again", Response: models.DeliveryRef{}, Status: http.StatusAccepted},
+}
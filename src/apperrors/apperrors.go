@@ -0,0 +1,79 @@
+package apperrors
+
+import "net/http"
+
+// Error is implemented by the typed errors services return. Kind names the
+// error class and Status is the HTTP status it is reported with.
+type Error interface {
+	error
+	Kind() string
+	Status() int
+}
+
+// FieldError is one failed rule on one field of the input.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule,omitempty"`
+	Message string `json:"message"`
+}
+
+type NotFoundError struct {
+	Message string
+}
+
+func NotFound(message string) *NotFoundError {
+	return &NotFoundError{Message: message}
+}
+
+func (e *NotFoundError) Error() string { return e.Message }
+func (e *NotFoundError) Kind() string  { return "not-found" }
+func (e *NotFoundError) Status() int   { return http.StatusNotFound }
+
+type ConflictError struct {
+	Message string
+}
+
+func Conflict(message string) *ConflictError {
+	return &ConflictError{Message: message}
+}
+
+func (e *ConflictError) Error() string { return e.Message }
+func (e *ConflictError) Kind() string  { return "conflict" }
+func (e *ConflictError) Status() int   { return http.StatusConflict }
+
+type ValidationError struct {
+	Message string
+	Fields  []FieldError
+}
+
+func Validation(message string, fields ...FieldError) *ValidationError {
+	return &ValidationError{Message: message, Fields: fields}
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Fields) == 0 {
+		return e.Message
+	}
+	message := e.Message + ":"
+	for i, field := range e.Fields {
+		if i > 0 {
+			message += ";"
+		}
+		message += " " + field.Field + " " + field.Message
+	}
+	return message
+}
+func (e *ValidationError) Kind() string { return "validation" }
+func (e *ValidationError) Status() int  { return http.StatusBadRequest }
+
+type UnauthorizedError struct {
+	Message string
+}
+
+func Unauthorized(message string) *UnauthorizedError {
+	return &UnauthorizedError{Message: message}
+}
+
+func (e *UnauthorizedError) Error() string { return e.Message }
+func (e *UnauthorizedError) Kind() string  { return "unauthorized" }
+func (e *UnauthorizedError) Status() int   { return http.StatusUnauthorized }
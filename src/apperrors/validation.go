@@ -0,0 +1,107 @@
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FromValidator turns the error from validate.Struct(value) into a
+// ValidationError naming each field by its JSON name, the failed rule and a
+// readable message. Other errors are returned unchanged.
+func FromValidator(message string, err error, value any) error {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return err
+	}
+	fields := make([]FieldError, len(validationErrors))
+	for i, fieldError := range validationErrors {
+		fields[i] = FieldError{
+			Field:   jsonPath(reflect.TypeOf(value), fieldError.StructNamespace()),
+			Rule:    fieldError.Tag(),
+			Message: ruleMessage(fieldError),
+		}
+	}
+	return Validation(message, fields...)
+}
+
+// jsonPath maps a struct namespace such as
+// "CustomerBatchOperation.Customer.First_name" onto JSON names
+// ("customer.first_name"), following the fields through t.
+func jsonPath(t reflect.Type, namespace string) string {
+	segments := strings.Split(namespace, ".")
+	if len(segments) > 1 {
+		// The first segment is the root type's name.
+		segments = segments[1:]
+	}
+	names := make([]string, len(segments))
+	for i, segment := range segments {
+		name, index, _ := strings.Cut(segment, "[")
+		for t != nil && (t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map) {
+			t = t.Elem()
+		}
+		jsonName := name
+		if t != nil && t.Kind() == reflect.Struct {
+			if field, ok := t.FieldByName(name); ok {
+				jsonName = fieldName(field)
+				t = field.Type
+			} else {
+				t = nil
+			}
+		}
+		if index != "" {
+			jsonName += "[" + index
+		}
+		names[i] = jsonName
+	}
+	return strings.Join(names, ".")
+}
+
+func fieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
+
+func ruleMessage(fieldError validator.FieldError) string {
+	param := fieldError.Param()
+	switch fieldError.Tag() {
+	case "required":
+		return "is required"
+	case "required_if":
+		return "is required when " + strings.Replace(param, " ", " is ", 1)
+	case "required_unless":
+		return "is required unless " + strings.Replace(param, " ", " is ", 1)
+	case "required_with":
+		return "is required with " + param
+	case "file":
+		return "must be an existing file"
+	case "email":
+		return "must be a valid email address"
+	case "url":
+		return "must be a valid URL"
+	case "startswith":
+		return fmt.Sprintf("must start with %q", param)
+	case "oneof":
+		return "must be one of " + strings.Join(strings.Fields(param), ", ")
+	case "min", "max", "len":
+		return sizeMessage(fieldError.Tag(), fieldError.Kind(), param)
+	}
+	return fmt.Sprintf("failed the %s rule", fieldError.Tag())
+}
+
+func sizeMessage(rule string, kind reflect.Kind, param string) string {
+	bound := map[string]string{"min": "at least ", "max": "at most ", "len": "exactly "}[rule]
+	switch kind {
+	case reflect.String:
+		return "must be " + bound + param + " characters long"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return "must have " + bound + param + " items"
+	}
+	return "must be " + bound + param
+}
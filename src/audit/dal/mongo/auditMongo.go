@@ -0,0 +1,64 @@
+package mongo
+
+import (
+	"context"
+	"somdeep-demo-app/src/audit/interfaces"
+	"somdeep-demo-app/src/audit/models"
+	"somdeep-demo-app/src/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type auditRepository struct {
+	auditCollection *database.Collection
+}
+
+func NewAuditRepository(tenancy *database.Tenancy) interfaces.AuditRepository {
+	auditCollection := database.OpenTenantCollection(tenancy, "audit")
+	return &auditRepository{
+		auditCollection: auditCollection,
+	}
+}
+
+func (r *auditRepository) AddAuditEntries(ctx context.Context, entries []models.AuditEntry) error {
+	documents := make([]interface{}, len(entries))
+	for i, entry := range entries {
+		documents[i] = entry
+	}
+	_, err := r.auditCollection.InsertMany(ctx, documents)
+	return err
+}
+
+func (r *auditRepository) GetAuditEntries(ctx context.Context, filter models.AuditFilter, startIndex int, recordPerPage int) (*mongo.Cursor, error) {
+	query := bson.M{}
+	if filter.Entity_type != "" {
+		query["entity_type"] = filter.Entity_type
+	}
+	if filter.Entity_id != "" {
+		query["entity_id"] = filter.Entity_id
+	}
+	if filter.Parent_id != "" {
+		query["parent_id"] = filter.Parent_id
+	}
+	if filter.Actor != "" {
+		query["actor"] = filter.Actor
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(int64(startIndex)).
+		SetLimit(int64(recordPerPage))
+	return r.auditCollection.Find(ctx, query, opts)
+}
+
+// EnsureIndexes creates the indexes GetAuditEntries filters on: the history of
+// one entity, and of the customers of one user, each newest first.
+func (r *auditRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.auditCollection.CreateIndexes(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "entity_type", Value: 1}, {Key: "entity_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "parent_id", Value: 1}, {Key: "created_at", Value: -1}}},
+	})
+	return err
+}
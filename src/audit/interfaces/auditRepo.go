@@ -0,0 +1,16 @@
+package interfaces
+
+import (
+	"context"
+	"somdeep-demo-app/src/audit/models"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// AuditRepository is append-only on purpose: there is no way to update or
+// delete an entry once it has been written.
+type AuditRepository interface {
+	AddAuditEntries(ctx context.Context, entries []models.AuditEntry) error
+	GetAuditEntries(ctx context.Context, filter models.AuditFilter, startIndex int, recordPerPage int) (*mongo.Cursor, error)
+	EnsureIndexes(ctx context.Context) error
+}
@@ -0,0 +1,18 @@
+package interfaces
+
+import (
+	"context"
+	"somdeep-demo-app/src/audit/models"
+)
+
+type Response struct {
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+	Error   string `json:"error"`
+	Data    any    `json:"data"`
+}
+
+type AuditService interface {
+	Record(ctx context.Context, actor models.Actor, entries ...models.AuditEntry) error
+	GetAuditEntries(ctx context.Context, filter models.AuditFilter, recordPerPage int, page int, startIndex int) (response Response, err error)
+}
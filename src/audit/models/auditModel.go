@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	EntityUser     = "user"
+	EntityCustomer = "customer"
+
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// Actor identifies who performed a mutation and the request it came from. The
+// ID is taken from the X-Actor header as is, so the trail is only as
+// trustworthy as the gateway that sets it.
+type Actor struct {
+	ID         string
+	Request_id string
+}
+
+type FieldChange struct {
+	Field  string `json:"field"`
+	Before any    `json:"before"`
+	After  any    `json:"after"`
+}
+
+type AuditEntry struct {
+	ID          primitive.ObjectID `bson:"_id"`
+	Audit_id    string             `json:"audit_id"`
+	Actor       string             `json:"actor"`
+	Request_id  string             `json:"request_id"`
+	Entity_type string             `json:"entity_type"`
+	Entity_id   string             `json:"entity_id"`
+	Parent_id   string             `json:"parent_id,omitempty"`
+	Action      string             `json:"action"`
+	Changes     []FieldChange      `json:"changes"`
+	Created_at  time.Time          `json:"created_at"`
+}
+
+type AuditFilter struct {
+	Entity_type string
+	Entity_id   string
+	Parent_id   string
+	Actor       string
+}
@@ -0,0 +1,111 @@
+package modules
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sort"
+	"time"
+
+	"somdeep-demo-app/src/audit/interfaces"
+	"somdeep-demo-app/src/audit/models"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// anonymousActor is recorded when a request does not identify its caller.
+const anonymousActor = "anonymous"
+
+type auditService struct {
+	auditRepository interfaces.AuditRepository
+}
+
+func NewAuditService(auditRepository interfaces.AuditRepository) interfaces.AuditService {
+	return &auditService{
+		auditRepository: auditRepository,
+	}
+}
+
+// Record stamps the entries with the actor and time and appends them. It must
+// be called with the transaction context of the write the entries describe,
+// so they are committed with it; an error aborts the transaction.
+func (s *auditService) Record(ctx context.Context, actor models.Actor, entries ...models.AuditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	actorId := actor.ID
+	if actorId == "" {
+		actorId = anonymousActor
+	}
+	now := time.Now()
+	for i := range entries {
+		entries[i].ID = primitive.NewObjectID()
+		entries[i].Audit_id = uuid.New().String()
+		entries[i].Actor = actorId
+		entries[i].Request_id = actor.Request_id
+		entries[i].Created_at = now
+		if entries[i].Changes == nil {
+			entries[i].Changes = []models.FieldChange{}
+		}
+	}
+
+	return s.auditRepository.AddAuditEntries(ctx, entries)
+}
+
+func (s *auditService) GetAuditEntries(ctx context.Context, filter models.AuditFilter, recordPerPage int, page int, startIndex int) (response interfaces.Response, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Second)
+	defer cancel()
+
+	var res interfaces.Response
+
+	result, err := s.auditRepository.GetAuditEntries(ctx, filter, startIndex, recordPerPage)
+	if err != nil {
+		res.Status = http.StatusInternalServerError
+		res.Error = err.Error()
+		res.Message = "error occured while listing audit entries"
+		res.Data = nil
+		return res, err
+	}
+
+	entries := []models.AuditEntry{}
+	if err = result.All(ctx, &entries); err != nil {
+		res.Status = http.StatusInternalServerError
+		res.Error = err.Error()
+		res.Message = "error occured while listing audit entries"
+		res.Data = nil
+		return res, err
+	}
+
+	res.Status = http.StatusOK
+	res.Error = "NA"
+	res.Message = "Records Fetched Successfully"
+	res.Data = entries
+	return res, nil
+}
+
+// Diff returns one change per field whose value differs between the two
+// snapshots, sorted by field name. A nil snapshot stands for "did not exist".
+func Diff(before map[string]any, after map[string]any) []models.FieldChange {
+	fields := map[string]bool{}
+	for field := range before {
+		fields[field] = true
+	}
+	for field := range after {
+		fields[field] = true
+	}
+
+	changes := []models.FieldChange{}
+	for field := range fields {
+		oldValue, newValue := before[field], after[field]
+		if reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		changes = append(changes, models.FieldChange{Field: field, Before: oldValue, After: newValue})
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+	return changes
+}
@@ -0,0 +1,17 @@
+// Package backoff spaces out retries of work that failed.
+package backoff
+
+import "time"
+
+// Exponential is the delay before the retry that follows attempts failed
+// attempts: base, doubled for each earlier failure, capped at max.
+func Exponential(base time.Duration, max time.Duration, attempts int) time.Duration {
+	delay := base
+	for i := 0; i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	return delay
+}
@@ -0,0 +1,250 @@
+package broker
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const maxBulk = 16 << 20
+
+type standInEntry struct {
+	value     []byte
+	expiresAt time.Time // zero means no expiry
+}
+
+// RedisStandIn is a small in-memory server that understands the RESP2 commands
+// the Redis cache uses (PING, AUTH, SELECT, GET, SET with EX/PX, DEL, SCAN,
+// DBSIZE, FLUSHDB), so the service and its cache can run locally or in tests
+// without a real redis-server. SCAN returns every match in a single page.
+type RedisStandIn struct {
+	listener net.Listener
+
+	mu      sync.Mutex
+	data    map[string]standInEntry
+	clients map[net.Conn]bool
+	now     func() time.Time
+}
+
+func NewRedisStandIn(addr string) (*RedisStandIn, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &RedisStandIn{
+		listener: listener,
+		data:     map[string]standInEntry{},
+		clients:  map[net.Conn]bool{},
+		now:      time.Now,
+	}, nil
+}
+
+func (s *RedisStandIn) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Serve accepts connections until Close is called.
+func (s *RedisStandIn) Serve() error {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return err
+		}
+		s.mu.Lock()
+		s.clients[conn] = true
+		s.mu.Unlock()
+		go s.handle(conn)
+	}
+}
+
+func (s *RedisStandIn) Close() error {
+	err := s.listener.Close()
+	s.mu.Lock()
+	for conn := range s.clients {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	return err
+}
+
+func (s *RedisStandIn) handle(conn net.Conn) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.clients, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	reader := bufio.NewReader(conn)
+	writer := bufio.NewWriter(conn)
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+		if strings.ToUpper(args[0]) == "QUIT" {
+			writer.WriteString("+OK\r\n")
+			writer.Flush()
+			return
+		}
+		writer.WriteString(s.execute(args))
+		if err = writer.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func (s *RedisStandIn) execute(args []string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch command := strings.ToUpper(args[0]); command {
+	case "PING":
+		return "+PONG\r\n"
+	case "AUTH", "SELECT":
+		return "+OK\r\n"
+	case "GET":
+		if len(args) != 2 {
+			return wrongArgs(command)
+		}
+		entry, found := s.lookup(args[1])
+		if !found {
+			return "$-1\r\n"
+		}
+		return bulk(entry.value)
+	case "SET":
+		if len(args) < 3 {
+			return wrongArgs(command)
+		}
+		entry := standInEntry{value: []byte(args[2])}
+		for i := 3; i < len(args); i++ {
+			option := strings.ToUpper(args[i])
+			if (option != "EX" && option != "PX") || i+1 >= len(args) {
+				return "-ERR syntax error\r\n"
+			}
+			amount, err := strconv.ParseInt(args[i+1], 10, 64)
+			if err != nil || amount <= 0 {
+				return "-ERR invalid expire time in 'set' command\r\n"
+			}
+			unit := time.Millisecond
+			if option == "EX" {
+				unit = time.Second
+			}
+			entry.expiresAt = s.now().Add(time.Duration(amount) * unit)
+			i++
+		}
+		s.data[args[1]] = entry
+		return "+OK\r\n"
+	case "DEL":
+		deleted := 0
+		for _, key := range args[1:] {
+			if _, found := s.lookup(key); found {
+				delete(s.data, key)
+				deleted++
+			}
+		}
+		return fmt.Sprintf(":%d\r\n", deleted)
+	case "SCAN":
+		pattern := "*"
+		for i := 2; i+1 < len(args); i += 2 {
+			if strings.ToUpper(args[i]) == "MATCH" {
+				pattern = args[i+1]
+			}
+		}
+		var keys []string
+		for key := range s.data {
+			if _, found := s.lookup(key); !found {
+				continue
+			}
+			if matched, _ := path.Match(pattern, key); matched {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		reply := "*2\r\n$1\r\n0\r\n" + fmt.Sprintf("*%d\r\n", len(keys))
+		for _, key := range keys {
+			reply += bulk([]byte(key))
+		}
+		return reply
+	case "DBSIZE":
+		count := 0
+		for key := range s.data {
+			if _, found := s.lookup(key); found {
+				count++
+			}
+		}
+		return fmt.Sprintf(":%d\r\n", count)
+	case "FLUSHDB", "FLUSHALL":
+		s.data = map[string]standInEntry{}
+		return "+OK\r\n"
+	default:
+		return fmt.Sprintf("-ERR unknown command '%s'\r\n", args[0])
+	}
+}
+
+// lookup must be called with mu held. Expired keys are removed on access.
+func (s *RedisStandIn) lookup(key string) (standInEntry, bool) {
+	entry, found := s.data[key]
+	if found && !entry.expiresAt.IsZero() && !entry.expiresAt.After(s.now()) {
+		delete(s.data, key)
+		return entry, false
+	}
+	return entry, found
+}
+
+func bulk(value []byte) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+}
+
+func wrongArgs(command string) string {
+	return fmt.Sprintf("-ERR wrong number of arguments for '%s' command\r\n", strings.ToLower(command))
+}
+
+// readCommand reads a RESP array of bulk strings, or an inline command.
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if !strings.HasPrefix(line, "*") {
+		return strings.Fields(line), nil
+	}
+
+	count, err := strconv.Atoi(line[1:])
+	if err != nil || count < 0 {
+		return nil, errors.New("invalid multibulk length")
+	}
+	args := make([]string, count)
+	for i := range args {
+		header, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		header = strings.TrimRight(header, "\r\n")
+		if !strings.HasPrefix(header, "$") {
+			return nil, errors.New("expected bulk string")
+		}
+		size, err := strconv.Atoi(header[1:])
+		if err != nil || size < 0 || size > maxBulk {
+			return nil, errors.New("invalid bulk length")
+		}
+		buf := make([]byte, size+2)
+		if _, err = io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
@@ -0,0 +1,22 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+)
+
+// Cache is a byte-oriented key/value store with per-entry expiry. Callers
+// must treat every error as a miss: the cache is never the source of truth.
+type Cache interface {
+	Get(ctx context.Context, key string) (value []byte, found bool, err error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+	// Clear drops every entry this cache owns.
+	Clear(ctx context.Context) error
+	Len(ctx context.Context) (int64, error)
+	// Evictions is the number of live entries dropped to stay within the size
+	// bound, where the backend knows it.
+	Evictions() int64
+	Backend() string
+	Close() error
+}
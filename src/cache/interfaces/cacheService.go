@@ -0,0 +1,13 @@
+package interfaces
+
+type Response struct {
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+	Error   string `json:"error"`
+	Data    any    `json:"data"`
+}
+
+type CacheService interface {
+	GetCacheStats() (response Response, err error)
+	ClearCache() (response Response, err error)
+}
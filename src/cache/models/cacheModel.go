@@ -0,0 +1,70 @@
+package models
+
+import (
+	"sync/atomic"
+
+	"somdeep-demo-app/src/metrics"
+)
+
+// Metrics counts how one cached repository uses the cache, both for the
+// cache report and, labelled by Name, on /metrics.
+type Metrics struct {
+	Name string
+
+	hits          atomic.Int64
+	misses        atomic.Int64
+	invalidations atomic.Int64
+	errors        atomic.Int64
+}
+
+func NewMetrics(name string) *Metrics {
+	return &Metrics{Name: name}
+}
+
+func (m *Metrics) Hit() {
+	m.hits.Add(1)
+	metrics.CacheLookups.WithLabelValues(m.Name, "hit").Inc()
+}
+
+func (m *Metrics) Miss() {
+	m.misses.Add(1)
+	metrics.CacheLookups.WithLabelValues(m.Name, "miss").Inc()
+}
+
+func (m *Metrics) Invalidated(n int) {
+	m.invalidations.Add(int64(n))
+	metrics.CacheInvalidations.WithLabelValues(m.Name).Add(float64(n))
+}
+
+func (m *Metrics) Error() {
+	m.errors.Add(1)
+	metrics.CacheErrors.WithLabelValues(m.Name).Inc()
+}
+
+func (m *Metrics) Snapshot() CacheStats {
+	stats := CacheStats{
+		Hits:          m.hits.Load(),
+		Misses:        m.misses.Load(),
+		Invalidations: m.invalidations.Load(),
+		Errors:        m.errors.Load(),
+	}
+	if lookups := stats.Hits + stats.Misses; lookups > 0 {
+		stats.Hit_ratio = float64(stats.Hits) / float64(lookups)
+	}
+	return stats
+}
+
+type CacheStats struct {
+	Hits          int64   `json:"hits"`
+	Misses        int64   `json:"misses"`
+	Hit_ratio     float64 `json:"hit_ratio"`
+	Invalidations int64   `json:"invalidations"`
+	Errors        int64   `json:"errors"`
+}
+
+type CacheReport struct {
+	Backend      string                `json:"backend"`
+	Entries      int64                 `json:"entries"`
+	Evictions    int64                 `json:"evictions"`
+	Repositories map[string]CacheStats `json:"repositories"`
+}
@@ -0,0 +1,90 @@
+package modules
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"somdeep-demo-app/src/cache/interfaces"
+	"somdeep-demo-app/src/cache/models"
+)
+
+type cacheService struct {
+	cache   interfaces.Cache
+	metrics []*models.Metrics
+}
+
+func NewCacheService(cache interfaces.Cache, metrics ...*models.Metrics) interfaces.CacheService {
+	return &cacheService{
+		cache:   cache,
+		metrics: metrics,
+	}
+}
+
+func (s *cacheService) GetCacheStats() (response interfaces.Response, err error) {
+	var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+	defer cancel()
+
+	var res interfaces.Response
+
+	if s.cache == nil {
+		res.Status = http.StatusOK
+		res.Error = "NA"
+		res.Message = "Caching is disabled"
+		res.Data = models.CacheReport{Backend: "none", Repositories: map[string]models.CacheStats{}}
+		return res, nil
+	}
+
+	report := models.CacheReport{
+		Backend:      s.cache.Backend(),
+		Evictions:    s.cache.Evictions(),
+		Repositories: map[string]models.CacheStats{},
+	}
+	for _, metrics := range s.metrics {
+		report.Repositories[metrics.Name] = metrics.Snapshot()
+	}
+
+	report.Entries, err = s.cache.Len(ctx)
+	if err != nil {
+		res.Status = http.StatusInternalServerError
+		res.Error = err.Error()
+		res.Message = "Error occured while reading cache stats"
+		res.Data = report
+		return res, err
+	}
+
+	res.Status = http.StatusOK
+	res.Error = "NA"
+	res.Message = "Record Fetched Successfully"
+	res.Data = report
+	return res, nil
+}
+
+func (s *cacheService) ClearCache() (response interfaces.Response, err error) {
+	var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+	defer cancel()
+
+	var res interfaces.Response
+
+	if s.cache == nil {
+		res.Status = http.StatusOK
+		res.Error = "NA"
+		res.Message = "Caching is disabled"
+		res.Data = nil
+		return res, nil
+	}
+
+	if err = s.cache.Clear(ctx); err != nil {
+		res.Status = http.StatusInternalServerError
+		res.Error = err.Error()
+		res.Message = "Failed to clear the cache"
+		res.Data = nil
+		return res, err
+	}
+
+	res.Status = http.StatusOK
+	res.Error = "NA"
+	res.Message = "Cache cleared"
+	res.Data = nil
+	return res, nil
+}
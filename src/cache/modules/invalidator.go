@@ -0,0 +1,123 @@
+package modules
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"somdeep-demo-app/src/cache/interfaces"
+	"somdeep-demo-app/src/cache/models"
+	eventInterfaces "somdeep-demo-app/src/events/interfaces"
+	eventModels "somdeep-demo-app/src/events/models"
+	"somdeep-demo-app/src/tenant"
+)
+
+const (
+	invalidatorMinBackoff = time.Second
+	invalidatorMaxBackoff = 30 * time.Second
+)
+
+// KeyFunc maps an event to the cache keys it makes stale.
+type KeyFunc func(event eventModels.Event) []string
+
+// Invalidator follows the event stream and drops the entries that committed
+// changes make stale, including changes written by other instances. When the
+// stream cannot be resumed where it left off the whole cache is cleared,
+// since the events in between are unknown. It follows every tenant and drops
+// each entry for the tenant the event happened in.
+type Invalidator struct {
+	stream   eventInterfaces.EventStream
+	cache    interfaces.Cache
+	keyFuncs []KeyFunc
+	metrics  *models.Metrics
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewInvalidator(stream eventInterfaces.EventStream, cache interfaces.Cache, metrics *models.Metrics, keyFuncs ...KeyFunc) *Invalidator {
+	return &Invalidator{
+		stream:   stream,
+		cache:    cache,
+		keyFuncs: keyFuncs,
+		metrics:  metrics,
+		done:     make(chan struct{}),
+	}
+}
+
+// Start follows the stream in the background until Stop is called.
+func (v *Invalidator) Start() {
+	ctx, cancel := context.WithCancel(tenant.WithAllTenants(context.Background()))
+	v.cancel = cancel
+	go v.run(ctx)
+}
+
+func (v *Invalidator) Stop(ctx context.Context) error {
+	if v.cancel == nil {
+		return nil
+	}
+	v.cancel()
+	select {
+	case <-v.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (v *Invalidator) run(ctx context.Context) {
+	defer close(v.done)
+
+	lastEventId := ""
+	backoff := invalidatorMinBackoff
+	for {
+		events, err := v.stream.Follow(ctx, lastEventId)
+		if err == nil {
+			for message := range events {
+				v.apply(ctx, message)
+				if message.ID != "" {
+					lastEventId = message.ID
+				}
+				backoff = invalidatorMinBackoff
+			}
+		} else if ctx.Err() == nil {
+			slog.Error("cache: invalidation stream failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > invalidatorMaxBackoff {
+			backoff = invalidatorMaxBackoff
+		}
+	}
+}
+
+func (v *Invalidator) apply(ctx context.Context, message eventModels.StreamedEvent) {
+	if message.Reset {
+		if err := v.cache.Clear(ctx); err != nil {
+			v.metrics.Error()
+			return
+		}
+		v.metrics.Invalidated(1)
+		return
+	}
+
+	var keys []string
+	for _, keyFunc := range v.keyFuncs {
+		keys = append(keys, keyFunc(message.Event)...)
+	}
+	if len(keys) == 0 {
+		return
+	}
+	if message.Event.Tenant_id != "" {
+		ctx = tenant.WithTenant(ctx, message.Event.Tenant_id)
+	}
+	if err := v.cache.Delete(ctx, keys...); err != nil {
+		v.metrics.Error()
+		return
+	}
+	v.metrics.Invalidated(len(keys))
+}
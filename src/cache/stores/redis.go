@@ -0,0 +1,312 @@
+package stores
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"somdeep-demo-app/src/cache/interfaces"
+)
+
+const (
+	redisDialTimeout = 2 * time.Second
+	// redisOpTimeout bounds a command when the caller's context has no deadline.
+	// The cache sits in front of Mongo, so a slow cache must fail fast.
+	redisOpTimeout = 500 * time.Millisecond
+)
+
+// errNil is the reply to GET on a missing key.
+var errNil = errors.New("redis: nil")
+
+// Redis speaks enough of RESP2 to use any Redis-compatible server as the
+// cache. All keys are stored under a namespace prefix so Clear only removes
+// entries this service wrote. Commands share one connection, which is
+// re-dialled after any I/O error.
+type Redis struct {
+	addr      string
+	password  string
+	db        int
+	namespace string
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+	writer *bufio.Writer
+}
+
+var _ interfaces.Cache = (*Redis)(nil)
+
+// NewRedis accepts redis://[:password@]host:port[/db] or a bare host:port.
+func NewRedis(rawURL string, namespace string) (*Redis, error) {
+	c := &Redis{addr: rawURL, namespace: namespace}
+	if strings.Contains(rawURL, "://") {
+		parsed, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, err
+		}
+		c.addr = parsed.Host
+		if password, set := parsed.User.Password(); set {
+			c.password = password
+		}
+		if path := strings.Trim(parsed.Path, "/"); path != "" {
+			if c.db, err = strconv.Atoi(path); err != nil {
+				return nil, fmt.Errorf("redis: invalid database %q", path)
+			}
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ctx, cancel := context.WithTimeout(context.Background(), redisDialTimeout)
+	defer cancel()
+	if _, err := c.do(ctx, "PING"); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	reply, err := c.command(ctx, "GET", c.namespace+key)
+	if errors.Is(err, errNil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	value, ok := reply.([]byte)
+	if !ok {
+		return nil, false, fmt.Errorf("redis: unexpected GET reply %T", reply)
+	}
+	return value, true, nil
+}
+
+func (c *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	millis := ttl.Milliseconds()
+	if millis < 1 {
+		millis = 1
+	}
+	_, err := c.command(ctx, "SET", c.namespace+key, string(value), "PX", strconv.FormatInt(millis, 10))
+	return err
+}
+
+func (c *Redis) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	args := make([]string, 0, len(keys)+1)
+	args = append(args, "DEL")
+	for _, key := range keys {
+		args = append(args, c.namespace+key)
+	}
+	_, err := c.command(ctx, args...)
+	return err
+}
+
+func (c *Redis) Clear(ctx context.Context) error {
+	return c.scan(ctx, func(keys []string) error {
+		if len(keys) == 0 {
+			return nil
+		}
+		_, err := c.command(ctx, append([]string{"DEL"}, keys...)...)
+		return err
+	})
+}
+
+func (c *Redis) Len(ctx context.Context) (int64, error) {
+	var count int64
+	err := c.scan(ctx, func(keys []string) error {
+		count += int64(len(keys))
+		return nil
+	})
+	return count, err
+}
+
+// Evictions is unknown: the server evicts according to its own maxmemory policy.
+func (c *Redis) Evictions() int64 {
+	return 0
+}
+
+func (c *Redis) Backend() string {
+	return "redis"
+}
+
+func (c *Redis) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	return err
+}
+
+// scan walks every key under the namespace, one SCAN page at a time.
+func (c *Redis) scan(ctx context.Context, page func(keys []string) error) error {
+	cursor := "0"
+	for {
+		reply, err := c.command(ctx, "SCAN", cursor, "MATCH", c.namespace+"*", "COUNT", "500")
+		if err != nil {
+			return err
+		}
+		parts, ok := reply.([]any)
+		if !ok || len(parts) != 2 {
+			return fmt.Errorf("redis: unexpected SCAN reply %v", reply)
+		}
+		next, _ := parts[0].([]byte)
+		items, _ := parts[1].([]any)
+		keys := make([]string, 0, len(items))
+		for _, item := range items {
+			if key, ok := item.([]byte); ok {
+				keys = append(keys, string(key))
+			}
+		}
+		if err = page(keys); err != nil {
+			return err
+		}
+		cursor = string(next)
+		if cursor == "0" || cursor == "" {
+			return nil
+		}
+	}
+}
+
+func (c *Redis) command(ctx context.Context, args ...string) (any, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.do(ctx, args...)
+}
+
+// do must be called with mu held.
+func (c *Redis) do(ctx context.Context, args ...string) (any, error) {
+	if c.conn == nil {
+		if err := c.connect(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok || time.Until(deadline) > redisOpTimeout {
+		deadline = time.Now().Add(redisOpTimeout)
+	}
+	c.conn.SetDeadline(deadline)
+
+	writeCommand(c.writer, args)
+	err := c.writer.Flush()
+	var reply any
+	if err == nil {
+		reply, err = readReply(c.reader)
+	}
+
+	var replyErr redisError
+	if err != nil && !errors.Is(err, errNil) && !errors.As(err, &replyErr) {
+		// the connection is in an unknown state
+		c.conn.Close()
+		c.conn = nil
+	}
+	return reply, err
+}
+
+func (c *Redis) connect(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: redisDialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return err
+	}
+	c.conn = conn
+	c.reader = bufio.NewReader(conn)
+	c.writer = bufio.NewWriter(conn)
+
+	var setup [][]string
+	if c.password != "" {
+		setup = append(setup, []string{"AUTH", c.password})
+	}
+	if c.db != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(c.db)})
+	}
+	for _, args := range setup {
+		if _, err = c.do(ctx, args...); err != nil {
+			if c.conn != nil {
+				c.conn.Close()
+				c.conn = nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+func writeCommand(w *bufio.Writer, args []string) {
+	fmt.Fprintf(w, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(w, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+}
+
+// readReply decodes one RESP2 reply: simple strings and bulk strings become
+// []byte, integers int64 and arrays []any. Error replies are returned as
+// redisError and a nil bulk string as errNil.
+func readReply(r *bufio.Reader) (any, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("redis: empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return []byte(line[1:]), nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 {
+			return nil, errNil
+		}
+		buf := make([]byte, size+2)
+		if _, err = io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return buf[:size], nil
+	case '*':
+		count, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if count < 0 {
+			return nil, errNil
+		}
+		items := make([]any, count)
+		for i := range items {
+			items[i], err = readReply(r)
+			if err != nil && !errors.Is(err, errNil) {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("redis: unexpected reply %q", line)
+	}
+}
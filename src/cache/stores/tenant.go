@@ -0,0 +1,59 @@
+package stores
+
+import (
+	"context"
+	"time"
+
+	"somdeep-demo-app/src/cache/interfaces"
+	"somdeep-demo-app/src/tenant"
+)
+
+// Tenant keeps each tenant's entries under a key prefix of their own, so one
+// tenant's lookups never return another's. Entries are read and written for
+// the tenant of ctx; without one, reads miss and writes fail. Clear and Len
+// cover every tenant.
+type Tenant struct {
+	interfaces.Cache
+}
+
+var _ interfaces.Cache = (*Tenant)(nil)
+
+func NewTenant(cache interfaces.Cache) *Tenant {
+	return &Tenant{Cache: cache}
+}
+
+func (c *Tenant) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	prefix, err := tenantPrefix(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	return c.Cache.Get(ctx, prefix+key)
+}
+
+func (c *Tenant) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	prefix, err := tenantPrefix(ctx)
+	if err != nil {
+		return err
+	}
+	return c.Cache.Set(ctx, prefix+key, value, ttl)
+}
+
+func (c *Tenant) Delete(ctx context.Context, keys ...string) error {
+	prefix, err := tenantPrefix(ctx)
+	if err != nil {
+		return err
+	}
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = prefix + key
+	}
+	return c.Cache.Delete(ctx, prefixed...)
+}
+
+func tenantPrefix(ctx context.Context) (string, error) {
+	tenantId := tenant.Id(ctx)
+	if tenantId == "" {
+		return "", tenant.ErrMissing
+	}
+	return "tenant:" + tenantId + ":", nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"somdeep-demo-app/src/cache/broker"
+	cacheInterfaces "somdeep-demo-app/src/cache/interfaces"
+	"somdeep-demo-app/src/cache/stores"
+	"somdeep-demo-app/src/config"
+)
+
+// newCache picks the configured lookup cache: "memory" (default, an LRU
+// bounded by cache.size entries), "redis" or "none". A nil cache means
+// caching is disabled.
+func newCache(cfg config.Cache) (cacheInterfaces.Cache, error) {
+	switch cfg.Backend {
+	case "memory":
+		return stores.NewLRU(cfg.Size), nil
+	case "redis":
+		return stores.NewRedis(cfg.Redis_url, cfg.Redis_namespace)
+	case "none":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown cache.backend %q, expected memory, redis or none", cfg.Backend)
+	}
+}
+
+// runRedisStandIn implements the "redis-standin" subcommand, a local
+// Redis-compatible server for the cache.
+func runRedisStandIn(args []string) int {
+	flags := flag.NewFlagSet("redis-standin", flag.ContinueOnError)
+	addr := flags.String("addr", "127.0.0.1:6379", "address to listen on")
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	standIn, err := broker.NewRedisStandIn(*addr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "redis-standin:", err)
+		return 1
+	}
+	slog.Info("redis stand-in: listening", "addr", standIn.Addr())
+	if err = standIn.Serve(); err != nil {
+		fmt.Fprintln(os.Stderr, "redis-standin:", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"somdeep-demo-app/src/config"
+	"somdeep-demo-app/src/events/broker"
+	eventInterfaces "somdeep-demo-app/src/events/interfaces"
+	"somdeep-demo-app/src/events/publishers"
+)
+
+// newEventPublisher picks the configured event publisher: "memory" (default),
+// "file" or "nats".
+func newEventPublisher(cfg config.Events) (eventInterfaces.EventPublisher, error) {
+	switch cfg.Publisher {
+	case "memory":
+		return publishers.NewInProcessPublisher(), nil
+	case "file":
+		return publishers.NewFilePublisher(cfg.File)
+	case "nats":
+		return publishers.NewNATSPublisher(cfg.NATS_url, cfg.NATS_subject_prefix)
+	default:
+		return nil, fmt.Errorf("unknown events.publisher %q, expected memory, file or nats", cfg.Publisher)
+	}
+}
+
+// runNATSStandIn implements the "nats-standin" subcommand, a local broker that
+// logs every message it receives.
+func runNATSStandIn(args []string) int {
+	flags := flag.NewFlagSet("nats-standin", flag.ContinueOnError)
+	addr := flags.String("addr", "127.0.0.1:4222", "address to listen on")
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	standIn, err := broker.NewNATSStandIn(*addr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "nats-standin:", err)
+		return 1
+	}
+	standIn.OnPublish = func(subject string, payload []byte) {
+		slog.Info("nats stand-in: published", "subject", subject, "payload", string(payload))
+	}
+	slog.Info("nats stand-in: listening", "addr", standIn.Addr())
+	if err = standIn.Serve(); err != nil {
+		fmt.Fprintln(os.Stderr, "nats-standin:", err)
+		return 1
+	}
+	return 0
+}
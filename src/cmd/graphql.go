@@ -0,0 +1,14 @@
+package main
+
+import (
+	graphqlServers "somdeep-demo-app/src/api/graphql/servers"
+	"somdeep-demo-app/src/config"
+)
+
+// graphqlOptions returns the configured query limits.
+func graphqlOptions(cfg config.GraphQL) graphqlServers.Options {
+	return graphqlServers.Options{
+		MaxDepth:      cfg.Max_depth,
+		MaxComplexity: cfg.Max_complexity,
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	cacheInterfaces "somdeep-demo-app/src/cache/interfaces"
+	"somdeep-demo-app/src/database"
+	eventInterfaces "somdeep-demo-app/src/events/interfaces"
+	healthModels "somdeep-demo-app/src/health/models"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// mongoCheck pings the primary and reports the connection pool.
+func mongoCheck(client *mongo.Client) healthModels.HealthCheck {
+	return healthModels.HealthCheck{
+		Name: "mongo",
+		Check: func(ctx context.Context) (any, error) {
+			err := client.Ping(ctx, readpref.Primary())
+			return database.Pool(), err
+		},
+	}
+}
+
+// cacheCheck reads the size of the cache, which needs a round trip to Redis.
+func cacheCheck(cache cacheInterfaces.Cache) healthModels.HealthCheck {
+	return healthModels.HealthCheck{
+		Name: "cache",
+		Check: func(ctx context.Context) (any, error) {
+			entries, err := cache.Len(ctx)
+			return map[string]any{"backend": cache.Backend(), "entries": entries}, err
+		},
+	}
+}
+
+// eventBusCheck pings the broker events are published to.
+func eventBusCheck(pinger eventInterfaces.EventPinger) healthModels.HealthCheck {
+	return healthModels.HealthCheck{
+		Name: "event_bus",
+		Check: func(ctx context.Context) (any, error) {
+			return nil, pinger.Ping(ctx)
+		},
+	}
+}
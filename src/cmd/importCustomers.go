@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"somdeep-demo-app/src/api/http/controllers"
+	auditMongo "somdeep-demo-app/src/audit/dal/mongo"
+	auditModels "somdeep-demo-app/src/audit/models"
+	auditModules "somdeep-demo-app/src/audit/modules"
+	"somdeep-demo-app/src/config"
+	customerMongo "somdeep-demo-app/src/customer/dal/mongo"
+	customerModels "somdeep-demo-app/src/customer/models"
+	customerModules "somdeep-demo-app/src/customer/modules"
+	"somdeep-demo-app/src/database"
+	"somdeep-demo-app/src/logging"
+	outboxMongo "somdeep-demo-app/src/outbox/dal/mongo"
+	outboxModules "somdeep-demo-app/src/outbox/modules"
+	"somdeep-demo-app/src/tenant"
+	tenantMongo "somdeep-demo-app/src/tenant/dal/mongo"
+	userMongo "somdeep-demo-app/src/user/dal/mongo"
+
+	"github.com/google/uuid"
+)
+
+// runImportCustomers implements the "import-customers" subcommand. It returns
+// the process exit code: 1 when the import fails and 2 when it completes but
+// some rows were rejected.
+func runImportCustomers(args []string) int {
+	flags := flag.NewFlagSet("import-customers", flag.ContinueOnError)
+	userId := flags.String("user", "", "user_id that will own the imported customers")
+	file := flags.String("file", "-", "path of the CSV or NDJSON file, - for stdin")
+	format := flags.String("format", "", "csv or ndjson (default: from the file extension)")
+	dryRun := flags.Bool("dry-run", false, "validate and report without writing anything")
+	actor := flags.String("actor", "cli", "actor recorded in the audit log")
+	tenantId := flags.String("tenant", "", "tenant_id to import into, required when tenancy is on")
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+	if *userId == "" {
+		fmt.Fprintln(os.Stderr, "import-customers: -user is required")
+		flags.Usage()
+		return 1
+	}
+
+	var input io.Reader = os.Stdin
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "import-customers:", err)
+			return 1
+		}
+		defer f.Close()
+		input = f
+	}
+
+	// the server's configuration file, when there is one, comes from CONFIG_FILE
+	cfg, err := config.Load(nil)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "import-customers:", err)
+		return 1
+	}
+	if err = configureLogging(cfg.Log); err != nil {
+		fmt.Fprintln(os.Stderr, "import-customers:", err)
+		return 1
+	}
+	if enabled := cfg.Tenancy.Mode != database.TenancyNone; enabled != (*tenantId != "") {
+		if enabled {
+			fmt.Fprintln(os.Stderr, "import-customers: -tenant is required when tenancy is on")
+		} else {
+			fmt.Fprintln(os.Stderr, "import-customers: -tenant needs tenancy.mode to be database or shared")
+		}
+		return 1
+	}
+	operationTimeouts, err := serviceTimeouts(cfg.Services)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "import-customers:", err)
+		return 1
+	}
+
+	// an interrupt stops waiting for MongoDB or cancels the import rather than
+	// leaving a half-written batch; the run gets a request ID of its own for
+	// the log and the audit trail
+	requestId := uuid.New().String()
+	ctx, stop := signal.NotifyContext(logging.WithRequestId(context.Background(), requestId), os.Interrupt)
+	defer stop()
+
+	// events go to the outbox and are delivered by the relay of a running server
+	client, err := database.DBinstance(ctx, cfg.Mongo)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "import-customers:", err)
+		return 1
+	}
+	defer client.Disconnect(context.Background())
+	tenancy := database.NewTenancy(client, cfg.Mongo, cfg.Tenancy)
+	if tenancy.Enabled() {
+		if _, err = tenantMongo.NewTenantRepository(tenancy).GetTenantByTenantId(ctx, *tenantId); err != nil {
+			fmt.Fprintf(os.Stderr, "import-customers: tenant %q: %v\n", *tenantId, err)
+			return 1
+		}
+		ctx = tenant.WithTenant(ctx, *tenantId)
+	}
+	outboxPublisher := outboxModules.NewOutboxPublisher(outboxMongo.NewOutboxRepository(tenancy))
+	auditService := auditModules.NewAuditService(auditMongo.NewAuditRepository(tenancy))
+	userRepo := userMongo.NewUserRepository(tenancy)
+	customerRepo := customerMongo.NewCustomerRepository(tenancy)
+	customerService := customerModules.NewCustomerService(customerRepo, userRepo, auditService, outboxPublisher, nil, newTransactor(client, cfg.Mongo), operationTimeouts)
+
+	response, err := customerService.ImportCustomersByUserId(ctx, auditModels.Actor{ID: *actor, Request_id: requestId}, *userId, input, controllers.ImportFormat(*format, *file, ""), *dryRun)
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(response)
+
+	if err != nil {
+		return 1
+	}
+	if report, ok := response.Data.(customerModels.CustomerImportReport); ok && len(report.Errors) > 0 {
+		return 2
+	}
+	return 0
+}
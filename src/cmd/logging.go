@@ -0,0 +1,25 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"somdeep-demo-app/src/config"
+	"somdeep-demo-app/src/logging"
+)
+
+// configureLogging sets the level of the default JSON logger. /admin/log-level
+// changes it afterwards.
+func configureLogging(cfg config.Log) error {
+	level, err := logging.ParseLevel(cfg.Level)
+	if err != nil {
+		return err
+	}
+	logging.Level.Set(level)
+	return nil
+}
+
+// fatal logs why the process cannot go on and exits.
+func fatal(message string, err error) {
+	slog.Error(message, "error", err)
+	os.Exit(1)
+}
@@ -1,42 +1,310 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
 	"os"
-	"somdeep-demo-app/src/api/http/routes"
+	"os/signal"
+	graphqlServers "somdeep-demo-app/src/api/graphql/servers"
+	grpcServers "somdeep-demo-app/src/api/grpc/servers"
+	"somdeep-demo-app/src/api/http/controllers"
+	auditMongo "somdeep-demo-app/src/audit/dal/mongo"
+	auditModules "somdeep-demo-app/src/audit/modules"
+	cacheModels "somdeep-demo-app/src/cache/models"
+	cacheModules "somdeep-demo-app/src/cache/modules"
+	"somdeep-demo-app/src/cache/stores"
+	"somdeep-demo-app/src/config"
+	customerCache "somdeep-demo-app/src/customer/dal/cache"
 	customerMongo "somdeep-demo-app/src/customer/dal/mongo"
 	customerModules "somdeep-demo-app/src/customer/modules"
 	"somdeep-demo-app/src/database"
+	eventInterfaces "somdeep-demo-app/src/events/interfaces"
+	"somdeep-demo-app/src/events/publishers"
+	"somdeep-demo-app/src/events/stream"
+	healthModules "somdeep-demo-app/src/health/modules"
+	"somdeep-demo-app/src/logging"
+	loggingModules "somdeep-demo-app/src/logging/modules"
+	outboxMongo "somdeep-demo-app/src/outbox/dal/mongo"
+	outboxModules "somdeep-demo-app/src/outbox/modules"
+	tenantMongo "somdeep-demo-app/src/tenant/dal/mongo"
+	tenantModules "somdeep-demo-app/src/tenant/modules"
+	userCache "somdeep-demo-app/src/user/dal/cache"
 	userMongo "somdeep-demo-app/src/user/dal/mongo"
 	userModules "somdeep-demo-app/src/user/modules"
-
-	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
+	webhookMongo "somdeep-demo-app/src/webhook/dal/mongo"
+	webhookModules "somdeep-demo-app/src/webhook/modules"
+	"strconv"
+	"syscall"
+	"time"
 )
 
+// webhookDeliveryRetention is how long webhook delivery logs are kept.
+const webhookDeliveryRetention = 30 * 24 * time.Hour
+
+// streamReplaySize is how many events the in-process stream keeps for
+// Last-Event-ID resumes.
+const streamReplaySize = 1000
+
 func main() {
-	err := godotenv.Load(".env")
-	// err := godotenv.Load("/Users/somdeep/Documents/Self-Projects/somdeep-demo-app/.env")
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "import-customers":
+			os.Exit(runImportCustomers(os.Args[2:]))
+		case "nats-standin":
+			os.Exit(runNATSStandIn(os.Args[2:]))
+		case "redis-standin":
+			os.Exit(runRedisStandIn(os.Args[2:]))
+		case "openapi":
+			os.Exit(runOpenAPI(os.Args[2:]))
+		}
+	}
+
+	cfg, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
 	if err != nil {
-		log.Fatal("Error loading the .env file")
+		fatal("Invalid configuration", err)
 	}
-	port := os.Getenv("PORT")
+	if err = configureLogging(cfg.Log); err != nil {
+		fatal("Invalid logging configuration", err)
+	}
+	slog.Info("configuration loaded", "config", cfg)
+
+	// everything started below registers how to stop it; on shutdown the
+	// steps run in reverse, so spans and events are flushed last
+	var stops lifecycle
 
-	if port == "" {
-		port = "8000"
+	shutdownTracing, err := setupTracing(cfg.Tracing)
+	if err != nil {
+		fatal("Failed to start tracing", err)
 	}
+	stops.onStop("tracing", shutdownTracing)
 
 	// Initialize the MongoDB client and repository
-	client := database.DBinstance()
-	userRepo := userMongo.NewUserRepository(client)
-	userService := userModules.NewUserService(userRepo)
-
-	customerRepo := customerMongo.NewCustomerRepository(client)
-	customerService := customerModules.NewCustomerService(customerRepo, userRepo)
-
-	router := gin.New()
-	router.Use(gin.Logger())
-	routes.UserRoutes(router, userService)
-	routes.CustomerRoutes(router, customerService)
-	router.Run(":" + port)
+	eventPublisher, err := newEventPublisher(cfg.Events)
+	if err != nil {
+		fatal("Failed to start the event publisher", err)
+	}
+	stops.onStop("event publisher", func(context.Context) error { return eventPublisher.Close() })
+
+	client, err := database.DBinstance(context.Background(), cfg.Mongo)
+	if err != nil {
+		fatal("Failed to connect to MongoDB", err)
+	}
+	tenancy := database.NewTenancy(client, cfg.Mongo, cfg.Tenancy)
+	stops.onStop("mongo", client.Disconnect)
+
+	// with tenancy on, every repository below scopes its queries to the
+	// tenant of the context it is called with
+	tenantRepo := tenantMongo.NewTenantRepository(tenancy)
+	registry := tenantModules.NewRegistry(tenantRepo, cfg.Tenancy.Refresh_interval)
+	tenants, tenantResolver, err := startTenancy(tenancy, tenantRepo, registry, cfg.Tenancy)
+	if err != nil {
+		fatal("Failed to start tenancy", err)
+	}
+	if tenants != nil {
+		stops.onStop("tenant registry", registry.Stop)
+	}
+	healthService := healthModules.NewHealthService(mongoCheck(client))
+	if pinger, ok := eventPublisher.(eventInterfaces.EventPinger); ok {
+		healthService.RegisterCheck(eventBusCheck(pinger))
+	}
+	transactor := newTransactor(client, cfg.Mongo)
+	auditRepo := auditMongo.NewAuditRepository(tenancy)
+	auditService := auditModules.NewAuditService(auditRepo)
+
+	lookupCache, err := newCache(cfg.Cache)
+	if err != nil {
+		fatal("Failed to start the cache", err)
+	}
+	userCacheMetrics := cacheModels.NewMetrics("users")
+	customerCacheMetrics := cacheModels.NewMetrics("customers")
+	invalidationMetrics := cacheModels.NewMetrics("invalidator")
+
+	userRepo := userMongo.NewUserRepository(tenancy)
+	customerRepo := customerMongo.NewCustomerRepository(tenancy)
+	// entries are kept per tenant, so a lookup never returns another
+	// tenant's record
+	repoCache := lookupCache
+	if lookupCache != nil && tenancy.Enabled() {
+		repoCache = stores.NewTenant(lookupCache)
+	}
+	if lookupCache != nil {
+		stops.onStop("cache", func(context.Context) error { return lookupCache.Close() })
+		healthService.RegisterCheck(cacheCheck(lookupCache))
+		userRepo = userCache.NewCachedUserRepository(userRepo, repoCache, cfg.Cache.TTL, userCacheMetrics)
+		customerRepo = customerCache.NewCachedCustomerRepository(customerRepo, repoCache, cfg.Cache.TTL, customerCacheMetrics)
+	}
+
+	webhookRepo := webhookMongo.NewWebhookRepository(tenancy)
+	outboxRepo := outboxMongo.NewOutboxRepository(tenancy)
+	// a new tenant gets the same indexes at provisioning
+	provisioners := []tenantModules.Provisioner{
+		func(ctx context.Context) error {
+			if err := webhookRepo.EnsureIndexes(ctx, webhookDeliveryRetention); err != nil {
+				return fmt.Errorf("webhooks: %w", err)
+			}
+			return nil
+		},
+		func(ctx context.Context) error {
+			if err := outboxRepo.EnsureIndexes(ctx, outboxRetention); err != nil {
+				return fmt.Errorf("outbox: %w", err)
+			}
+			return nil
+		},
+		func(ctx context.Context) error {
+			if err := auditRepo.EnsureIndexes(ctx); err != nil {
+				return fmt.Errorf("audit: %w", err)
+			}
+			return nil
+		},
+	}
+	ensureIndexes(tenants, provisioners...)
+
+	operationTimeouts, err := serviceTimeouts(cfg.Services)
+	if err != nil {
+		fatal("Invalid service timeouts", err)
+	}
+
+	dispatcherOptions := webhookModules.DefaultDispatcherOptions()
+	dispatcherOptions.Tenants = tenants
+	dispatcher := webhookModules.NewDispatcher(webhookRepo, dispatcherOptions)
+	dispatcher.Start()
+	stops.onStop("webhook dispatcher", dispatcher.Stop)
+	webhookService := webhookModules.NewWebhookService(webhookRepo, userRepo, dispatcher, operationTimeouts)
+
+	// services only write events to the outbox, the relay delivers them to
+	// the event publisher and fans them out to webhooks
+	outboxPublisher := outboxModules.NewOutboxPublisher(outboxRepo)
+	relayTargets := []eventInterfaces.EventPublisher{eventPublisher, webhookModules.NewWebhookFanout(webhookRepo, dispatcher)}
+
+	// change streams need a replica set, which is also what transactions need;
+	// otherwise customer changes are streamed from an in-process bus the relay feeds
+	var eventStream eventInterfaces.EventStream
+	if transactor.Enabled() {
+		eventStream = outboxMongo.NewOutboxStream(tenancy)
+	} else {
+		bus := publishers.NewInProcessPublisher()
+		relayTargets = append(relayTargets, bus)
+		eventStream = stream.NewBusStream(bus, streamReplaySize)
+	}
+
+	relayTarget := publishers.NewMultiPublisher(relayTargets...)
+
+	relayOptions := outboxModules.DefaultRelayOptions()
+	relayOptions.Tenants = tenants
+	relay := outboxModules.NewRelay(outboxRepo, relayTarget, relayOptions)
+	relay.Start()
+	stops.onStop("outbox relay", relay.Stop)
+	outboxService := outboxModules.NewOutboxService(outboxRepo, relay)
+
+	// cached lookups are invalidated by local writes and by the event stream,
+	// which also carries changes made by other instances
+	if lookupCache != nil {
+		invalidator := cacheModules.NewInvalidator(eventStream, repoCache, invalidationMetrics, userCache.InvalidationKeys, customerCache.InvalidationKeys)
+		invalidator.Start()
+		stops.onStop("cache invalidator", invalidator.Stop)
+	}
+	cacheService := cacheModules.NewCacheService(lookupCache, userCacheMetrics, customerCacheMetrics, invalidationMetrics)
+	tenantService := tenantModules.NewTenantService(tenantRepo, registry, tenancy, lookupCache, operationTimeouts, provisioners...)
+
+	// every API calls the services through the instrumented wrappers, so the
+	// timings and business counters cover HTTP, GraphQL and gRPC alike
+	userService := userModules.NewInstrumentedUserService(
+		userModules.NewUserService(userRepo, auditService, outboxPublisher, transactor, operationTimeouts))
+
+	customerService := customerModules.NewInstrumentedCustomerService(
+		customerModules.NewCustomerService(customerRepo, userRepo, auditService, outboxPublisher, eventStream, transactor, operationTimeouts))
+
+	graphqlServer, err := graphqlServers.NewServer(userService, customerService, graphqlOptions(cfg.GraphQL))
+	if err != nil {
+		fatal("Failed to build the GraphQL schema", err)
+	}
+
+	grpcListener, err := net.Listen("tcp", ":"+strconv.Itoa(cfg.GRPC.Port))
+	if err != nil {
+		fatal("Failed to listen for gRPC", err)
+	}
+	grpcServer := grpcServers.NewServer(userService, customerService, healthService, tenantResolver)
+	go func() {
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			slog.Error("grpc: server stopped", "error", err)
+		}
+	}()
+	stops.onStop("grpc", func(ctx context.Context) error {
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+			return nil
+		case <-ctx.Done():
+			grpcServer.Stop()
+			return fmt.Errorf("closed open streams: %w", ctx.Err())
+		}
+	})
+
+	adminToken, err := readAdminToken(cfg.Admin.Token_file)
+	if err != nil {
+		fatal("Failed to read the admin token", err)
+	}
+	router, _, err := newRouter(services{
+		user:     userService,
+		customer: customerService,
+		audit:    auditService,
+		outbox:   outboxService,
+		webhook:  webhookService,
+		cache:    cacheService,
+		tenant:   tenantService,
+		logging:  loggingModules.NewLoggingService(logging.Level),
+		health:   healthService,
+		graphql:  graphqlServer,
+	}, tenantResolver, adminToken, cfg.HTTP)
+	if err != nil {
+		fatal("Failed to build the HTTP routes", err)
+	}
+	server := newHTTPServer(router, cfg.HTTP)
+	server.RegisterOnShutdown(controllers.CloseStreams)
+
+	signals, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+	served := make(chan error, 1)
+	go func() {
+		slog.Info("http: listening", "port", cfg.HTTP.Port, "grpc_port", cfg.GRPC.Port)
+		served <- server.ListenAndServe()
+	}()
+
+	select {
+	case err = <-served:
+		stops.stop(context.Background())
+		fatal("HTTP server stopped", err)
+	case <-signals.Done():
+	}
+	// a second signal kills the process without waiting
+	stopSignals()
+
+	// fail readiness first so load balancers stop sending requests, then
+	// stop accepting connections and let in-flight requests finish
+	slog.Info("shutdown: draining", "delay", cfg.Shutdown.Drain_delay)
+	healthService.StartDraining()
+	time.Sleep(cfg.Shutdown.Drain_delay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Shutdown.Timeout)
+	if err = server.Shutdown(ctx); err != nil {
+		slog.Error("shutdown: requests did not finish in time, closing their connections", "error", err)
+		server.Close()
+	}
+	cancel()
+
+	ctx, cancel = context.WithTimeout(context.Background(), cfg.Shutdown.Timeout)
+	stops.stop(ctx)
+	cancel()
+	slog.Info("shutdown: complete")
 }
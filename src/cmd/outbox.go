@@ -0,0 +1,30 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"somdeep-demo-app/src/config"
+	"somdeep-demo-app/src/database"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// outboxRetention is how long delivered outbox messages are kept around.
+const outboxRetention = 7 * 24 * time.Hour
+
+// newTransactor enables transactions when mongo.transactions says so, or,
+// when it is unset, when the server is part of a replica set or cluster.
+func newTransactor(client *mongo.Client, cfg config.Mongo) database.Transactor {
+	if cfg.Transactions != nil {
+		return database.NewTransactor(client, *cfg.Transactions)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	enabled := database.SupportsTransactions(ctx, client)
+	if !enabled {
+		slog.Warn("MongoDB transactions are not available, outbox writes will not be atomic with entity changes")
+	}
+	return database.NewTransactor(client, enabled)
+}
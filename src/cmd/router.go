@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	graphqlServers "somdeep-demo-app/src/api/graphql/servers"
+	"somdeep-demo-app/src/api/http/middleware"
+	"somdeep-demo-app/src/api/http/openapi"
+	"somdeep-demo-app/src/api/http/problems"
+	"somdeep-demo-app/src/api/http/routes"
+	auditInterfaces "somdeep-demo-app/src/audit/interfaces"
+	cacheInterfaces "somdeep-demo-app/src/cache/interfaces"
+	"somdeep-demo-app/src/config"
+	customerInterfaces "somdeep-demo-app/src/customer/interfaces"
+	healthInterfaces "somdeep-demo-app/src/health/interfaces"
+	loggingInterfaces "somdeep-demo-app/src/logging/interfaces"
+	outboxInterfaces "somdeep-demo-app/src/outbox/interfaces"
+	"somdeep-demo-app/src/tenant"
+	tenantInterfaces "somdeep-demo-app/src/tenant/interfaces"
+	"somdeep-demo-app/src/tracing"
+	userInterfaces "somdeep-demo-app/src/user/interfaces"
+	webhookInterfaces "somdeep-demo-app/src/webhook/interfaces"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+)
+
+// services is everything the HTTP routes are built from.
+type services struct {
+	user     userInterfaces.UserService
+	customer customerInterfaces.CustomerService
+	audit    auditInterfaces.AuditService
+	outbox   outboxInterfaces.OutboxService
+	webhook  webhookInterfaces.WebhookService
+	cache    cacheInterfaces.CacheService
+	tenant   tenantInterfaces.TenantService
+	logging  loggingInterfaces.LoggingService
+	health   healthInterfaces.HealthService
+	graphql  *graphqlServers.Server
+}
+
+// newRouter registers every route and returns the OpenAPI document describing
+// them, which requests are validated against. It fails when a route has no
+// operation. The /admin routes require adminToken. With tenants set,
+// requests are scoped to the tenant they name. Responses are checked too when
+// cfg.Validate_responses is set, or, when it is nil, in gin's test mode.
+func newRouter(s services, tenants *tenant.Resolver, adminToken []byte, cfg config.HTTP) (*gin.Engine, *openapi.Document, error) {
+	validateResponses := gin.Mode() == gin.TestMode
+	if cfg.Validate_responses != nil {
+		validateResponses = *cfg.Validate_responses
+	}
+	validator := openapi.NewValidator(openapi.ValidatorOptions{
+		ValidateResponses: validateResponses,
+		MaxBodyBytes:      int64(cfg.Max_body_bytes),
+	})
+
+	router := gin.New()
+	router.Use(
+		otelgin.Middleware(tracing.ServiceName, otelgin.WithFilter(traced)),
+		middleware.RequestId(),
+		middleware.Logger(),
+		middleware.Metrics(),
+		middleware.Recovery(),
+		middleware.Admin(adminToken),
+		validator.Middleware(),
+	)
+	if tenants != nil {
+		router.Use(middleware.Tenant(tenants))
+	}
+	router.NoRoute(problems.RouteNotFound)
+	routes.UserRoutes(router, s.user)
+	routes.CustomerRoutes(router, s.customer)
+	routes.AuditRoutes(router, s.audit)
+	routes.OutboxRoutes(router, s.outbox)
+	routes.WebhookRoutes(router, s.webhook)
+	routes.CacheRoutes(router, s.cache)
+	routes.TenantRoutes(router, s.tenant)
+	routes.LoggingRoutes(router, s.logging)
+	routes.MetricsRoutes(router)
+	routes.HealthRoutes(router, s.health)
+	routes.GraphQLRoutes(router, s.graphql)
+	document, err := routes.OpenAPIRoutes(router)
+	if err != nil {
+		return nil, nil, err
+	}
+	validator.SetDocument(document)
+	return router, document, nil
+}
+
+// readAdminToken reads the token the /admin routes require, or returns nil,
+// which refuses them, when no file is configured.
+func readAdminToken(path string) ([]byte, error) {
+	if path == "" {
+		slog.Warn("admin: admin.token_file is not set, the /admin routes are refused")
+		return nil, nil
+	}
+	token, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	token = bytes.TrimSpace(token)
+	if len(token) == 0 {
+		return nil, fmt.Errorf("%s is empty", path)
+	}
+	return token, nil
+}
+
+// untraced are the paths polled by Prometheus and the orchestrator.
+var untraced = map[string]bool{"/metrics": true, "/healthz": true, "/readyz": true}
+
+// traced leaves scrapes and probes out of the traces.
+func traced(request *http.Request) bool {
+	return !untraced[request.URL.Path]
+}
+
+// runOpenAPI implements the "openapi" subcommand, which prints the OpenAPI
+// document without connecting to anything. It exits non-zero when a route is
+// undocumented, so CI can run it as a check.
+func runOpenAPI(args []string) int {
+	flags := flag.NewFlagSet("openapi", flag.ContinueOnError)
+	output := flags.String("o", "", "write the document to this file instead of stdout")
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	_, document, err := newRouter(services{}, nil, nil, config.HTTP{})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	data, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	data = append(data, '\n')
+
+	if *output == "" {
+		_, err = os.Stdout.Write(data)
+	} else {
+		err = os.WriteFile(*output, data, 0o644)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"somdeep-demo-app/src/api/http/problems"
+	"somdeep-demo-app/src/api/http/routes"
+	"somdeep-demo-app/src/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// selfDescribing are the routes serving the document itself, registered after
+// it is built.
+var selfDescribing = map[string]bool{"GET /openapi.json": true, "GET /docs/*any": true}
+
+func TestEveryRouteIsInTheSpec(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router, document, err := newRouter(services{}, nil, nil, config.HTTP{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a route dispatching on a path parameter is documented once per value,
+	// under the operation's SpecPath
+	specPaths := map[string][]string{}
+	for _, operation := range routes.Operations() {
+		key := operation.Method + " " + operation.Path
+		if operation.SpecPath != "" {
+			specPaths[key] = append(specPaths[key], operation.SpecPath)
+		}
+	}
+
+	for _, route := range router.Routes() {
+		key := route.Method + " " + route.Path
+		if selfDescribing[key] {
+			continue
+		}
+		paths, ok := specPaths[key]
+		if !ok {
+			paths = []string{route.Path}
+		}
+		for _, path := range paths {
+			if document.Paths[specPath(path)][strings.ToLower(route.Method)] == nil {
+				t.Errorf("%s is not in the OpenAPI document as %s", key, specPath(path))
+			}
+		}
+	}
+}
+
+// specPath spells gin's :param and *param segments the OpenAPI way.
+func specPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func TestUnknownRoutesAreProblems(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router, _, err := newRouter(services{}, nil, nil, config.HTTP{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the first two reach the custom-method catch-all, the last no route at all
+	for _, target := range []string{"/users/u-1/customers:merge", "/users/u-1/nowhere", "/nowhere"} {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest("POST", target, strings.NewReader(`{}`)))
+
+		if recorder.Code != http.StatusNotFound {
+			t.Errorf("%s: status = %d, want 404", target, recorder.Code)
+		}
+		if contentType := recorder.Header().Get("Content-Type"); !strings.HasPrefix(contentType, problems.ContentType) {
+			t.Errorf("%s: Content-Type = %q, want %q", target, contentType, problems.ContentType)
+		}
+		var problem problems.Problem
+		if err = json.Unmarshal(recorder.Body.Bytes(), &problem); err != nil || problem.Type != "/problems/not-found" || problem.Instance != target {
+			t.Errorf("%s: body = %s, want a not-found problem for the path", target, recorder.Body.String())
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"somdeep-demo-app/src/config"
+	"strconv"
+	"time"
+)
+
+// newHTTPServer bounds how long the server waits on clients. Exports, imports
+// and event streams are exempt from the read and write timeouts.
+func newHTTPServer(handler http.Handler, cfg config.HTTP) *http.Server {
+	return &http.Server{
+		Addr:              ":" + strconv.Itoa(cfg.Port),
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.Read_timeout,
+		ReadTimeout:       cfg.Read_timeout,
+		WriteTimeout:      cfg.Write_timeout,
+		IdleTimeout:       cfg.Idle_timeout,
+	}
+}
+
+// lifecycle stops what main started, in the reverse order it was started, so
+// nothing is stopped while something started later still uses it.
+type lifecycle struct {
+	steps []lifecycleStep
+}
+
+type lifecycleStep struct {
+	name string
+	stop func(ctx context.Context) error
+}
+
+func (l *lifecycle) onStop(name string, stop func(ctx context.Context) error) {
+	l.steps = append(l.steps, lifecycleStep{name: name, stop: stop})
+}
+
+// stop runs every step even when one fails or ctx expires, so that at least
+// the quick ones, such as closing connections, still happen.
+func (l *lifecycle) stop(ctx context.Context) {
+	for i := len(l.steps) - 1; i >= 0; i-- {
+		step := l.steps[i]
+		start := time.Now()
+		if err := step.stop(ctx); err != nil {
+			slog.Error("shutdown: step failed", "step", step.name, "error", err)
+			continue
+		}
+		slog.Info("shutdown: stopped", "step", step.name, "duration", time.Since(start))
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"somdeep-demo-app/src/config"
+	"somdeep-demo-app/src/database"
+	"somdeep-demo-app/src/tenant"
+	tenantInterfaces "somdeep-demo-app/src/tenant/interfaces"
+	tenantModules "somdeep-demo-app/src/tenant/modules"
+	"time"
+)
+
+// indexTimeout bounds creating the indexes of one repository.
+const indexTimeout = 10 * time.Second
+
+// startTenancy loads the tenant registry and keeps it fresh, returning the
+// lister the background workers go through and the resolver requests are
+// scoped with. Both are nil when tenancy is off.
+func startTenancy(tenancy *database.Tenancy, tenantRepo tenantInterfaces.TenantRepository, registry *tenantModules.Registry, cfg config.Tenancy) (tenant.Lister, *tenant.Resolver, error) {
+	if !tenancy.Enabled() {
+		return nil, nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), indexTimeout)
+	defer cancel()
+	if err := tenantRepo.EnsureIndexes(ctx); err != nil {
+		slog.Error("tenants: failed to create indexes", "error", err)
+	}
+	if err := registry.Load(ctx); err != nil {
+		return nil, nil, fmt.Errorf("loading the tenant registry: %w", err)
+	}
+	resolver, err := tenant.NewResolver(cfg, registry.Known)
+	if err != nil {
+		return nil, nil, err
+	}
+	registry.Start()
+	slog.Info("tenants: registry loaded", "mode", tenancy.Mode(), "tenants", len(registry.TenantIds()))
+	return registry, resolver, nil
+}
+
+// ensureIndexes runs every provisioner for each tenant, or once when tenancy
+// is off, so collections created before a restart get new indexes too.
+func ensureIndexes(tenants tenant.Lister, provisioners ...tenantModules.Provisioner) {
+	for _, tenantCtx := range tenant.Contexts(context.Background(), tenants) {
+		for _, provision := range provisioners {
+			ctx, cancel := context.WithTimeout(tenantCtx, indexTimeout)
+			if err := provision(ctx); err != nil {
+				slog.ErrorContext(ctx, "failed to create indexes", "error", err)
+			}
+			cancel()
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package main
+
+import (
+	"fmt"
+	"somdeep-demo-app/src/config"
+	"somdeep-demo-app/src/timeouts"
+)
+
+// serviceTimeouts applies the configured per-operation limits, a list such as
+// "default=10s,ExportCustomers=1h", over the built-in defaults.
+func serviceTimeouts(cfg config.Services) (timeouts.Timeouts, error) {
+	parsed, err := timeouts.Parse(cfg.Timeouts, timeouts.Defaults())
+	if err != nil {
+		return parsed, fmt.Errorf("invalid services.timeouts: %w", err)
+	}
+	return parsed, nil
+}
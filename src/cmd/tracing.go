@@ -0,0 +1,21 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"somdeep-demo-app/src/config"
+	"somdeep-demo-app/src/tracing"
+)
+
+// setupTracing exports spans to the configured exporter: none (the default),
+// otlp, stdout, or file.
+func setupTracing(cfg config.Tracing) (func(context.Context) error, error) {
+	shutdown, err := tracing.Setup(context.Background(), tracing.Options{
+		Exporter: cfg.Exporter,
+		File:     cfg.File,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid tracing configuration: %w", err)
+	}
+	return shutdown, nil
+}
@@ -0,0 +1,197 @@
+package config
+
+import (
+	"time"
+)
+
+// Config is everything the server is configured with. Each setting has a key,
+// its path through the json names such as "http.port", which is how the
+// configuration file and flags name it, and an environment variable.
+type Config struct {
+	HTTP     HTTP     `json:"http"`
+	Admin    Admin    `json:"admin"`
+	GRPC     GRPC     `json:"grpc"`
+	Shutdown Shutdown `json:"shutdown"`
+	Mongo    Mongo    `json:"mongo"`
+	Tenancy  Tenancy  `json:"tenancy"`
+	Log      Log      `json:"log"`
+	Tracing  Tracing  `json:"tracing"`
+	Events   Events   `json:"events"`
+	Cache    Cache    `json:"cache"`
+	GraphQL  GraphQL  `json:"graphql"`
+	Services Services `json:"services"`
+}
+
+type HTTP struct {
+	Port          int           `json:"port" env:"PORT" validate:"min=1,max=65535" usage:"port the HTTP API listens on"`
+	Read_timeout  time.Duration `json:"read_timeout" env:"HTTP_READ_TIMEOUT" validate:"min=0s" usage:"limit on reading a request, 0 for none"`
+	Write_timeout time.Duration `json:"write_timeout" env:"HTTP_WRITE_TIMEOUT" validate:"min=0s" usage:"limit on writing a response, 0 for none; streams and imports are exempt"`
+	Idle_timeout  time.Duration `json:"idle_timeout" env:"HTTP_IDLE_TIMEOUT" validate:"min=0s" usage:"how long keep-alive connections stay open between requests"`
+	// Max_body_bytes bounds the JSON request bodies read to validate them.
+	// Imports and other non-JSON bodies are streamed and not bounded by it.
+	Max_body_bytes int `json:"max_body_bytes" env:"HTTP_MAX_BODY_BYTES" validate:"min=1" usage:"limit on JSON request bodies, in bytes"`
+	// Validate_responses checks responses against the OpenAPI document. Unset,
+	// it is on in gin's test mode only.
+	Validate_responses *bool `json:"validate_responses" env:"OPENAPI_VALIDATE_RESPONSES" usage:"check responses against the OpenAPI document"`
+}
+
+// Admin guards the /admin routes, which operate on the whole deployment.
+type Admin struct {
+	Token_file string `json:"token_file" env:"ADMIN_TOKEN_FILE" validate:"omitempty,file" usage:"file holding the bearer token the /admin routes require; they are refused without one"`
+}
+
+type GRPC struct {
+	Port int `json:"port" env:"GRPC_PORT" validate:"min=1,max=65535" usage:"port the gRPC API listens on"`
+}
+
+type Shutdown struct {
+	// Drain_delay is how long readiness fails before the server stops
+	// accepting connections, so load balancers route traffic away first.
+	Drain_delay time.Duration `json:"drain_delay" env:"SHUTDOWN_DRAIN_DELAY" validate:"min=0s" usage:"how long readiness fails before connections are refused"`
+	// Timeout bounds draining in-flight requests and, separately, flushing
+	// the background workers.
+	Timeout time.Duration `json:"timeout" env:"SHUTDOWN_TIMEOUT" validate:"min=0s" usage:"limit on draining requests and on flushing workers"`
+}
+
+type Mongo struct {
+	URL      string `json:"url" env:"MONGODB_URL" secret:"true" validate:"required,startswith=mongodb" usage:"MongoDB connection string"`
+	Database string `json:"database" env:"MONGODB_DATABASE" validate:"required" usage:"database the collections live in"`
+	// Transactions forces transactions on or off. Unset, they are used when
+	// the server is part of a replica set or cluster.
+	Transactions *bool `json:"transactions" env:"MONGODB_TRANSACTIONS" usage:"use transactions (default: when the server supports them)"`
+
+	// Tuning left at zero or empty keeps what the URL says, or else the
+	// driver's default.
+	Max_pool_size            int           `json:"max_pool_size" env:"MONGODB_MAX_POOL_SIZE" validate:"min=0" usage:"most connections per server"`
+	Min_pool_size            int           `json:"min_pool_size" env:"MONGODB_MIN_POOL_SIZE" validate:"min=0" usage:"connections per server kept open while idle"`
+	Connect_timeout          time.Duration `json:"connect_timeout" env:"MONGODB_CONNECT_TIMEOUT" validate:"min=0s" usage:"limit on opening a connection"`
+	Socket_timeout           time.Duration `json:"socket_timeout" env:"MONGODB_SOCKET_TIMEOUT" validate:"min=0s" usage:"limit on each read or write on a connection"`
+	Server_selection_timeout time.Duration `json:"server_selection_timeout" env:"MONGODB_SERVER_SELECTION_TIMEOUT" validate:"min=0s" usage:"limit on finding a server for an operation"`
+	Read_preference          string        `json:"read_preference" env:"MONGODB_READ_PREFERENCE" validate:"omitempty,oneof=primary primaryPreferred secondary secondaryPreferred nearest" usage:"servers reads go to, such as primary or nearest"`
+	Read_concern             string        `json:"read_concern" env:"MONGODB_READ_CONCERN" validate:"omitempty,oneof=local available majority linearizable snapshot" usage:"read concern level, such as majority"`
+	Write_concern            string        `json:"write_concern" env:"MONGODB_WRITE_CONCERN" usage:"majority or how many servers acknowledge writes"`
+	Retry_writes             *bool         `json:"retry_writes" env:"MONGODB_RETRY_WRITES" usage:"retry writes once after a network error or failover"`
+	// Compressors is a comma-separated list of zstd, zlib and snappy, in the
+	// order they are preferred.
+	Compressors string `json:"compressors" env:"MONGODB_COMPRESSORS" usage:"wire compression to offer, such as zstd,snappy"`
+
+	// TLS is on when asked for or when any of the files is set. The client
+	// certificate and key go together.
+	TLS           bool   `json:"tls" env:"MONGODB_TLS" usage:"connect over TLS"`
+	TLS_ca_file   string `json:"tls_ca_file" env:"MONGODB_TLS_CA_FILE" validate:"omitempty,file" usage:"PEM file of the CAs server certificates are checked against"`
+	TLS_cert_file string `json:"tls_cert_file" env:"MONGODB_TLS_CERT_FILE" validate:"required_with=TLS_key_file,omitempty,file" usage:"PEM file of the client certificate"`
+	TLS_key_file  string `json:"tls_key_file" env:"MONGODB_TLS_KEY_FILE" validate:"required_with=TLS_cert_file,omitempty,file" usage:"PEM file of the client certificate's key"`
+
+	// Credentials set here replace those in the URL. The password is read
+	// from a file so it stays out of the environment and the process list.
+	Username       string `json:"username" env:"MONGODB_USERNAME" validate:"required_with=Password_file" usage:"user to authenticate as"`
+	Password_file  string `json:"password_file" env:"MONGODB_PASSWORD_FILE" validate:"omitempty,file" usage:"file holding the user's password"`
+	Auth_source    string `json:"auth_source" env:"MONGODB_AUTH_SOURCE" usage:"database the user is defined in"`
+	Auth_mechanism string `json:"auth_mechanism" env:"MONGODB_AUTH_MECHANISM" validate:"omitempty,oneof=SCRAM-SHA-1 SCRAM-SHA-256 MONGODB-X509" usage:"SCRAM-SHA-256, SCRAM-SHA-1 or MONGODB-X509"`
+
+	// The server is pinged at startup, and again after a pause that doubles
+	// each time, until it answers or the attempts run out.
+	Ping_attempts int           `json:"ping_attempts" env:"MONGODB_PING_ATTEMPTS" validate:"min=1" usage:"times the server is pinged at startup before giving up"`
+	Ping_backoff  time.Duration `json:"ping_backoff" env:"MONGODB_PING_BACKOFF" validate:"min=0s" usage:"pause after the first failed ping, doubling each time"`
+}
+
+// Tenancy hosts several tenants on one deployment. Each tenant's documents
+// live in a database of its own, or in shared collections where every
+// document carries its tenant_id.
+type Tenancy struct {
+	Mode string `json:"mode" env:"TENANCY_MODE" validate:"oneof=none database shared" usage:"none, database (one database per tenant) or shared (a tenant_id on every document)"`
+	// Resolvers is a comma-separated list of header, subdomain and token,
+	// tried in order until one names the tenant. Only the token is
+	// authenticated: list header or subdomain only behind a gateway that
+	// sets them itself, or any client can name any tenant.
+	Resolvers         string        `json:"resolvers" env:"TENANCY_RESOLVERS" usage:"where requests name their tenant: header, subdomain and token, tried in order"`
+	Header            string        `json:"header" env:"TENANCY_HEADER" validate:"required" usage:"header naming the tenant"`
+	Domain            string        `json:"domain" env:"TENANCY_DOMAIN" usage:"domain under which each tenant has a subdomain, such as api.example.com"`
+	Token_secret_file string        `json:"token_secret_file" env:"TENANCY_TOKEN_SECRET_FILE" validate:"omitempty,file" usage:"file holding the HS256 key bearer tokens are signed with"`
+	Token_claim       string        `json:"token_claim" env:"TENANCY_TOKEN_CLAIM" validate:"required" usage:"bearer token claim naming the tenant"`
+	Database_prefix   string        `json:"database_prefix" env:"TENANCY_DATABASE_PREFIX" validate:"required" usage:"prefix of the per-tenant database names"`
+	Refresh_interval  time.Duration `json:"refresh_interval" env:"TENANCY_REFRESH_INTERVAL" validate:"min=1s" usage:"how often the tenant registry is reloaded, to see tenants other instances provisioned"`
+}
+
+type Log struct {
+	Level string `json:"level" env:"LOG_LEVEL" validate:"oneof=debug info warn error" usage:"minimum log level, changeable at /admin/log-level"`
+}
+
+type Tracing struct {
+	Exporter string `json:"exporter" env:"OTEL_TRACES_EXPORTER" validate:"oneof=none otlp stdout file" usage:"where spans go: none, otlp, stdout or file"`
+	File     string `json:"file" env:"OTEL_TRACES_FILE" validate:"required_if=Exporter file" usage:"file the file exporter appends spans to"`
+}
+
+type Events struct {
+	Publisher           string `json:"publisher" env:"EVENT_PUBLISHER" validate:"oneof=memory file nats" usage:"where events go: memory, file or nats"`
+	File                string `json:"file" env:"EVENT_FILE" validate:"required_if=Publisher file" usage:"file the file publisher appends events to"`
+	NATS_url            string `json:"nats_url" env:"NATS_URL" secret:"true" validate:"required_if=Publisher nats" usage:"NATS server the nats publisher connects to"`
+	NATS_subject_prefix string `json:"nats_subject_prefix" env:"NATS_SUBJECT_PREFIX" usage:"prefix of the subjects events are published on"`
+}
+
+type Cache struct {
+	Backend         string        `json:"backend" env:"CACHE_BACKEND" validate:"oneof=memory redis none" usage:"lookup cache: memory, redis or none"`
+	TTL             time.Duration `json:"ttl" env:"CACHE_TTL" validate:"min=1ms" usage:"how long cached lookups live"`
+	Size            int           `json:"size" env:"CACHE_SIZE" validate:"min=1" usage:"entries the memory cache holds"`
+	Redis_url       string        `json:"redis_url" env:"REDIS_URL" secret:"true" validate:"required_if=Backend redis" usage:"Redis server the redis cache uses"`
+	Redis_namespace string        `json:"redis_namespace" env:"REDIS_NAMESPACE" usage:"prefix of the redis cache's keys"`
+}
+
+type GraphQL struct {
+	Max_depth      int `json:"max_depth" env:"GRAPHQL_MAX_DEPTH" validate:"min=1" usage:"deepest GraphQL query accepted"`
+	Max_complexity int `json:"max_complexity" env:"GRAPHQL_MAX_COMPLEXITY" validate:"min=1" usage:"most complex GraphQL query accepted"`
+}
+
+type Services struct {
+	// Timeouts is a list such as "default=10s,ExportCustomers=1h" applied
+	// over the built-in limits of each operation. Operations are named by
+	// service method, and an unknown name fails validation.
+	Timeouts string `json:"timeouts" env:"SERVICE_TIMEOUTS" usage:"per-operation limits, such as default=10s,ExportCustomers=1h"`
+}
+
+// Defaults is the configuration when nothing is set, except for the MongoDB
+// URL, which has to be.
+func Defaults() Config {
+	return Config{
+		HTTP: HTTP{
+			Port:           8000,
+			Read_timeout:   15 * time.Second,
+			Write_timeout:  60 * time.Second,
+			Idle_timeout:   2 * time.Minute,
+			Max_body_bytes: 1 << 20,
+		},
+		GRPC: GRPC{Port: 9090},
+		Shutdown: Shutdown{
+			Drain_delay: 5 * time.Second,
+			Timeout:     30 * time.Second,
+		},
+		Mongo: Mongo{
+			Database:      "users-project",
+			Ping_attempts: 5,
+			Ping_backoff:  time.Second,
+		},
+		Tenancy: Tenancy{
+			Mode:             "none",
+			Resolvers:        "token",
+			Header:           "X-Tenant-ID",
+			Token_claim:      "tenant",
+			Database_prefix:  "tenant_",
+			Refresh_interval: 30 * time.Second,
+		},
+		Log:     Log{Level: "info"},
+		Tracing: Tracing{Exporter: "none"},
+		Events: Events{
+			Publisher: "memory",
+			File:      "events.ndjson",
+			NATS_url:  "nats://127.0.0.1:4222",
+		},
+		Cache: Cache{
+			Backend:         "memory",
+			TTL:             time.Minute,
+			Size:            10000,
+			Redis_url:       "redis://127.0.0.1:6379",
+			Redis_namespace: "somdeep-demo-app:",
+		},
+		GraphQL: GraphQL{Max_depth: 10, Max_complexity: 2000},
+	}
+}
@@ -0,0 +1,240 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"somdeep-demo-app/src/apperrors"
+	"somdeep-demo-app/src/timeouts"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+var validate = validator.New()
+
+// Load reads the configuration. Each source overrides the ones before it:
+// the defaults, the YAML or TOML file named by -config or CONFIG_FILE, the
+// environment, then flags such as -http.port=8080. Variables in .env are added
+// to the environment unless already set there; the file is optional.
+func Load(args []string) (Config, error) {
+	cfg := Defaults()
+	if err := godotenv.Load(".env"); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return cfg, fmt.Errorf("reading .env: %w", err)
+	}
+
+	settings := settingsOf(&cfg)
+	flags := flag.NewFlagSet(filepath.Base(os.Args[0]), flag.ContinueOnError)
+	file := flags.String("config", os.Getenv("CONFIG_FILE"), "YAML or TOML configuration file")
+	flagged := map[string]string{}
+	for _, s := range settings {
+		key := s.key
+		flags.Func(key, s.usage+" ("+s.env+")", func(value string) error {
+			flagged[key] = value
+			return nil
+		})
+	}
+	if err := flags.Parse(args); err != nil {
+		return cfg, err
+	}
+	if flags.NArg() > 0 {
+		return cfg, fmt.Errorf("unexpected argument %q", flags.Arg(0))
+	}
+
+	if *file != "" {
+		values, err := readFile(*file)
+		if err != nil {
+			return cfg, err
+		}
+		for _, s := range settings {
+			if value, ok := values[s.key]; ok {
+				if err = s.set(value); err != nil {
+					return cfg, fmt.Errorf("%s: %w", *file, err)
+				}
+				delete(values, s.key)
+			}
+		}
+		for key := range values {
+			return cfg, fmt.Errorf("%s: unknown setting %q", *file, key)
+		}
+	}
+	for _, s := range settings {
+		if value := os.Getenv(s.env); value != "" {
+			if err := s.set(value); err != nil {
+				return cfg, fmt.Errorf("%s: %w", s.env, err)
+			}
+		}
+	}
+	for _, s := range settings {
+		if value, ok := flagged[s.key]; ok {
+			if err := s.set(value); err != nil {
+				return cfg, fmt.Errorf("-%s: %w", s.key, err)
+			}
+		}
+	}
+	return cfg, cfg.Validate()
+}
+
+// Validate reports every setting that is out of range, naming it by its key.
+func (c Config) Validate() error {
+	var fields []apperrors.FieldError
+	if err := validate.Struct(c); err != nil {
+		var validationError *apperrors.ValidationError
+		if !errors.As(apperrors.FromValidator("invalid configuration", err, c), &validationError) {
+			return err
+		}
+		fields = validationError.Fields
+	}
+	if _, err := timeouts.Parse(c.Services.Timeouts, timeouts.Defaults()); err != nil {
+		fields = append(fields, apperrors.FieldError{Field: "services.timeouts", Rule: "timeouts", Message: err.Error()})
+	}
+	if w := c.Mongo.Write_concern; w != "" && w != "majority" {
+		if servers, err := strconv.Atoi(w); err != nil || servers < 0 {
+			fields = append(fields, apperrors.FieldError{Field: "mongo.write_concern", Rule: "write_concern", Message: "must be majority or a number of servers"})
+		}
+	}
+	for _, compressor := range strings.Split(c.Mongo.Compressors, ",") {
+		switch strings.TrimSpace(compressor) {
+		case "", "zstd", "zlib", "snappy":
+		default:
+			fields = append(fields, apperrors.FieldError{Field: "mongo.compressors", Rule: "compressors", Message: fmt.Sprintf("has unknown compressor %q, expected zstd, zlib or snappy", compressor)})
+		}
+	}
+	if c.Tenancy.Mode != "none" {
+		fields = append(fields, c.Tenancy.validateResolvers()...)
+	}
+	if len(fields) > 0 {
+		return apperrors.Validation("invalid configuration", fields...)
+	}
+	return nil
+}
+
+// validateResolvers checks that each resolver is known and configured.
+func (t Tenancy) validateResolvers() []apperrors.FieldError {
+	var fields []apperrors.FieldError
+	for _, resolver := range strings.Split(t.Resolvers, ",") {
+		switch resolver = strings.TrimSpace(resolver); resolver {
+		case "header", "":
+		case "subdomain":
+			if t.Domain == "" {
+				fields = append(fields, apperrors.FieldError{Field: "tenancy.domain", Rule: "required_with", Message: "is required by the subdomain resolver"})
+			}
+		case "token":
+			if t.Token_secret_file == "" {
+				fields = append(fields, apperrors.FieldError{Field: "tenancy.token_secret_file", Rule: "required_with", Message: "is required by the token resolver"})
+			}
+		default:
+			fields = append(fields, apperrors.FieldError{Field: "tenancy.resolvers", Rule: "resolvers", Message: fmt.Sprintf("has unknown resolver %q, expected header, subdomain or token", resolver)})
+		}
+	}
+	return fields
+}
+
+// setting is one field of a Config.
+type setting struct {
+	key    string
+	env    string
+	usage  string
+	secret bool
+	value  reflect.Value
+}
+
+func settingsOf(cfg *Config) []setting {
+	var settings []setting
+	root := reflect.ValueOf(cfg).Elem()
+	for i := 0; i < root.NumField(); i++ {
+		section := root.Field(i)
+		sectionKey := root.Type().Field(i).Tag.Get("json")
+		for j := 0; j < section.NumField(); j++ {
+			field := section.Type().Field(j)
+			settings = append(settings, setting{
+				key:    sectionKey + "." + field.Tag.Get("json"),
+				env:    field.Tag.Get("env"),
+				usage:  field.Tag.Get("usage"),
+				secret: field.Tag.Get("secret") == "true",
+				value:  section.Field(j),
+			})
+		}
+	}
+	return settings
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// set parses value as the setting's type.
+func (s setting) set(value string) error {
+	value = strings.TrimSpace(value)
+	invalid := func() error { return fmt.Errorf("invalid %s %q", s.key, value) }
+	switch {
+	case s.value.Type() == durationType:
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return invalid()
+		}
+		s.value.SetInt(int64(parsed))
+	case s.value.Kind() == reflect.Int:
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return invalid()
+		}
+		s.value.SetInt(int64(parsed))
+	case s.value.Kind() == reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return invalid()
+		}
+		s.value.SetBool(parsed)
+	case s.value.Kind() == reflect.Pointer && s.value.Type().Elem().Kind() == reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return invalid()
+		}
+		s.value.Set(reflect.ValueOf(&parsed))
+	default:
+		s.value.SetString(value)
+	}
+	return nil
+}
+
+// readFile flattens a YAML or TOML file into keys and their values as text,
+// so they are parsed the same way as environment variables.
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var document map[string]any
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &document)
+	case ".toml":
+		err = toml.Unmarshal(data, &document)
+	default:
+		return nil, fmt.Errorf("%s: unknown configuration format %q, expected .yaml, .yml or .toml", path, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	values := map[string]string{}
+	flatten("", document, values)
+	return values, nil
+}
+
+func flatten(prefix string, document map[string]any, values map[string]string) {
+	for name, value := range document {
+		if section, ok := value.(map[string]any); ok {
+			flatten(prefix+name+".", section, values)
+			continue
+		}
+		values[prefix+name] = fmt.Sprint(value)
+	}
+}
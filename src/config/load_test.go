@@ -0,0 +1,119 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeFile writes a configuration file into a temporary directory.
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	files := map[string]string{
+		"config.yaml": "mongo:\n  url: mongodb://file:27017\n  database: file\nhttp:\n  read_timeout: 5s\ngrpc:\n  port: 7000\n",
+		"config.toml": "[mongo]\nurl = \"mongodb://file:27017\"\ndatabase = \"file\"\n[http]\nread_timeout = \"5s\"\n[grpc]\nport = 7000\n",
+	}
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			path := writeFile(t, name, content)
+			t.Setenv("CONFIG_FILE", path)
+			t.Setenv("GRPC_PORT", "7001")
+			t.Setenv("MONGODB_DATABASE", "env")
+
+			cfg, err := Load([]string{"-mongo.database=flag"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			// each setting comes from the last source that sets it
+			if cfg.HTTP.Idle_timeout != 2*time.Minute {
+				t.Errorf("http.idle_timeout = %s, want the default 2m", cfg.HTTP.Idle_timeout)
+			}
+			if cfg.HTTP.Read_timeout != 5*time.Second {
+				t.Errorf("http.read_timeout = %s, want 5s from the file", cfg.HTTP.Read_timeout)
+			}
+			if cfg.GRPC.Port != 7001 {
+				t.Errorf("grpc.port = %d, want 7001 from the environment", cfg.GRPC.Port)
+			}
+			if cfg.Mongo.Database != "flag" {
+				t.Errorf("mongo.database = %q, want flag", cfg.Mongo.Database)
+			}
+		})
+	}
+}
+
+func TestLoadFlagNamesTheFile(t *testing.T) {
+	t.Setenv("CONFIG_FILE", writeFile(t, "env.yaml", "mongo:\n  url: mongodb://env:27017\n"))
+	path := writeFile(t, "flag.yaml", "mongo:\n  url: mongodb://flag:27017\n")
+
+	cfg, err := Load([]string{"-config", path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Mongo.URL != "mongodb://flag:27017" {
+		t.Errorf("mongo.url = %q, want the one in the file named by -config", cfg.Mongo.URL)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		env  map[string]string
+		args []string
+		err  string
+	}{
+		{
+			name: "unknown setting in the file",
+			file: "mongo:\n  url: mongodb://file:27017\n  hostname: db\n",
+			err:  `unknown setting "mongo.hostname"`,
+		},
+		{
+			name: "invalid value in the environment",
+			env:  map[string]string{"MONGODB_URL": "mongodb://env:27017", "PORT": "eighty"},
+			err:  "PORT: invalid http.port",
+		},
+		{
+			name: "invalid value in a flag",
+			env:  map[string]string{"MONGODB_URL": "mongodb://env:27017"},
+			args: []string{"-http.read_timeout=soon"},
+			err:  "-http.read_timeout: invalid http.read_timeout",
+		},
+		{
+			name: "stray argument",
+			env:  map[string]string{"MONGODB_URL": "mongodb://env:27017"},
+			args: []string{"serve"},
+			err:  `unexpected argument "serve"`,
+		},
+		{
+			name: "missing URL",
+			err:  "invalid configuration",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv("CONFIG_FILE", "")
+			t.Setenv("MONGODB_URL", "")
+			if test.file != "" {
+				t.Setenv("CONFIG_FILE", writeFile(t, "config.yaml", test.file))
+			}
+			for name, value := range test.env {
+				t.Setenv(name, value)
+			}
+
+			_, err := Load(test.args)
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("error = %v, want one containing %q", err, test.err)
+			}
+		})
+	}
+}
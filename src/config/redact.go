@@ -0,0 +1,52 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// LogValue logs the configuration section by section with secrets redacted,
+// so it can be logged as it is.
+func (c Config) LogValue() slog.Value {
+	var sections []slog.Attr
+	var attrs []slog.Attr
+	section := ""
+	for _, s := range settingsOf(&c) {
+		name, key, _ := strings.Cut(s.key, ".")
+		if name != section && section != "" {
+			sections = append(sections, slog.Attr{Key: section, Value: slog.GroupValue(attrs...)})
+			attrs = nil
+		}
+		section = name
+		attrs = append(attrs, slog.String(key, s.String()))
+	}
+	sections = append(sections, slog.Attr{Key: section, Value: slog.GroupValue(attrs...)})
+	return slog.GroupValue(sections...)
+}
+
+// String is the setting's value as text, redacted when it is a secret.
+func (s setting) String() string {
+	if s.value.Kind() == reflect.Pointer && s.value.IsNil() {
+		return ""
+	}
+	value := fmt.Sprint(reflect.Indirect(s.value).Interface())
+	if s.secret {
+		return redact(value)
+	}
+	return value
+}
+
+// redact hides a secret. URLs keep everything but their password, so which
+// server the process talks to is still visible.
+func redact(value string) string {
+	if value == "" {
+		return ""
+	}
+	if parsed, err := url.Parse(value); err == nil && parsed.Scheme != "" && parsed.Host != "" {
+		return parsed.Redacted()
+	}
+	return "REDACTED"
+}
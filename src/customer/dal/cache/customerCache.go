@@ -0,0 +1,209 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	cacheInterfaces "somdeep-demo-app/src/cache/interfaces"
+	cacheModels "somdeep-demo-app/src/cache/models"
+	"somdeep-demo-app/src/customer/interfaces"
+	"somdeep-demo-app/src/customer/models"
+	eventModels "somdeep-demo-app/src/events/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// cachedCustomerRepository is a read-through cache in front of a
+// CustomerRepository. Only GetCustomerByCustomerId is cached; it follows the
+// same rules as the user cache.
+type cachedCustomerRepository struct {
+	customerRepository interfaces.CustomerRepository
+	cache              cacheInterfaces.Cache
+	ttl                time.Duration
+	metrics            *cacheModels.Metrics
+}
+
+func NewCachedCustomerRepository(customerRepository interfaces.CustomerRepository, cache cacheInterfaces.Cache, ttl time.Duration, metrics *cacheModels.Metrics) interfaces.CustomerRepository {
+	return &cachedCustomerRepository{
+		customerRepository: customerRepository,
+		cache:              cache,
+		ttl:                ttl,
+		metrics:            metrics,
+	}
+}
+
+func CustomerKey(customerId string) string {
+	return "customer:" + customerId
+}
+
+// InvalidationKeys returns the cache keys a customer event makes stale.
+func InvalidationKeys(event eventModels.Event) []string {
+	if !strings.HasPrefix(event.Type, "customer.") {
+		return nil
+	}
+	return []string{CustomerKey(event.Subject)}
+}
+
+func (r *cachedCustomerRepository) GetAllCustomers(startIndex int, recordPerPage int, ctx context.Context) (*mongo.Cursor, error) {
+	return r.customerRepository.GetAllCustomers(startIndex, recordPerPage, ctx)
+}
+
+func (r *cachedCustomerRepository) GetCustomersByUserId(userId string, startIndex int, recordPerPage int, ctx context.Context) (*mongo.Cursor, error) {
+	return r.customerRepository.GetCustomersByUserId(userId, startIndex, recordPerPage, ctx)
+}
+
+func (r *cachedCustomerRepository) GetCustomersByUserIds(userIds []string, startIndex int, recordPerPage int, ctx context.Context) (*mongo.Cursor, error) {
+	return r.customerRepository.GetCustomersByUserIds(userIds, startIndex, recordPerPage, ctx)
+}
+
+func (r *cachedCustomerRepository) GetCustomerByCustomerId(ctx context.Context, customerId string) (models.Customer, error) {
+	if mongo.SessionFromContext(ctx) != nil {
+		return r.customerRepository.GetCustomerByCustomerId(ctx, customerId)
+	}
+
+	key := CustomerKey(customerId)
+	value, found, err := r.cache.Get(ctx, key)
+	if err != nil {
+		r.metrics.Error()
+	}
+	if found {
+		var customer models.Customer
+		if err = bson.Unmarshal(value, &customer); err == nil {
+			r.metrics.Hit()
+			return customer, nil
+		}
+		r.metrics.Error()
+	}
+	r.metrics.Miss()
+
+	customer, err := r.customerRepository.GetCustomerByCustomerId(ctx, customerId)
+	if err != nil {
+		return customer, err
+	}
+	if value, err = bson.Marshal(customer); err == nil {
+		err = r.cache.Set(ctx, key, value, r.ttl)
+	}
+	if err != nil {
+		r.metrics.Error()
+	}
+	return customer, nil
+}
+
+func (r *cachedCustomerRepository) AddCustomerToMongoDb(ctx context.Context, customer models.Customer) error {
+	return r.customerRepository.AddCustomerToMongoDb(ctx, customer)
+}
+
+func (r *cachedCustomerRepository) UpdateCustomerByCustomerId(ctx context.Context, opt options.UpdateOptions, filter primitive.M, updateObject primitive.D) (*mongo.UpdateResult, error) {
+	result, err := r.customerRepository.UpdateCustomerByCustomerId(ctx, opt, filter, updateObject)
+	r.invalidate(ctx, filter)
+	return result, err
+}
+
+func (r *cachedCustomerRepository) DeleteCustomerByCustomerId(customerId string, ctx context.Context, filter primitive.M) (*mongo.DeleteResult, error) {
+	result, err := r.customerRepository.DeleteCustomerByCustomerId(customerId, ctx, filter)
+	r.invalidate(ctx, filter)
+	return result, err
+}
+
+// DeleteCustomersByUserId looks the matching customers up first, since the
+// delete itself does not say which ones it removed.
+func (r *cachedCustomerRepository) DeleteCustomersByUserId(userId string, ctx context.Context, filter primitive.M) (*mongo.DeleteResult, error) {
+	var keys []string
+	cursor, err := r.customerRepository.FindCustomers(ctx, filter, 0, 0)
+	if err == nil {
+		var customers []models.Customer
+		if err = cursor.All(ctx, &customers); err == nil {
+			for _, customer := range customers {
+				keys = append(keys, CustomerKey(customer.Customer_id))
+			}
+		}
+	}
+
+	result, deleteErr := r.customerRepository.DeleteCustomersByUserId(userId, ctx, filter)
+	if err != nil {
+		r.metrics.Error()
+		r.clear(ctx)
+	} else {
+		r.delete(ctx, keys...)
+	}
+	return result, deleteErr
+}
+
+func (r *cachedCustomerRepository) FindCustomers(ctx context.Context, filter primitive.M, startIndex int, recordPerPage int) (*mongo.Cursor, error) {
+	return r.customerRepository.FindCustomers(ctx, filter, startIndex, recordPerPage)
+}
+
+func (r *cachedCustomerRepository) GetCustomersByCustomerIds(ctx context.Context, userId string, customerIds []string) ([]models.Customer, error) {
+	return r.customerRepository.GetCustomersByCustomerIds(ctx, userId, customerIds)
+}
+
+func (r *cachedCustomerRepository) GetExistingCustomerNames(ctx context.Context, userId string, customers []models.Customer) ([]models.Customer, error) {
+	return r.customerRepository.GetExistingCustomerNames(ctx, userId, customers)
+}
+
+func (r *cachedCustomerRepository) BulkWriteCustomers(ctx context.Context, writeModels []mongo.WriteModel, ordered bool) (*mongo.BulkWriteResult, error) {
+	result, err := r.customerRepository.BulkWriteCustomers(ctx, writeModels, ordered)
+
+	var keys []string
+	for _, model := range writeModels {
+		var filter interface{}
+		switch m := model.(type) {
+		case *mongo.InsertOneModel:
+			continue
+		case *mongo.UpdateOneModel:
+			filter = m.Filter
+		case *mongo.DeleteOneModel:
+			filter = m.Filter
+		}
+		customerId, ok := filterCustomerId(filter)
+		if !ok {
+			r.clear(ctx)
+			return result, err
+		}
+		keys = append(keys, CustomerKey(customerId))
+	}
+	r.delete(ctx, keys...)
+	return result, err
+}
+
+// invalidate drops the customer a write filter targets, or the whole cache
+// when the filter does not name a single customer.
+func (r *cachedCustomerRepository) invalidate(ctx context.Context, filter primitive.M) {
+	if customerId, ok := filterCustomerId(filter); ok {
+		r.delete(ctx, CustomerKey(customerId))
+		return
+	}
+	r.clear(ctx)
+}
+
+func (r *cachedCustomerRepository) delete(ctx context.Context, keys ...string) {
+	if len(keys) == 0 {
+		return
+	}
+	if err := r.cache.Delete(ctx, keys...); err != nil {
+		r.metrics.Error()
+		return
+	}
+	r.metrics.Invalidated(len(keys))
+}
+
+func (r *cachedCustomerRepository) clear(ctx context.Context) {
+	if err := r.cache.Clear(ctx); err != nil {
+		r.metrics.Error()
+		return
+	}
+	r.metrics.Invalidated(1)
+}
+
+func filterCustomerId(filter interface{}) (string, bool) {
+	m, ok := filter.(primitive.M)
+	if !ok {
+		return "", false
+	}
+	customerId, ok := m["customer_id"].(string)
+	return customerId, ok
+}
@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"somdeep-demo-app/src/cache/broker"
+	cacheModels "somdeep-demo-app/src/cache/models"
+	"somdeep-demo-app/src/cache/stores"
+	"somdeep-demo-app/src/customer/interfaces"
+	"somdeep-demo-app/src/customer/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// customerStore is a CustomerRepository that serves customers from memory
+// and counts the reads that reach it.
+type customerStore struct {
+	interfaces.CustomerRepository
+
+	mu        sync.Mutex
+	customers map[string]models.Customer
+	reads     int
+}
+
+func (s *customerStore) GetCustomerByCustomerId(ctx context.Context, customerId string) (models.Customer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reads++
+	customer, ok := s.customers[customerId]
+	if !ok {
+		return customer, mongo.ErrNoDocuments
+	}
+	return customer, nil
+}
+
+func (s *customerStore) BulkWriteCustomers(ctx context.Context, writeModels []mongo.WriteModel, ordered bool) (*mongo.BulkWriteResult, error) {
+	return &mongo.BulkWriteResult{}, nil
+}
+
+func TestCachedCustomerRepositoryInvalidatesBulkWrites(t *testing.T) {
+	server, err := broker.NewRedisStandIn("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve()
+	defer server.Close()
+	redis, err := stores.NewRedis(server.Addr(), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer redis.Close()
+
+	ctx := context.Background()
+	store := &customerStore{customers: map[string]models.Customer{}}
+	for _, customerId := range []string{"c-1", "c-2", "c-3"} {
+		store.customers[customerId] = models.Customer{Customer_id: customerId, User_id: "u-1"}
+	}
+	metrics := cacheModels.NewMetrics("customers")
+	repository := NewCachedCustomerRepository(store, redis, time.Minute, metrics)
+
+	for _, customerId := range []string{"c-1", "c-2", "c-3", "c-1", "c-2", "c-3"} {
+		if _, err = repository.GetCustomerByCustomerId(ctx, customerId); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if stats := metrics.Snapshot(); stats.Hits != 3 || stats.Misses != 3 {
+		t.Fatalf("stats = %+v, want 3 hits and 3 misses", stats)
+	}
+
+	// inserts leave the cache alone, updates and deletes drop their customer
+	_, err = repository.BulkWriteCustomers(ctx, []mongo.WriteModel{
+		mongo.NewInsertOneModel().SetDocument(models.Customer{Customer_id: "c-4"}),
+		mongo.NewUpdateOneModel().SetFilter(primitive.M{"customer_id": "c-1", "user_id": "u-1"}).SetUpdate(primitive.M{}),
+		mongo.NewDeleteOneModel().SetFilter(primitive.M{"customer_id": "c-2", "user_id": "u-1"}),
+	}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for customerId, cached := range map[string]bool{"c-1": false, "c-2": false, "c-3": true} {
+		if _, found, _ := redis.Get(ctx, CustomerKey(customerId)); found != cached {
+			t.Errorf("%s cached = %v after the bulk write, want %v", customerId, found, cached)
+		}
+	}
+	if stats := metrics.Snapshot(); stats.Invalidations != 2 {
+		t.Errorf("invalidations = %d, want 2", stats.Invalidations)
+	}
+
+	// a write model without a customer_id filter clears everything
+	_, err = repository.BulkWriteCustomers(ctx, []mongo.WriteModel{
+		mongo.NewDeleteOneModel().SetFilter(primitive.M{"user_id": "u-1"}),
+	}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, found, _ := redis.Get(ctx, CustomerKey("c-3")); found {
+		t.Error("c-3 is still cached after a bulk write the cache could not attribute")
+	}
+}
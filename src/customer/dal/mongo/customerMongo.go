@@ -15,11 +15,11 @@ import (
 )
 
 type customerRepository struct {
-	customerCollection *mongo.Collection
+	customerCollection *database.Collection
 }
 
-func NewCustomerRepository(client *mongo.Client) interfaces.CustomerRepository {
-	customerCollection := database.OpenCollection(client, "customer")
+func NewCustomerRepository(tenancy *database.Tenancy) interfaces.CustomerRepository {
+	customerCollection := database.OpenTenantCollection(tenancy, "customer")
 	return &customerRepository{
 		customerCollection: customerCollection,
 	}
@@ -57,6 +57,26 @@ func (r *customerRepository) GetCustomersByUserId(userId string, startIndex int,
 	return result, err
 }
 
+// GetCustomersByUserIds pages each user's customers the same way
+// GetCustomersByUserId does, returning one {user_id, total_count, items}
+// document per user that has any.
+func (r *customerRepository) GetCustomersByUserIds(userIds []string, startIndex int, recordPerPage int, ctx context.Context) (result *mongo.Cursor, err error) {
+	matchStage := bson.D{{Key: "$match", Value: bson.M{"user_id": bson.M{"$in": userIds}}}}
+	groupStage := bson.D{{Key: "$group", Value: bson.D{{Key: "_id", Value: "$user_id"}, {Key: "total_count", Value: bson.D{{Key: "$sum", Value: 1}}}, {Key: "data", Value: bson.D{{Key: "$push", Value: "$$ROOT"}}}}}}
+	projectStage := bson.D{
+		{
+			Key: "$project", Value: bson.D{
+				{Key: "_id", Value: 0},
+				{Key: "user_id", Value: "$_id"},
+				{Key: "total_count", Value: 1},
+				{Key: "items", Value: bson.D{{Key: "$slice", Value: []interface{}{"$data", startIndex, recordPerPage}}}},
+			}}}
+	result, err = r.customerCollection.Aggregate(ctx, mongo.Pipeline{
+		matchStage, groupStage, projectStage})
+
+	return result, err
+}
+
 func (r *customerRepository) GetCustomerByCustomerId(ctx context.Context, customerId string) (customer models.Customer, result error) {
 	result = r.customerCollection.FindOne(ctx, bson.M{"customer_id": customerId}).Decode(&customer)
 	return customer, result
@@ -88,3 +108,55 @@ func (r *customerRepository) DeleteCustomersByUserId(customerId string, ctx cont
 	result, err = r.customerCollection.DeleteMany(ctx, filter)
 	return result, err
 }
+
+func (r *customerRepository) GetCustomersByCustomerIds(ctx context.Context, userId string, customerIds []string) (customers []models.Customer, err error) {
+	filter := bson.M{"user_id": userId, "customer_id": bson.M{"$in": customerIds}}
+
+	cursor, err := r.customerCollection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &customers); err != nil {
+		return nil, err
+	}
+	return customers, nil
+}
+
+func (r *customerRepository) BulkWriteCustomers(ctx context.Context, writeModels []mongo.WriteModel, ordered bool) (result *mongo.BulkWriteResult, err error) {
+	return r.customerCollection.BulkWrite(ctx, writeModels, options.BulkWrite().SetOrdered(ordered))
+}
+
+func (r *customerRepository) GetExistingCustomerNames(ctx context.Context, userId string, customers []models.Customer) (existing []models.Customer, err error) {
+	if len(customers) == 0 {
+		return nil, nil
+	}
+
+	names := make([]bson.M, 0, len(customers))
+	for _, customer := range customers {
+		names = append(names, bson.M{"first_name": customer.First_name, "last_name": customer.Last_name})
+	}
+	filter := bson.M{"user_id": userId, "$or": names}
+	opts := options.Find().SetProjection(bson.M{"_id": 0, "customer_id": 1, "first_name": 1, "last_name": 1})
+
+	cursor, err := r.customerCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &existing); err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+// FindCustomers returns a plain cursor in _id order, which the default index
+// serves without a blocking sort, so callers can stream large result sets
+// instead of buffering them. A recordPerPage of 0 means no limit.
+func (r *customerRepository) FindCustomers(ctx context.Context, filter primitive.M, startIndex int, recordPerPage int) (result *mongo.Cursor, err error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetSkip(int64(startIndex))
+	if recordPerPage > 0 {
+		opts.SetLimit(int64(recordPerPage))
+	}
+	return r.customerCollection.Find(ctx, filter, opts)
+}
@@ -16,9 +16,14 @@ type Res struct {
 type CustomerRepository interface {
 	GetAllCustomers(startIndex int, recordPerPage int, ctx context.Context) (result *mongo.Cursor, err error)
 	GetCustomersByUserId(userId string, startIndex int, recordPerPage int, ctx context.Context) (result *mongo.Cursor, err error)
+	GetCustomersByUserIds(userIds []string, startIndex int, recordPerPage int, ctx context.Context) (result *mongo.Cursor, err error)
 	GetCustomerByCustomerId(ctx context.Context, customerId string) (customer models.Customer, result error)
 	AddCustomerToMongoDb(ctx context.Context, customer models.Customer) (insertErr error)
 	UpdateCustomerByCustomerId(ctx context.Context, opt options.UpdateOptions, filter primitive.M, updateObject primitive.D) (result *mongo.UpdateResult, err error)
 	DeleteCustomerByCustomerId(customerId string, ctx context.Context, filter primitive.M) (result *mongo.DeleteResult, err error)
 	DeleteCustomersByUserId(customerId string, ctx context.Context, filter primitive.M) (result *mongo.DeleteResult, err error)
+	FindCustomers(ctx context.Context, filter primitive.M, startIndex int, recordPerPage int) (result *mongo.Cursor, err error)
+	GetCustomersByCustomerIds(ctx context.Context, userId string, customerIds []string) (customers []models.Customer, err error)
+	GetExistingCustomerNames(ctx context.Context, userId string, customers []models.Customer) (existing []models.Customer, err error)
+	BulkWriteCustomers(ctx context.Context, writeModels []mongo.WriteModel, ordered bool) (result *mongo.BulkWriteResult, err error)
 }
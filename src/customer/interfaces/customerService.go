@@ -1,6 +1,12 @@
 package interfaces
 
-import "somdeep-demo-app/src/customer/models"
+import (
+	"context"
+	"io"
+	auditModels "somdeep-demo-app/src/audit/models"
+	"somdeep-demo-app/src/customer/models"
+	eventModels "somdeep-demo-app/src/events/models"
+)
 
 type Response struct {
 	Status  int    `json:"status"`
@@ -10,11 +16,17 @@ type Response struct {
 }
 
 type CustomerService interface {
-	GetAllCustomers(recordPerPage int, page int, startIndex int) (response Response, err error)
-	GetCustomersByUserId(userId string, recordPerPage int, page int, startIndex int) (response Response, err error)
-	GetCustomerByCustomerId(userId string, customerId string) (response Response, err error)
-	AddCustomerByUserId(userId string, customer models.Customer) (response Response, err error)
-	UpdateCustomerByCustomerId(userId string, customerId string, customer models.Customer) (response Response, err error)
-	DeleteCustomerByCustomerId(customerId string) (response Response, err error)
-	DeleteCustomersByUserId(userId string) (response Response, err error)
+	GetAllCustomers(ctx context.Context, recordPerPage int, page int, startIndex int) (response Response, err error)
+	GetCustomersByUserId(ctx context.Context, userId string, recordPerPage int, page int, startIndex int) (response Response, err error)
+	GetCustomersByUserIds(ctx context.Context, userIds []string, recordPerPage int, page int, startIndex int) (response Response, err error)
+	GetCustomerByCustomerId(ctx context.Context, userId string, customerId string) (response Response, err error)
+	AddCustomerByUserId(ctx context.Context, actor auditModels.Actor, userId string, customer models.Customer) (response Response, err error)
+	UpdateCustomerByCustomerId(ctx context.Context, actor auditModels.Actor, userId string, customerId string, customer models.Customer) (response Response, err error)
+	DeleteCustomerByCustomerId(ctx context.Context, actor auditModels.Actor, userId string, customerId string) (response Response, err error)
+	DeleteCustomersByUserId(ctx context.Context, actor auditModels.Actor, userId string) (response Response, err error)
+	BatchCustomersByUserId(ctx context.Context, actor auditModels.Actor, userId string, batch models.CustomerBatchRequest) (response Response, err error)
+	ExportCustomers(ctx context.Context, filter models.CustomerExportFilter, format string, writer io.Writer) (response Response, err error)
+	StreamCustomers(ctx context.Context, userId string, send func(customer models.Customer) error) (response Response, err error)
+	ImportCustomersByUserId(ctx context.Context, actor auditModels.Actor, userId string, reader io.Reader, format string, dryRun bool) (response Response, err error)
+	StreamCustomerChanges(ctx context.Context, userId string, lastEventId string) (changes <-chan eventModels.StreamedEvent, response Response, err error)
 }
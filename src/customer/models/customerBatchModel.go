@@ -0,0 +1,35 @@
+package models
+
+const (
+	BatchOpCreate = "create"
+	BatchOpUpdate = "update"
+	BatchOpDelete = "delete"
+)
+
+type CustomerBatchOperation struct {
+	Op          string   `json:"op" validate:"required,oneof=create update delete"`
+	Customer_id string   `json:"customer_id" validate:"required_unless=Op create"`
+	Customer    Customer `json:"customer"`
+}
+
+type CustomerBatchRequest struct {
+	Transactional bool                     `json:"transactional"`
+	Operations    []CustomerBatchOperation `json:"operations" validate:"required,min=1"`
+}
+
+type CustomerBatchItemResult struct {
+	Index       int    `json:"index"`
+	Op          string `json:"op"`
+	Customer_id string `json:"customer_id,omitempty"`
+	Status      int    `json:"status"`
+	Error       string `json:"error,omitempty"`
+}
+
+type CustomerBatchResult struct {
+	Transactional bool                      `json:"transactional"`
+	Inserted      int64                     `json:"inserted"`
+	Modified      int64                     `json:"modified"`
+	Deleted       int64                     `json:"deleted"`
+	Failed        int                       `json:"failed"`
+	Results       []CustomerBatchItemResult `json:"results"`
+}
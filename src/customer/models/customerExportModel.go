@@ -0,0 +1,11 @@
+package models
+
+// CustomerExportColumns is the fixed column order used by every export format.
+var CustomerExportColumns = []string{"customer_id", "user_id", "first_name", "last_name", "created_at", "updated_at"}
+
+type CustomerExportFilter struct {
+	User_id       string
+	StartIndex    int
+	RecordPerPage int
+	Fields        []string
+}
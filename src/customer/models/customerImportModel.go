@@ -0,0 +1,29 @@
+package models
+
+const (
+	ImportFormatCSV    = "csv"
+	ImportFormatNDJSON = "ndjson"
+)
+
+type CustomerImportRowError struct {
+	Row   int    `json:"row"`
+	Field string `json:"field,omitempty"`
+	Rule  string `json:"rule,omitempty"`
+	Error string `json:"error"`
+}
+
+// CustomerImportReport accounts for every row read: TotalRows is Valid,
+// Invalid, Duplicates and Failed together. Valid rows are the ones written, or
+// the ones that would be on a dry run.
+type CustomerImportReport struct {
+	DryRun         bool                     `json:"dry_run"`
+	Format         string                   `json:"format"`
+	TotalRows      int                      `json:"total_rows"`
+	Valid          int                      `json:"valid"`
+	Invalid        int                      `json:"invalid"`
+	Duplicates     int                      `json:"duplicates"`
+	Failed         int                      `json:"failed"`
+	Inserted       int                      `json:"inserted"`
+	IgnoredColumns []string                 `json:"ignored_columns,omitempty"`
+	Errors         []CustomerImportRowError `json:"errors"`
+}
@@ -15,3 +15,10 @@ type Customer struct {
 	Created_at  time.Time          `json:"created_at"`
 	Updated_at  time.Time          `json:"updated_at"`
 }
+
+// CustomerRef identifies the customer a create or delete applied to. Deleting
+// every customer of a user leaves Customer_id empty.
+type CustomerRef struct {
+	User_id     string `json:"user_id"`
+	Customer_id string `json:"customer_id,omitempty"`
+}
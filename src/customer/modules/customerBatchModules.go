@@ -0,0 +1,322 @@
+package modules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"somdeep-demo-app/src/apperrors"
+	auditModels "somdeep-demo-app/src/audit/models"
+	"somdeep-demo-app/src/customer/interfaces"
+	"somdeep-demo-app/src/customer/models"
+	"somdeep-demo-app/src/database"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MaxBatchOperations caps the number of operations accepted in one batch request.
+const MaxBatchOperations = 1000
+
+func (s *customerService) BatchCustomersByUserId(ctx context.Context, actor auditModels.Actor, userId string, batch models.CustomerBatchRequest) (response interfaces.Response, err error) {
+	ctx, cancel := s.timeouts.Context(ctx, "BatchCustomersByUserId")
+	defer cancel()
+
+	var res interfaces.Response
+
+	_, err = s.userRepository.GetUserByUserId(ctx, userId)
+	if err != nil {
+		res.Error = err.Error()
+		res.Message = "The user associated with customer is not present or is deleted"
+		res.Data = nil
+		res.Status, err = database.ClassifyError(err, res.Message)
+		return res, err
+	}
+
+	if batch.Transactional && !s.transactor.Enabled() {
+		res.Status = http.StatusBadRequest
+		res.Error = "transactional batches need MongoDB transactions, which are disabled"
+		res.Message = "Validation Error"
+		res.Data = nil
+		return res, apperrors.Validation(res.Error)
+	}
+
+	if len(batch.Operations) == 0 || len(batch.Operations) > MaxBatchOperations {
+		res.Status = http.StatusBadRequest
+		res.Error = fmt.Sprintf("operations must contain between 1 and %d items", MaxBatchOperations)
+		res.Message = "Validation Error"
+		res.Data = nil
+		return res, apperrors.Validation(res.Error)
+	}
+
+	result := models.CustomerBatchResult{
+		Transactional: batch.Transactional,
+		Results:       make([]models.CustomerBatchItemResult, len(batch.Operations)),
+	}
+
+	// validate every item up front so that the report covers the whole batch
+
+	seen := map[string]bool{}
+	var lookupIds []string
+	for i, op := range batch.Operations {
+		item := &result.Results[i]
+		item.Index = i
+		item.Op = op.Op
+		item.Customer_id = op.Customer_id
+
+		if validationError := validateBatchOperation(op); validationError != nil {
+			item.Status = http.StatusBadRequest
+			item.Error = validationError.Error()
+			continue
+		}
+		if op.Op == models.BatchOpCreate {
+			continue
+		}
+		if seen[op.Customer_id] {
+			item.Status = http.StatusBadRequest
+			item.Error = "customer_id appears more than once in the batch"
+			continue
+		}
+		seen[op.Customer_id] = true
+		lookupIds = append(lookupIds, op.Customer_id)
+	}
+
+	// updates and deletes may only target customers that belong to this user
+
+	found := map[string]models.Customer{}
+	if len(lookupIds) > 0 {
+		existing, lookupErr := s.customerRepository.GetCustomersByCustomerIds(ctx, userId, lookupIds)
+		if lookupErr != nil {
+			res.Error = lookupErr.Error()
+			res.Message = "Error occured while looking up customers"
+			res.Data = nil
+			res.Status, err = database.ClassifyError(lookupErr, res.Message)
+			return res, err
+		}
+		for _, customer := range existing {
+			found[customer.Customer_id] = customer
+		}
+		for i, op := range batch.Operations {
+			item := &result.Results[i]
+			if _, exists := found[op.Customer_id]; item.Status == 0 && op.Op != models.BatchOpCreate && !exists {
+				item.Status = http.StatusNotFound
+				item.Error = "Customer not found or is already deleted"
+			}
+		}
+	}
+
+	if batch.Transactional && countFailed(result.Results) > 0 {
+		abortPending(result.Results, "batch aborted because another operation is invalid")
+		result.Failed = countFailed(result.Results)
+		res.Status = http.StatusBadRequest
+		res.Error = "NA"
+		res.Message = "Batch rejected, no changes were written"
+		res.Data = result
+		return res, apperrors.Validation(res.Message)
+	}
+
+	now := time.Now()
+	var writeModels []mongo.WriteModel
+	var modelIndex []int
+	changes := map[int]customerChange{}
+	for i, op := range batch.Operations {
+		item := &result.Results[i]
+		if item.Status != 0 {
+			continue
+		}
+
+		switch op.Op {
+		case models.BatchOpCreate:
+			customer := op.Customer
+			customer.Created_at = now
+			customer.Updated_at = now
+			customer.ID = primitive.NewObjectID()
+			customer.Customer_id = uuid.New().String()
+			customer.User_id = userId
+			item.Customer_id = customer.Customer_id
+			writeModels = append(writeModels, mongo.NewInsertOneModel().SetDocument(customer))
+			changes[i] = customerChange{Action: auditModels.ActionCreate, After: &customer}
+		case models.BatchOpUpdate:
+			writeModels = append(writeModels, mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"customer_id": op.Customer_id, "user_id": userId}).
+				SetUpdate(bson.D{{Key: "$set", Value: customerUpdateObject(op.Customer, now)}}))
+			before := found[op.Customer_id]
+			after := applyCustomerUpdate(before, op.Customer, now)
+			changes[i] = customerChange{Action: auditModels.ActionUpdate, Before: &before, After: &after}
+		case models.BatchOpDelete:
+			writeModels = append(writeModels, mongo.NewDeleteOneModel().
+				SetFilter(bson.M{"customer_id": op.Customer_id, "user_id": userId}))
+			before := found[op.Customer_id]
+			changes[i] = customerChange{Action: auditModels.ActionDelete, Before: &before}
+		}
+		modelIndex = append(modelIndex, i)
+	}
+
+	if len(writeModels) == 0 {
+		result.Failed = countFailed(result.Results)
+		res.Status = http.StatusBadRequest
+		res.Error = "NA"
+		res.Message = "No valid operations in batch"
+		res.Data = result
+		return res, errors.New(res.Message)
+	}
+
+	modelChanges := make([]customerChange, len(modelIndex))
+	for j, i := range modelIndex {
+		modelChanges[j] = changes[i]
+	}
+
+	bulkResult, failedModels, writeErr := s.bulkWriteChanges(ctx, actor, writeModels, modelChanges, batch.Transactional)
+	if writeErr != nil {
+		// a transactional batch fails as a whole; the operations that caused
+		// it are named when the client can act on them
+		var bulkErr mongo.BulkWriteException
+		blamed := errors.As(writeErr, &bulkErr)
+		if blamed {
+			for _, writeError := range bulkErr.WriteErrors {
+				failure := classifyWriteError(ctx, writeError)
+				item := &result.Results[modelIndex[writeError.Index]]
+				item.Status = failure.Status
+				item.Error = failure.Message
+			}
+		} else {
+			slog.ErrorContext(ctx, "customer batch write failed", "operations", len(writeModels), "error", writeErr)
+		}
+		for _, i := range modelIndex {
+			if item := &result.Results[i]; item.Status == 0 {
+				item.Status = http.StatusInternalServerError
+				item.Error = writeFailed
+				if batch.Transactional && blamed {
+					item.Status = http.StatusFailedDependency
+					item.Error = "batch aborted because another operation failed"
+				}
+			}
+		}
+		result.Failed = countFailed(result.Results)
+		res.Error = writeErr.Error()
+		res.Message = "Batch write failed"
+		res.Data = result
+		res.Status, err = database.ClassifyError(writeErr, res.Message)
+		return res, err
+	}
+
+	for j, i := range modelIndex {
+		item := &result.Results[i]
+		if failure, failed := failedModels[j]; failed {
+			item.Status = failure.Status
+			item.Error = failure.Message
+			continue
+		}
+		if item.Op == models.BatchOpCreate {
+			item.Status = http.StatusCreated
+		} else {
+			item.Status = http.StatusOK
+		}
+	}
+
+	if bulkResult != nil {
+		result.Inserted = bulkResult.InsertedCount
+		result.Modified = bulkResult.ModifiedCount
+		result.Deleted = bulkResult.DeletedCount
+	}
+	result.Failed = countFailed(result.Results)
+
+	if result.Failed > 0 {
+		res.Status = http.StatusMultiStatus
+		res.Error = "NA"
+		res.Message = "Batch processed with partial failures"
+		res.Data = result
+		return res, nil
+	}
+
+	res.Status = http.StatusOK
+	res.Error = "NA"
+	res.Message = "Batch processed successfully"
+	res.Data = result
+	return res, nil
+}
+
+func validateBatchOperation(op models.CustomerBatchOperation) error {
+	if err := validate.StructExcept(op, "Customer"); err != nil {
+		return err
+	}
+
+	switch op.Op {
+	case models.BatchOpCreate:
+		return validate.Struct(op.Customer)
+	case models.BatchOpUpdate:
+		var fields []string
+		if op.Customer.First_name != nil {
+			fields = append(fields, "First_name")
+		}
+		if op.Customer.Last_name != nil {
+			fields = append(fields, "Last_name")
+		}
+		if len(fields) == 0 {
+			return errors.New("update requires at least one of first_name or last_name")
+		}
+		return validate.StructPartial(op.Customer, fields...)
+	}
+	return nil
+}
+
+func customerUpdateObject(customer models.Customer, updatedAt time.Time) primitive.D {
+	var updateObject primitive.D
+
+	if customer.First_name != nil {
+		updateObject = append(updateObject, bson.E{Key: "first_name", Value: customer.First_name})
+	}
+
+	if customer.Last_name != nil {
+		updateObject = append(updateObject, bson.E{Key: "last_name", Value: customer.Last_name})
+	}
+
+	updateObject = append(updateObject, bson.E{Key: "updated_at", Value: updatedAt})
+	return updateObject
+}
+
+func applyCustomerUpdate(customer models.Customer, update models.Customer, updatedAt time.Time) models.Customer {
+	if update.First_name != nil {
+		customer.First_name = update.First_name
+	}
+	if update.Last_name != nil {
+		customer.Last_name = update.Last_name
+	}
+	customer.Updated_at = updatedAt
+	return customer
+}
+
+// customerChanged reports whether applying the update to customer changes any
+// of its names.
+func customerChanged(customer models.Customer, update models.Customer) bool {
+	if update.First_name != nil && (customer.First_name == nil || *customer.First_name != *update.First_name) {
+		return true
+	}
+	if update.Last_name != nil && (customer.Last_name == nil || *customer.Last_name != *update.Last_name) {
+		return true
+	}
+	return false
+}
+
+func countFailed(results []models.CustomerBatchItemResult) (failed int) {
+	for _, item := range results {
+		if item.Status >= http.StatusBadRequest {
+			failed++
+		}
+	}
+	return failed
+}
+
+func abortPending(results []models.CustomerBatchItemResult, reason string) {
+	for i := range results {
+		if results[i].Status == 0 {
+			results[i].Status = http.StatusFailedDependency
+			results[i].Error = reason
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package modules
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"net/http"
+	"strings"
+	"testing"
+
+	auditModels "somdeep-demo-app/src/audit/models"
+	"somdeep-demo-app/src/customer/models"
+	"somdeep-demo-app/src/timeouts"
+	userInterfaces "somdeep-demo-app/src/user/interfaces"
+	userModels "somdeep-demo-app/src/user/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	duplicateMessage = "E11000 duplicate key error collection: app.customers index: customer_id_1 dup key"
+	driverMessage    = "(InternalError) WiredTiger error in the storage engine on mongo-0:27017"
+)
+
+type stubUserRepository struct {
+	userInterfaces.UserRepository
+}
+
+func (stubUserRepository) GetUserByUserId(ctx context.Context, userId string) (userModels.User, error) {
+	return userModels.User{User_id: userId}, nil
+}
+
+// batchStore writes batches like MongoDB does: inserts of a customer named in
+// failures fail with that error, and an ordered write stops at the first one.
+type batchStore struct {
+	customerStore
+	failures map[string]mongo.WriteError
+}
+
+func (s *batchStore) GetCustomersByCustomerIds(ctx context.Context, userId string, customerIds []string) ([]models.Customer, error) {
+	var customers []models.Customer
+	for _, customerId := range customerIds {
+		if customer, ok := s.customers[customerId]; ok && customer.User_id == userId {
+			customers = append(customers, customer)
+		}
+	}
+	return customers, nil
+}
+
+func (s *batchStore) GetExistingCustomerNames(ctx context.Context, userId string, customers []models.Customer) ([]models.Customer, error) {
+	names := map[string]bool{}
+	for _, customer := range customers {
+		names[customerNameKey(customer)] = true
+	}
+	var existing []models.Customer
+	for _, customer := range s.customers {
+		if customer.User_id == userId && names[customerNameKey(customer)] {
+			existing = append(existing, customer)
+		}
+	}
+	return existing, nil
+}
+
+func (s *batchStore) BulkWriteCustomers(ctx context.Context, writeModels []mongo.WriteModel, ordered bool) (*mongo.BulkWriteResult, error) {
+	result := &mongo.BulkWriteResult{}
+	var bulkErr mongo.BulkWriteException
+	for i, model := range writeModels {
+		switch m := model.(type) {
+		case *mongo.InsertOneModel:
+			customer := m.Document.(models.Customer)
+			if failure, fail := s.failures[*customer.First_name]; fail {
+				failure.Index = i
+				bulkErr.WriteErrors = append(bulkErr.WriteErrors, mongo.BulkWriteError{WriteError: failure, Request: model})
+				if ordered {
+					return result, bulkErr
+				}
+				continue
+			}
+			s.customers[customer.Customer_id] = customer
+			result.InsertedCount++
+		case *mongo.UpdateOneModel:
+			filter := m.Filter.(bson.M)
+			if customer, ok := s.customers[filter["customer_id"].(string)]; ok && customer.User_id == filter["user_id"] {
+				result.ModifiedCount++
+			}
+		case *mongo.DeleteOneModel:
+			filter := m.Filter.(bson.M)
+			if customer, ok := s.customers[filter["customer_id"].(string)]; ok && customer.User_id == filter["user_id"] {
+				delete(s.customers, customer.Customer_id)
+				result.DeletedCount++
+			}
+		}
+	}
+	if len(bulkErr.WriteErrors) > 0 {
+		return result, bulkErr
+	}
+	return result, nil
+}
+
+// rollbackTransactor undoes the store writes, audit entries and events of a
+// transaction that fails.
+type rollbackTransactor struct {
+	store     *batchStore
+	audit     *stubAuditService
+	publisher *stubPublisher
+}
+
+func (t rollbackTransactor) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	customers := maps.Clone(t.store.customers)
+	entries, events := len(t.audit.entries), len(t.publisher.events)
+	err := fn(ctx)
+	if err != nil {
+		t.store.customers = customers
+		t.audit.entries = t.audit.entries[:entries]
+		t.publisher.events = t.publisher.events[:events]
+	}
+	return err
+}
+
+func (rollbackTransactor) Enabled() bool { return true }
+
+// failingAuditService fails to record anything, which aborts the transaction
+// of the change.
+type failingAuditService struct {
+	stubAuditService
+}
+
+func (s *failingAuditService) Record(ctx context.Context, actor auditModels.Actor, entries ...auditModels.AuditEntry) error {
+	return errors.New("(NotWritablePrimary) not primary on mongo-1:27017")
+}
+
+func batchFixture() (*batchStore, *stubAuditService, *stubPublisher) {
+	store := &batchStore{
+		customerStore: customerStore{customers: map[string]models.Customer{
+			"c-1": {Customer_id: "c-1", User_id: "u-1", First_name: ptr("Ada")},
+		}},
+		failures: map[string]mongo.WriteError{
+			"Dup":  {Code: 11000, Message: duplicateMessage},
+			"Boom": {Code: 1, Message: driverMessage},
+		},
+	}
+	return store, &stubAuditService{}, &stubPublisher{}
+}
+
+func create(firstName string) models.CustomerBatchOperation {
+	return models.CustomerBatchOperation{Op: models.BatchOpCreate, Customer: models.Customer{First_name: ptr(firstName), Last_name: ptr("Lovelace")}}
+}
+
+func ptr(value string) *string {
+	return &value
+}
+
+// assertNoLeak fails when a driver message reached an item of the result.
+func assertNoLeak(t *testing.T, result models.CustomerBatchResult) {
+	t.Helper()
+	for _, item := range result.Results {
+		if strings.Contains(item.Error, "E11000") || strings.Contains(item.Error, "mongo-") {
+			t.Errorf("item %d error %q carries driver details", item.Index, item.Error)
+		}
+	}
+}
+
+func statuses(result models.CustomerBatchResult) []int {
+	var statuses []int
+	for _, item := range result.Results {
+		statuses = append(statuses, item.Status)
+	}
+	return statuses
+}
+
+func TestBatchWithPartialFailures(t *testing.T) {
+	store, audit, publisher := batchFixture()
+	service := NewCustomerService(store, stubUserRepository{}, audit, publisher, nil, rollbackTransactor{store, audit, publisher}, timeouts.Defaults())
+
+	response, err := service.BatchCustomersByUserId(context.Background(), auditModels.Actor{}, "u-1", models.CustomerBatchRequest{
+		Operations: []models.CustomerBatchOperation{
+			create("Grace"),
+			create("Dup"),
+			{Op: models.BatchOpUpdate, Customer_id: "c-1", Customer: models.Customer{Last_name: ptr("Byron")}},
+			{Op: models.BatchOpDelete, Customer_id: "c-missing"},
+			create("Boom"),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Status != http.StatusMultiStatus {
+		t.Fatalf("status = %d, want 207", response.Status)
+	}
+	result := response.Data.(models.CustomerBatchResult)
+	want := []int{http.StatusCreated, http.StatusConflict, http.StatusOK, http.StatusNotFound, http.StatusInternalServerError}
+	for i, status := range statuses(result) {
+		if status != want[i] {
+			t.Errorf("item %d status = %d, want %d", i, status, want[i])
+		}
+	}
+	if result.Results[4].Error != writeFailed {
+		t.Errorf("failed write error = %q, want %q", result.Results[4].Error, writeFailed)
+	}
+	assertNoLeak(t, result)
+	if result.Failed != 3 || result.Inserted != 1 || result.Modified != 1 {
+		t.Errorf("result = %+v, want 1 inserted, 1 modified and 3 failed", result)
+	}
+
+	// only the create of Grace and the update of c-1 were committed
+	if len(store.customers) != 2 || len(audit.entries) != 2 || len(publisher.events) != 2 {
+		t.Errorf("%d customers, %d audit entries and %d events, want 2 of each", len(store.customers), len(audit.entries), len(publisher.events))
+	}
+}
+
+func TestTransactionalBatchAborts(t *testing.T) {
+	tests := []struct {
+		name     string
+		failing  bool
+		ops      []models.CustomerBatchOperation
+		status   int
+		statuses []int
+	}{
+		{
+			name:     "duplicate key",
+			ops:      []models.CustomerBatchOperation{create("Grace"), create("Dup"), {Op: models.BatchOpDelete, Customer_id: "c-1"}},
+			status:   http.StatusConflict,
+			statuses: []int{http.StatusFailedDependency, http.StatusConflict, http.StatusFailedDependency},
+		},
+		{
+			name:     "audit failure",
+			failing:  true,
+			ops:      []models.CustomerBatchOperation{create("Grace"), {Op: models.BatchOpDelete, Customer_id: "c-1"}},
+			status:   http.StatusInternalServerError,
+			statuses: []int{http.StatusInternalServerError, http.StatusInternalServerError},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store, audit, publisher := batchFixture()
+			transactor := rollbackTransactor{store, audit, publisher}
+			service := NewCustomerService(store, stubUserRepository{}, audit, publisher, nil, transactor, timeouts.Defaults())
+			if test.failing {
+				failing := &failingAuditService{}
+				transactor.audit = &failing.stubAuditService
+				service = NewCustomerService(store, stubUserRepository{}, failing, publisher, nil, transactor, timeouts.Defaults())
+			}
+
+			response, err := service.BatchCustomersByUserId(context.Background(), auditModels.Actor{}, "u-1", models.CustomerBatchRequest{Transactional: true, Operations: test.ops})
+			if err == nil || response.Status != test.status {
+				t.Fatalf("status = %d, error = %v, want %d and an error", response.Status, err, test.status)
+			}
+			result := response.Data.(models.CustomerBatchResult)
+			for i, status := range statuses(result) {
+				if status != test.statuses[i] {
+					t.Errorf("item %d status = %d, want %d", i, status, test.statuses[i])
+				}
+			}
+			assertNoLeak(t, result)
+			if _, ok := store.customers["c-1"]; !ok || len(store.customers) != 1 || len(audit.entries) != 0 || len(publisher.events) != 0 {
+				t.Errorf("an aborted batch left %d customers, %d audit entries and %d events", len(store.customers), len(audit.entries), len(publisher.events))
+			}
+		})
+	}
+}
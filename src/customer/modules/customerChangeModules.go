@@ -0,0 +1,214 @@
+package modules
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	auditModels "somdeep-demo-app/src/audit/models"
+	auditModules "somdeep-demo-app/src/audit/modules"
+	"somdeep-demo-app/src/customer/models"
+	"somdeep-demo-app/src/database"
+	eventModels "somdeep-demo-app/src/events/models"
+	"somdeep-demo-app/src/tracing"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var customerEventTypes = map[string]string{
+	auditModels.ActionCreate: eventModels.CustomerCreated,
+	auditModels.ActionUpdate: eventModels.CustomerUpdated,
+	auditModels.ActionDelete: eventModels.CustomerDeleted,
+}
+
+// customerChange is a committed change from Before to After, where a nil side
+// means the customer did not exist on that side of the change.
+type customerChange struct {
+	Action string
+	Before *models.Customer
+	After  *models.Customer
+}
+
+// recordChanges writes the audit entries and publishes the domain events for
+// changes. It must be called with the transaction context of the write so
+// that both are stored atomically with it; an error aborts the transaction.
+func (s *customerService) recordChanges(ctx context.Context, actor auditModels.Actor, changes ...customerChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	entries := make([]auditModels.AuditEntry, 0, len(changes))
+	events := make([]eventModels.Event, 0, len(changes))
+	for _, change := range changes {
+		entry := customerAuditEntry(change)
+		event, err := customerEvent(change, entry)
+		if err != nil {
+			return err
+		}
+		event.Actor = actor.ID
+		event.Request_id = actor.Request_id
+		event.Traceparent, event.Tracestate = tracing.Inject(ctx)
+		entries = append(entries, entry)
+		events = append(events, event)
+	}
+	if err := s.auditService.Record(ctx, actor, entries...); err != nil {
+		return err
+	}
+	return s.eventPublisher.Publish(ctx, events...)
+}
+
+// bulkWriteChanges runs writeModels, where changes[i] describes writeModels[i],
+// and records the audit entries and events of every change that was written. Unless
+// allOrNothing is set, items that fail are returned in failed (keyed by model
+// index) while the rest are still written.
+//
+// Inside a transaction a single write error aborts everything, so a partial
+// batch is written by retrying without the items that failed until the
+// remainder commits together with its outbox rows.
+func (s *customerService) bulkWriteChanges(ctx context.Context, actor auditModels.Actor, writeModels []mongo.WriteModel, changes []customerChange, allOrNothing bool) (result *mongo.BulkWriteResult, failed map[int]writeFailure, err error) {
+	failed = map[int]writeFailure{}
+
+	if !s.transactor.Enabled() {
+		result, err = s.customerRepository.BulkWriteCustomers(ctx, writeModels, false)
+		var bulkErr mongo.BulkWriteException
+		if err != nil && !errors.As(err, &bulkErr) {
+			return nil, failed, err
+		}
+		var written []customerChange
+		for _, writeError := range bulkErr.WriteErrors {
+			failed[writeError.Index] = classifyWriteError(ctx, writeError)
+		}
+		for i, change := range changes {
+			if _, ok := failed[i]; !ok {
+				written = append(written, change)
+			}
+		}
+		if err = s.recordChanges(ctx, actor, written...); err != nil {
+			slog.ErrorContext(ctx, "failed to store audit entries and events for written customers", "customers", len(written), "error", err)
+		}
+		return result, failed, nil
+	}
+
+	remaining := make([]int, len(writeModels))
+	for i := range remaining {
+		remaining[i] = i
+	}
+
+	for len(remaining) > 0 {
+		attemptModels := make([]mongo.WriteModel, len(remaining))
+		attemptChanges := make([]customerChange, len(remaining))
+		for j, i := range remaining {
+			attemptModels[j] = writeModels[i]
+			attemptChanges[j] = changes[i]
+		}
+
+		err = s.transactor.WithTransaction(ctx, func(txCtx context.Context) error {
+			var writeErr error
+			result, writeErr = s.customerRepository.BulkWriteCustomers(txCtx, attemptModels, true)
+			if writeErr != nil {
+				return writeErr
+			}
+			return s.recordChanges(txCtx, actor, attemptChanges...)
+		})
+		if err == nil {
+			return result, failed, nil
+		}
+
+		var bulkErr mongo.BulkWriteException
+		if allOrNothing || !errors.As(err, &bulkErr) || len(bulkErr.WriteErrors) == 0 {
+			return nil, failed, err
+		}
+		for _, writeError := range bulkErr.WriteErrors {
+			failed[remaining[writeError.Index]] = classifyWriteError(ctx, writeError)
+		}
+		next := remaining[:0]
+		for _, i := range remaining {
+			if _, ok := failed[i]; !ok {
+				next = append(next, i)
+			}
+		}
+		remaining = next
+	}
+	return &mongo.BulkWriteResult{}, failed, nil
+}
+
+// writeFailed is what the client is told about a write that failed for a
+// reason it cannot act on; the driver's error is logged instead.
+const writeFailed = "The customer could not be written"
+
+// writeFailure is why one write model was not written, fit to be reported to
+// the client.
+type writeFailure struct {
+	Status  int
+	Message string
+}
+
+// classifyWriteError reports errors the client can act on, such as a
+// duplicate key, as they are and any other as writeFailed.
+func classifyWriteError(ctx context.Context, writeError mongo.BulkWriteError) writeFailure {
+	status, err := database.ClassifyError(mongo.WriteException{WriteErrors: mongo.WriteErrors{writeError.WriteError}}, "Customer not found or is already deleted")
+	if status < http.StatusInternalServerError {
+		return writeFailure{Status: status, Message: err.Error()}
+	}
+	slog.ErrorContext(ctx, "customer write failed", "code", writeError.Code, "error", writeError.Message)
+	return writeFailure{Status: http.StatusInternalServerError, Message: writeFailed}
+}
+
+func customerAuditEntry(change customerChange) auditModels.AuditEntry {
+	var beforeSnapshot, afterSnapshot map[string]any
+	entry := auditModels.AuditEntry{Entity_type: auditModels.EntityCustomer, Action: change.Action}
+	if change.Before != nil {
+		beforeSnapshot = customerSnapshot(*change.Before)
+		entry.Entity_id = change.Before.Customer_id
+		entry.Parent_id = change.Before.User_id
+	}
+	if change.After != nil {
+		afterSnapshot = customerSnapshot(*change.After)
+		entry.Entity_id = change.After.Customer_id
+		entry.Parent_id = change.After.User_id
+	}
+	entry.Changes = auditModules.Diff(beforeSnapshot, afterSnapshot)
+	return entry
+}
+
+func customerSnapshot(customer models.Customer) map[string]any {
+	if customer.User_id == "" && customer.First_name == nil && customer.Last_name == nil {
+		// only the id is known, there is nothing to diff
+		return nil
+	}
+	return map[string]any{
+		"customer_id": customer.Customer_id,
+		"user_id":     customer.User_id,
+		"first_name":  stringValue(customer.First_name),
+		"last_name":   stringValue(customer.Last_name),
+		"created_at":  customer.Created_at.UTC(),
+		"updated_at":  customer.Updated_at.UTC(),
+	}
+}
+
+func customerEvent(change customerChange, entry auditModels.AuditEntry) (eventModels.Event, error) {
+	eventType := customerEventTypes[change.Action]
+	if change.After == nil {
+		return eventModels.NewEvent(eventType, change.Before.Customer_id, eventModels.CustomerDeletedPayload{
+			Customer_id: change.Before.Customer_id,
+			User_id:     change.Before.User_id,
+		})
+	}
+
+	customer := *change.After
+	payload := eventModels.CustomerPayload{
+		Customer_id: customer.Customer_id,
+		User_id:     customer.User_id,
+		First_name:  stringValue(customer.First_name),
+		Last_name:   stringValue(customer.Last_name),
+		Created_at:  customer.Created_at.UTC(),
+		Updated_at:  customer.Updated_at.UTC(),
+	}
+	if change.Action == auditModels.ActionUpdate {
+		for _, fieldChange := range entry.Changes {
+			payload.Changed = append(payload.Changed, fieldChange.Field)
+		}
+	}
+	return eventModels.NewEvent(eventType, customer.Customer_id, payload)
+}
@@ -0,0 +1,186 @@
+package modules
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"somdeep-demo-app/src/customer/interfaces"
+	"somdeep-demo-app/src/customer/models"
+	"somdeep-demo-app/src/database"
+	"somdeep-demo-app/src/export"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func (s *customerService) ExportCustomers(ctx context.Context, filter models.CustomerExportFilter, format string, writer io.Writer) (response interfaces.Response, err error) {
+	ctx, cancel := s.timeouts.Context(ctx, "ExportCustomers")
+	defer cancel()
+
+	var res interfaces.Response
+
+	columns, err := export.SelectColumns(models.CustomerExportColumns, filter.Fields)
+	if err == nil {
+		_, err = export.ContentType(format)
+	}
+	if err != nil {
+		res.Status = http.StatusBadRequest
+		res.Error = err.Error()
+		res.Message = "Validation Error"
+		res.Data = nil
+		return res, err
+	}
+
+	mongoFilter := bson.M{}
+	if filter.User_id != "" {
+		_, err = s.userRepository.GetUserByUserId(ctx, filter.User_id)
+		if err != nil {
+			res.Error = err.Error()
+			res.Message = "The user associated with customer is not present or is deleted"
+			res.Data = nil
+			res.Status, err = database.ClassifyError(err, res.Message)
+			return res, err
+		}
+		mongoFilter["user_id"] = filter.User_id
+	}
+
+	cursor, err := s.customerRepository.FindCustomers(ctx, mongoFilter, filter.StartIndex, filter.RecordPerPage)
+	if err != nil {
+		res.Error = err.Error()
+		res.Message = "error occured while exporting customer items"
+		res.Data = nil
+		res.Status, err = database.ClassifyError(err, res.Message)
+		return res, err
+	}
+	defer cursor.Close(ctx)
+
+	rows, err := export.NewRowWriter(format, writer, columns)
+	if err != nil {
+		res.Error = err.Error()
+		res.Message = "error occured while exporting customer items"
+		res.Data = nil
+		res.Status, err = database.ClassifyError(err, res.Message)
+		return res, err
+	}
+
+	count := 0
+	for cursor.Next(ctx) {
+		var customer models.Customer
+		if err = cursor.Decode(&customer); err == nil {
+			err = rows.WriteRow(customerExportValues(customer, columns))
+		}
+		if err != nil {
+			break
+		}
+		count++
+	}
+	if err == nil {
+		err = cursor.Err()
+	}
+	if closeErr := rows.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		res.Status = http.StatusInternalServerError
+		res.Error = err.Error()
+		res.Message = "Export was interrupted"
+		res.Data = count
+		return res, err
+	}
+
+	res.Status = http.StatusOK
+	res.Error = "NA"
+	res.Message = "Export completed"
+	res.Data = count
+	return res, nil
+}
+
+// StreamCustomers hands every customer of userId, or of every user when
+// userId is "", to send in _id order, reading them from a single cursor, and
+// stops at the first error send returns. Data is the number of customers sent.
+func (s *customerService) StreamCustomers(ctx context.Context, userId string, send func(customer models.Customer) error) (response interfaces.Response, err error) {
+	ctx, cancel := s.timeouts.Context(ctx, "StreamCustomers")
+	defer cancel()
+
+	var res interfaces.Response
+
+	filter := bson.M{}
+	if userId != "" {
+		_, err = s.userRepository.GetUserByUserId(ctx, userId)
+		if err != nil {
+			res.Error = err.Error()
+			res.Message = "The user associated with customer is not present or is deleted"
+			res.Data = nil
+			res.Status, err = database.ClassifyError(err, res.Message)
+			return res, err
+		}
+		filter["user_id"] = userId
+	}
+
+	cursor, err := s.customerRepository.FindCustomers(ctx, filter, 0, 0)
+	if err != nil {
+		res.Error = err.Error()
+		res.Message = "error occured while streaming customer items"
+		res.Data = nil
+		res.Status, err = database.ClassifyError(err, res.Message)
+		return res, err
+	}
+	defer cursor.Close(ctx)
+
+	count := 0
+	for cursor.Next(ctx) {
+		var customer models.Customer
+		if err = cursor.Decode(&customer); err == nil {
+			err = send(customer)
+		}
+		if err != nil {
+			break
+		}
+		count++
+	}
+	if err == nil {
+		err = cursor.Err()
+	}
+	if err != nil {
+		res.Error = err.Error()
+		res.Message = "Stream was interrupted"
+		res.Data = count
+		res.Status, err = database.ClassifyError(err, res.Message)
+		return res, err
+	}
+
+	res.Status = http.StatusOK
+	res.Error = "NA"
+	res.Message = "Stream completed"
+	res.Data = count
+	return res, nil
+}
+
+func customerExportValues(customer models.Customer, columns []string) []string {
+	values := make([]string, len(columns))
+	for i, column := range columns {
+		switch column {
+		case "customer_id":
+			values[i] = customer.Customer_id
+		case "user_id":
+			values[i] = customer.User_id
+		case "first_name":
+			values[i] = stringValue(customer.First_name)
+		case "last_name":
+			values[i] = stringValue(customer.Last_name)
+		case "created_at":
+			values[i] = customer.Created_at.UTC().Format(time.RFC3339)
+		case "updated_at":
+			values[i] = customer.Updated_at.UTC().Format(time.RFC3339)
+		}
+	}
+	return values
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
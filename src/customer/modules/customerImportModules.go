@@ -0,0 +1,400 @@
+package modules
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	auditModels "somdeep-demo-app/src/audit/models"
+	"somdeep-demo-app/src/customer/interfaces"
+	"somdeep-demo-app/src/customer/models"
+	"somdeep-demo-app/src/database"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ImportBatchSize is the number of valid rows checked for duplicates and
+// inserted per round-trip while an import is streaming.
+const ImportBatchSize = 500
+
+// importColumns maps accepted header spellings to customer fields.
+var importColumns = map[string]string{
+	"first_name": "first_name",
+	"firstname":  "first_name",
+	"first name": "first_name",
+	"last_name":  "last_name",
+	"lastname":   "last_name",
+	"last name":  "last_name",
+}
+
+type importRow struct {
+	row      int
+	customer models.Customer
+}
+
+type customerRowReader interface {
+	// Next returns io.EOF once the input is exhausted. Any other error that is
+	// an *importRowError only affects that row; everything else aborts the import.
+	Next() (row int, customer models.Customer, err error)
+}
+
+type importRowError struct {
+	row int
+	err error
+}
+
+func (e *importRowError) Error() string {
+	return e.err.Error()
+}
+
+func (s *customerService) ImportCustomersByUserId(ctx context.Context, actor auditModels.Actor, userId string, reader io.Reader, format string, dryRun bool) (response interfaces.Response, err error) {
+	ctx, cancel := s.timeouts.Context(ctx, "ImportCustomersByUserId")
+	defer cancel()
+
+	var res interfaces.Response
+
+	_, err = s.userRepository.GetUserByUserId(ctx, userId)
+	if err != nil {
+		res.Error = err.Error()
+		res.Message = "The user associated with customer is not present or is deleted"
+		res.Data = nil
+		res.Status, err = database.ClassifyError(err, res.Message)
+		return res, err
+	}
+
+	report := models.CustomerImportReport{
+		DryRun: dryRun,
+		Format: format,
+		Errors: []models.CustomerImportRowError{},
+	}
+
+	var rows customerRowReader
+	switch format {
+	case models.ImportFormatCSV:
+		csvRows, headerErr := newCSVRowReader(reader)
+		if headerErr != nil {
+			res.Status = http.StatusBadRequest
+			res.Error = headerErr.Error()
+			res.Message = "Invalid CSV header"
+			res.Data = nil
+			return res, headerErr
+		}
+		report.IgnoredColumns = csvRows.ignored
+		rows = csvRows
+	case models.ImportFormatNDJSON:
+		rows = newNDJSONRowReader(reader)
+	default:
+		err = fmt.Errorf("unsupported import format %q", format)
+		res.Status = http.StatusBadRequest
+		res.Error = err.Error()
+		res.Message = "Validation Error"
+		res.Data = nil
+		return res, err
+	}
+
+	seen := map[string]int{}
+	pending := make([]importRow, 0, ImportBatchSize)
+
+	for {
+		row, customer, readErr := rows.Next()
+		if readErr == io.EOF {
+			break
+		}
+
+		var rowErr *importRowError
+		if readErr != nil && !errors.As(readErr, &rowErr) {
+			res.Status = http.StatusBadRequest
+			res.Error = readErr.Error()
+			res.Message = "Error occured while reading the import file"
+			res.Data = report
+			return res, readErr
+		}
+		// only now is it a row of the file, even when it is malformed
+		report.TotalRows++
+		if rowErr != nil {
+			report.Invalid++
+			report.Errors = append(report.Errors, models.CustomerImportRowError{Row: rowErr.row, Error: rowErr.Error()})
+			continue
+		}
+
+		if validationErrors := importValidationErrors(row, customer); len(validationErrors) > 0 {
+			report.Invalid++
+			report.Errors = append(report.Errors, validationErrors...)
+			continue
+		}
+
+		key := customerNameKey(customer)
+		if firstRow, duplicate := seen[key]; duplicate {
+			report.Duplicates++
+			report.Errors = append(report.Errors, models.CustomerImportRowError{
+				Row:   row,
+				Rule:  "unique",
+				Error: fmt.Sprintf("duplicate of row %d in the same file", firstRow),
+			})
+			continue
+		}
+		seen[key] = row
+
+		pending = append(pending, importRow{row: row, customer: customer})
+		if len(pending) == ImportBatchSize {
+			if err = s.flushImportBatch(ctx, actor, userId, pending, dryRun, &report); err != nil {
+				break
+			}
+			pending = pending[:0]
+		}
+	}
+
+	if err == nil && len(pending) > 0 {
+		err = s.flushImportBatch(ctx, actor, userId, pending, dryRun, &report)
+	}
+	sort.SliceStable(report.Errors, func(i, j int) bool {
+		return report.Errors[i].Row < report.Errors[j].Row
+	})
+	if err != nil {
+		res.Status = http.StatusInternalServerError
+		res.Error = err.Error()
+		res.Message = "Customer import failed"
+		res.Data = report
+		return res, err
+	}
+
+	res.Status = http.StatusOK
+	if !dryRun && report.Inserted > 0 {
+		res.Status = http.StatusCreated
+	}
+	res.Error = "NA"
+	if dryRun {
+		res.Message = "Dry run completed, no changes were written"
+	} else {
+		res.Message = "Customers imported successfully"
+	}
+	res.Data = report
+	return res, nil
+}
+
+// flushImportBatch drops rows that already exist for the user and inserts the
+// rest, unless this is a dry run. Rows count as valid once they are written;
+// a row that loses a race with another writer counts as a duplicate.
+func (s *customerService) flushImportBatch(ctx context.Context, actor auditModels.Actor, userId string, batch []importRow, dryRun bool, report *models.CustomerImportReport) error {
+	customers := make([]models.Customer, len(batch))
+	for i, item := range batch {
+		customers[i] = item.customer
+	}
+
+	existing, err := s.customerRepository.GetExistingCustomerNames(ctx, userId, customers)
+	if err != nil {
+		return err
+	}
+	existingIds := make(map[string]string, len(existing))
+	for _, customer := range existing {
+		existingIds[customerNameKey(customer)] = customer.Customer_id
+	}
+
+	now := time.Now()
+	var writeModels []mongo.WriteModel
+	var writeRows []int
+	var changes []customerChange
+	for _, item := range batch {
+		if customerId, found := existingIds[customerNameKey(item.customer)]; found {
+			report.Duplicates++
+			report.Errors = append(report.Errors, models.CustomerImportRowError{
+				Row:   item.row,
+				Rule:  "unique",
+				Error: "customer already exists with customer_id " + customerId,
+			})
+			continue
+		}
+		if dryRun {
+			report.Valid++
+			continue
+		}
+
+		customer := item.customer
+		customer.Created_at = now
+		customer.Updated_at = now
+		customer.ID = primitive.NewObjectID()
+		customer.Customer_id = uuid.New().String()
+		customer.User_id = userId
+		writeModels = append(writeModels, mongo.NewInsertOneModel().SetDocument(customer))
+		writeRows = append(writeRows, item.row)
+		changes = append(changes, customerChange{Action: auditModels.ActionCreate, After: &customer})
+	}
+
+	if len(writeModels) == 0 {
+		return nil
+	}
+
+	result, failed, err := s.bulkWriteChanges(ctx, actor, writeModels, changes, false)
+	if err != nil {
+		return err
+	}
+	if result != nil {
+		report.Inserted += int(result.InsertedCount)
+	}
+
+	for i := range changes {
+		failure, ok := failed[i]
+		if !ok {
+			report.Valid++
+			continue
+		}
+		rowError := models.CustomerImportRowError{Row: writeRows[i], Error: failure.Message}
+		if failure.Status == http.StatusConflict {
+			rowError.Rule = "unique"
+			report.Duplicates++
+		} else {
+			report.Failed++
+		}
+		report.Errors = append(report.Errors, rowError)
+	}
+	return nil
+}
+
+func importValidationErrors(row int, customer models.Customer) (rowErrors []models.CustomerImportRowError) {
+	err := validate.Struct(customer)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return []models.CustomerImportRowError{{Row: row, Error: err.Error()}}
+	}
+	for _, fieldError := range validationErrors {
+		rowErrors = append(rowErrors, models.CustomerImportRowError{
+			Row:   row,
+			Field: strings.ToLower(fieldError.Field()),
+			Rule:  fieldError.Tag(),
+			Error: fieldError.Error(),
+		})
+	}
+	return rowErrors
+}
+
+func customerNameKey(customer models.Customer) string {
+	var first, last string
+	if customer.First_name != nil {
+		first = *customer.First_name
+	}
+	if customer.Last_name != nil {
+		last = *customer.Last_name
+	}
+	return first + "\x00" + last
+}
+
+type csvRowReader struct {
+	reader  *csv.Reader
+	columns []string
+	ignored []string
+}
+
+func newCSVRowReader(reader io.Reader) (*csvRowReader, error) {
+	r := csv.NewReader(reader)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	r.ReuseRecord = true
+
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil, errors.New("file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows := &csvRowReader{reader: r, columns: make([]string, len(header))}
+	mapped := 0
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if field, ok := importColumns[name]; ok {
+			rows.columns[i] = field
+			mapped++
+			continue
+		}
+		rows.ignored = append(rows.ignored, name)
+	}
+	if mapped == 0 {
+		return nil, errors.New("header has no recognised customer columns")
+	}
+	return rows, nil
+}
+
+func (r *csvRowReader) Next() (int, models.Customer, error) {
+	var customer models.Customer
+	record, err := r.reader.Read()
+
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return parseErr.Line, customer, &importRowError{row: parseErr.Line, err: err}
+	}
+	if err != nil {
+		return 0, customer, err
+	}
+	line, _ := r.reader.FieldPos(0)
+
+	for i, value := range record {
+		if i >= len(r.columns) {
+			break
+		}
+		value := strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		switch r.columns[i] {
+		case "first_name":
+			customer.First_name = &value
+		case "last_name":
+			customer.Last_name = &value
+		}
+	}
+	return line, customer, nil
+}
+
+type ndjsonRowReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func newNDJSONRowReader(reader io.Reader) *ndjsonRowReader {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	return &ndjsonRowReader{scanner: scanner}
+}
+
+func (r *ndjsonRowReader) Next() (int, models.Customer, error) {
+	var customer models.Customer
+	for r.scanner.Scan() {
+		r.line++
+		line := strings.TrimSpace(r.scanner.Text())
+		if line == "" {
+			continue
+		}
+		if err := json.Unmarshal([]byte(line), &customer); err != nil {
+			return r.line, customer, &importRowError{row: r.line, err: err}
+		}
+		if customer.First_name != nil {
+			trimmed := strings.TrimSpace(*customer.First_name)
+			customer.First_name = &trimmed
+		}
+		if customer.Last_name != nil {
+			trimmed := strings.TrimSpace(*customer.Last_name)
+			customer.Last_name = &trimmed
+		}
+		return r.line, customer, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return r.line, customer, err
+	}
+	return r.line, customer, io.EOF
+}
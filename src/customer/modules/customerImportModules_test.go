@@ -0,0 +1,130 @@
+package modules
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	auditModels "somdeep-demo-app/src/audit/models"
+	"somdeep-demo-app/src/customer/models"
+	"somdeep-demo-app/src/timeouts"
+)
+
+// importFixture returns a service whose store already holds Grace Hopper for
+// u-1, fails to insert Dup with a duplicate key and Boom with a driver error.
+func importFixture() (*batchStore, *stubAuditService, *customerService) {
+	store, audit, publisher := batchFixture()
+	store.customers = map[string]models.Customer{
+		"c-1": {Customer_id: "c-1", User_id: "u-1", First_name: ptr("Grace"), Last_name: ptr("Hopper")},
+	}
+	service := NewCustomerService(store, stubUserRepository{}, audit, publisher, nil, rollbackTransactor{store, audit, publisher}, timeouts.Defaults())
+	return store, audit, service.(*customerService)
+}
+
+func TestImportCustomers(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		input  string
+		dryRun bool
+		status int
+		want   models.CustomerImportReport
+		rows   map[int]string
+	}{
+		{
+			name:   "csv",
+			format: models.ImportFormatCSV,
+			input: "First Name,last_name,notes\n" +
+				"Ada,Lovelace,first\n" +
+				"A,Turing,too short\n" +
+				"Ada,Lovelace,again\n" +
+				"Grace,Hopper,in the database\n" +
+				"Dup,Licate,raced\n" +
+				"Boom,Crash,driver error\n" +
+				"\"Alan,Turing\n",
+			status: http.StatusCreated,
+			want:   models.CustomerImportReport{TotalRows: 7, Valid: 1, Invalid: 2, Duplicates: 3, Failed: 1, Inserted: 1, IgnoredColumns: []string{"notes"}},
+			rows:   map[int]string{3: "min", 4: "unique", 5: "unique", 6: "unique", 7: "", 8: ""},
+		},
+		{
+			name:   "ndjson",
+			format: models.ImportFormatNDJSON,
+			input: `{"first_name": " Ada ", "last_name": "Lovelace"}` + "\n" +
+				"\n" +
+				`{"first_name": "Ada", "last_name": "Lovelace"}` + "\n" +
+				`{"first_name": "Grace", "last_name": "Hopper"}` + "\n" +
+				`{"first_name": "Alan",` + "\n" +
+				`{"first_name": "Alan", "last_name": "Turing"}` + "\n",
+			status: http.StatusCreated,
+			want:   models.CustomerImportReport{TotalRows: 5, Valid: 2, Invalid: 1, Duplicates: 2, Inserted: 2},
+			rows:   map[int]string{3: "unique", 4: "unique", 5: ""},
+		},
+		{
+			name:   "dry run",
+			format: models.ImportFormatCSV,
+			input:  "first_name,last_name\nAda,Lovelace\nGrace,Hopper\nAlan,Turing\n",
+			dryRun: true,
+			status: http.StatusOK,
+			want:   models.CustomerImportReport{DryRun: true, TotalRows: 3, Valid: 2, Duplicates: 1},
+			rows:   map[int]string{3: "unique"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store, audit, service := importFixture()
+			response, err := service.ImportCustomersByUserId(context.Background(), auditModels.Actor{}, "u-1", strings.NewReader(test.input), test.format, test.dryRun)
+			if err != nil || response.Status != test.status {
+				t.Fatalf("status = %d, error = %v, want %d", response.Status, err, test.status)
+			}
+			report := response.Data.(models.CustomerImportReport)
+			if report.TotalRows != test.want.TotalRows || report.Valid != test.want.Valid || report.Invalid != test.want.Invalid ||
+				report.Duplicates != test.want.Duplicates || report.Failed != test.want.Failed || report.Inserted != test.want.Inserted ||
+				strings.Join(report.IgnoredColumns, ",") != strings.Join(test.want.IgnoredColumns, ",") {
+				t.Errorf("report = %+v, want %+v", report, test.want)
+			}
+			if report.Valid+report.Invalid+report.Duplicates+report.Failed != report.TotalRows {
+				t.Errorf("report = %+v does not account for every row", report)
+			}
+
+			rules := map[int]string{}
+			for _, rowError := range report.Errors {
+				rules[rowError.Row] = rowError.Rule
+				if strings.Contains(rowError.Error, "E11000") || strings.Contains(rowError.Error, "mongo-") {
+					t.Errorf("row %d error %q carries driver details", rowError.Row, rowError.Error)
+				}
+			}
+			if len(rules) != len(test.rows) {
+				t.Errorf("rows with errors = %v, want %v", rules, test.rows)
+			}
+			for row, rule := range test.rows {
+				if got, ok := rules[row]; !ok || got != rule {
+					t.Errorf("row %d rule = %q, want %q", row, got, rule)
+				}
+			}
+
+			// only the valid rows were written and recorded
+			written := test.want.Inserted
+			if len(store.customers) != 1+written || len(audit.entries) != written {
+				t.Errorf("%d customers and %d audit entries, want %d new", len(store.customers), len(audit.entries), written)
+			}
+		})
+	}
+}
+
+func TestImportStopsAtAnUnreadableFile(t *testing.T) {
+	_, _, service := importFixture()
+	input := io.MultiReader(strings.NewReader(`{"first_name": "Ada", "last_name": "Lovelace"}`+"\n"), iotest.ErrReader(errors.New("connection reset")))
+
+	response, err := service.ImportCustomersByUserId(context.Background(), auditModels.Actor{}, "u-1", input, models.ImportFormatNDJSON, false)
+	if err == nil || response.Status != http.StatusBadRequest {
+		t.Fatalf("status = %d, error = %v, want 400", response.Status, err)
+	}
+	// the read that failed is not a row, and nothing was written yet
+	if report := response.Data.(models.CustomerImportReport); report.TotalRows != 1 || report.Valid != 0 || report.Inserted != 0 {
+		t.Errorf("report = %+v, want 1 row read and none written", report)
+	}
+}
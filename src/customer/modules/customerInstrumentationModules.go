@@ -0,0 +1,128 @@
+package modules
+
+import (
+	"context"
+	"io"
+	"time"
+
+	auditModels "somdeep-demo-app/src/audit/models"
+	"somdeep-demo-app/src/customer/interfaces"
+	"somdeep-demo-app/src/customer/models"
+	eventModels "somdeep-demo-app/src/events/models"
+	"somdeep-demo-app/src/metrics"
+	"somdeep-demo-app/src/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentedCustomerService times and traces every CustomerService method
+// and counts the customers created, whether one at a time, in a batch or by
+// an import.
+type instrumentedCustomerService struct {
+	customerService interfaces.CustomerService
+}
+
+func NewInstrumentedCustomerService(customerService interfaces.CustomerService) interfaces.CustomerService {
+	return &instrumentedCustomerService{
+		customerService: customerService,
+	}
+}
+
+func observeCustomerService(span trace.Span, method string, start time.Time, response *interfaces.Response, err *error) {
+	metrics.ObserveService("customers", method, start, response.Status, *err)
+	tracing.End(span, response.Status, *err)
+}
+
+func (s *instrumentedCustomerService) GetAllCustomers(ctx context.Context, recordPerPage int, page int, startIndex int) (response interfaces.Response, err error) {
+	ctx, span := tracing.Start(ctx, "CustomerService.GetAllCustomers")
+	defer observeCustomerService(span, "GetAllCustomers", time.Now(), &response, &err)
+	return s.customerService.GetAllCustomers(ctx, recordPerPage, page, startIndex)
+}
+
+func (s *instrumentedCustomerService) GetCustomersByUserId(ctx context.Context, userId string, recordPerPage int, page int, startIndex int) (response interfaces.Response, err error) {
+	ctx, span := tracing.Start(ctx, "CustomerService.GetCustomersByUserId", attribute.String("app.user_id", userId))
+	defer observeCustomerService(span, "GetCustomersByUserId", time.Now(), &response, &err)
+	return s.customerService.GetCustomersByUserId(ctx, userId, recordPerPage, page, startIndex)
+}
+
+func (s *instrumentedCustomerService) GetCustomersByUserIds(ctx context.Context, userIds []string, recordPerPage int, page int, startIndex int) (response interfaces.Response, err error) {
+	ctx, span := tracing.Start(ctx, "CustomerService.GetCustomersByUserIds")
+	defer observeCustomerService(span, "GetCustomersByUserIds", time.Now(), &response, &err)
+	return s.customerService.GetCustomersByUserIds(ctx, userIds, recordPerPage, page, startIndex)
+}
+
+func (s *instrumentedCustomerService) GetCustomerByCustomerId(ctx context.Context, userId string, customerId string) (response interfaces.Response, err error) {
+	ctx, span := tracing.Start(ctx, "CustomerService.GetCustomerByCustomerId", attribute.String("app.user_id", userId), attribute.String("app.customer_id", customerId))
+	defer observeCustomerService(span, "GetCustomerByCustomerId", time.Now(), &response, &err)
+	return s.customerService.GetCustomerByCustomerId(ctx, userId, customerId)
+}
+
+func (s *instrumentedCustomerService) AddCustomerByUserId(ctx context.Context, actor auditModels.Actor, userId string, customer models.Customer) (response interfaces.Response, err error) {
+	ctx, span := tracing.Start(ctx, "CustomerService.AddCustomerByUserId", attribute.String("app.user_id", userId))
+	defer observeCustomerService(span, "AddCustomerByUserId", time.Now(), &response, &err)
+	response, err = s.customerService.AddCustomerByUserId(ctx, actor, userId, customer)
+	if err == nil {
+		metrics.CustomersCreated.Inc()
+	}
+	return response, err
+}
+
+func (s *instrumentedCustomerService) UpdateCustomerByCustomerId(ctx context.Context, actor auditModels.Actor, userId string, customerId string, customer models.Customer) (response interfaces.Response, err error) {
+	ctx, span := tracing.Start(ctx, "CustomerService.UpdateCustomerByCustomerId", attribute.String("app.user_id", userId), attribute.String("app.customer_id", customerId))
+	defer observeCustomerService(span, "UpdateCustomerByCustomerId", time.Now(), &response, &err)
+	return s.customerService.UpdateCustomerByCustomerId(ctx, actor, userId, customerId, customer)
+}
+
+func (s *instrumentedCustomerService) DeleteCustomerByCustomerId(ctx context.Context, actor auditModels.Actor, userId string, customerId string) (response interfaces.Response, err error) {
+	ctx, span := tracing.Start(ctx, "CustomerService.DeleteCustomerByCustomerId", attribute.String("app.user_id", userId), attribute.String("app.customer_id", customerId))
+	defer observeCustomerService(span, "DeleteCustomerByCustomerId", time.Now(), &response, &err)
+	return s.customerService.DeleteCustomerByCustomerId(ctx, actor, userId, customerId)
+}
+
+func (s *instrumentedCustomerService) DeleteCustomersByUserId(ctx context.Context, actor auditModels.Actor, userId string) (response interfaces.Response, err error) {
+	ctx, span := tracing.Start(ctx, "CustomerService.DeleteCustomersByUserId", attribute.String("app.user_id", userId))
+	defer observeCustomerService(span, "DeleteCustomersByUserId", time.Now(), &response, &err)
+	return s.customerService.DeleteCustomersByUserId(ctx, actor, userId)
+}
+
+func (s *instrumentedCustomerService) BatchCustomersByUserId(ctx context.Context, actor auditModels.Actor, userId string, batch models.CustomerBatchRequest) (response interfaces.Response, err error) {
+	ctx, span := tracing.Start(ctx, "CustomerService.BatchCustomersByUserId", attribute.String("app.user_id", userId))
+	defer observeCustomerService(span, "BatchCustomersByUserId", time.Now(), &response, &err)
+	response, err = s.customerService.BatchCustomersByUserId(ctx, actor, userId, batch)
+	if result, ok := response.Data.(models.CustomerBatchResult); ok {
+		metrics.CustomersCreated.Add(float64(result.Inserted))
+	}
+	return response, err
+}
+
+func (s *instrumentedCustomerService) ExportCustomers(ctx context.Context, filter models.CustomerExportFilter, format string, writer io.Writer) (response interfaces.Response, err error) {
+	ctx, span := tracing.Start(ctx, "CustomerService.ExportCustomers", attribute.String("app.format", format))
+	defer observeCustomerService(span, "ExportCustomers", time.Now(), &response, &err)
+	return s.customerService.ExportCustomers(ctx, filter, format, writer)
+}
+
+func (s *instrumentedCustomerService) StreamCustomers(ctx context.Context, userId string, send func(customer models.Customer) error) (response interfaces.Response, err error) {
+	ctx, span := tracing.Start(ctx, "CustomerService.StreamCustomers", attribute.String("app.user_id", userId))
+	defer observeCustomerService(span, "StreamCustomers", time.Now(), &response, &err)
+	return s.customerService.StreamCustomers(ctx, userId, send)
+}
+
+func (s *instrumentedCustomerService) ImportCustomersByUserId(ctx context.Context, actor auditModels.Actor, userId string, reader io.Reader, format string, dryRun bool) (response interfaces.Response, err error) {
+	ctx, span := tracing.Start(ctx, "CustomerService.ImportCustomersByUserId", attribute.String("app.user_id", userId), attribute.String("app.format", format))
+	defer observeCustomerService(span, "ImportCustomersByUserId", time.Now(), &response, &err)
+	response, err = s.customerService.ImportCustomersByUserId(ctx, actor, userId, reader, format, dryRun)
+	// a failed import may still have inserted the batches before the failure
+	if report, ok := response.Data.(models.CustomerImportReport); ok && !report.DryRun {
+		metrics.CustomersCreated.Add(float64(report.Inserted))
+	}
+	return response, err
+}
+
+func (s *instrumentedCustomerService) StreamCustomerChanges(ctx context.Context, userId string, lastEventId string) (changes <-chan eventModels.StreamedEvent, response interfaces.Response, err error) {
+	ctx, span := tracing.Start(ctx, "CustomerService.StreamCustomerChanges", attribute.String("app.user_id", userId))
+	// only opening the stream is timed and traced, not how long the client
+	// follows it
+	defer observeCustomerService(span, "StreamCustomerChanges", time.Now(), &response, &err)
+	return s.customerService.StreamCustomerChanges(ctx, userId, lastEventId)
+}
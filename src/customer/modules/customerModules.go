@@ -2,18 +2,24 @@ package modules
 
 import (
 	"context"
-	"log"
 	"net/http"
 	"time"
 
+	"somdeep-demo-app/src/apperrors"
+	auditInterfaces "somdeep-demo-app/src/audit/interfaces"
+	auditModels "somdeep-demo-app/src/audit/models"
 	"somdeep-demo-app/src/customer/interfaces"
 	"somdeep-demo-app/src/customer/models"
+	"somdeep-demo-app/src/database"
+	eventInterfaces "somdeep-demo-app/src/events/interfaces"
+	"somdeep-demo-app/src/timeouts"
 	userInterfaces "somdeep-demo-app/src/user/interfaces"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
@@ -22,17 +28,31 @@ var validate = validator.New()
 type customerService struct {
 	customerRepository interfaces.CustomerRepository
 	userRepository     userInterfaces.UserRepository
+	auditService       auditInterfaces.AuditService
+	eventPublisher     eventInterfaces.EventPublisher
+	eventStream        eventInterfaces.EventStream
+	transactor         database.Transactor
+	timeouts           timeouts.Timeouts
 }
 
-func NewCustomerService(customerRepository interfaces.CustomerRepository, userRepository userInterfaces.UserRepository) interfaces.CustomerService {
+// NewCustomerService publishes events with the transaction context of the
+// change they describe, so eventPublisher should be the outbox publisher.
+// eventStream may be nil when change streaming is not served. Every method
+// runs under the caller's context, further bounded by timeouts.
+func NewCustomerService(customerRepository interfaces.CustomerRepository, userRepository userInterfaces.UserRepository, auditService auditInterfaces.AuditService, eventPublisher eventInterfaces.EventPublisher, eventStream eventInterfaces.EventStream, transactor database.Transactor, timeouts timeouts.Timeouts) interfaces.CustomerService {
 	return &customerService{
 		customerRepository: customerRepository,
 		userRepository:     userRepository,
+		auditService:       auditService,
+		eventPublisher:     eventPublisher,
+		eventStream:        eventStream,
+		transactor:         transactor,
+		timeouts:           timeouts,
 	}
 }
 
-func (s *customerService) GetAllCustomers(recordPerPage int, page int, startIndex int) (response interfaces.Response, err error) {
-	var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+func (s *customerService) GetAllCustomers(ctx context.Context, recordPerPage int, page int, startIndex int) (response interfaces.Response, err error) {
+	ctx, cancel := s.timeouts.Context(ctx, "GetAllCustomers")
 
 	var res interfaces.Response
 
@@ -40,22 +60,27 @@ func (s *customerService) GetAllCustomers(recordPerPage int, page int, startInde
 	defer cancel()
 	if err != nil {
 		// c.JSON(http.StatusInternalServerError, gin.H{"error": "error occured while listing user items"})
-		res.Status = http.StatusInternalServerError
 		res.Error = err.Error()
 		res.Message = "error occured while listing user items"
 		res.Data = nil
+		res.Status, err = database.ClassifyError(err, res.Message)
 		return res, err
 	}
 	var allusers []bson.M
 	if err = result.All(ctx, &allusers); err != nil {
-		log.Fatal(err)
+		res.Error = err.Error()
+		res.Message = "error occured while listing user items"
+		res.Data = nil
+		res.Status, err = database.ClassifyError(err, res.Message)
+		return res, err
 	}
 
 	if len(allusers) == 0 {
-		res.Status = http.StatusInternalServerError
+		// the aggregation has nothing to group, answer with an empty page
+		res.Status = http.StatusOK
 		res.Error = "NA"
 		res.Message = "No Records Found"
-		res.Data = nil
+		res.Data = []bson.M{{"total_count": 0, "items": []bson.M{}}}
 		return res, err
 	}
 
@@ -67,39 +92,44 @@ func (s *customerService) GetAllCustomers(recordPerPage int, page int, startInde
 	return res, err
 }
 
-func (s *customerService) GetCustomersByUserId(userId string, recordPerPage int, page int, startIndex int) (response interfaces.Response, err error) {
-	var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+func (s *customerService) GetCustomersByUserId(ctx context.Context, userId string, recordPerPage int, page int, startIndex int) (response interfaces.Response, err error) {
+	ctx, cancel := s.timeouts.Context(ctx, "GetCustomersByUserId")
 	defer cancel()
 
 	var res interfaces.Response
 
 	_, err = s.userRepository.GetUserByUserId(ctx, userId)
 	if err != nil {
-		res.Status = http.StatusInternalServerError
 		res.Error = err.Error()
 		res.Message = "The user associated with customer is not present or is deleted"
 		res.Data = nil
+		res.Status, err = database.ClassifyError(err, res.Message)
 		return res, err
 	}
 	result, err := s.customerRepository.GetCustomersByUserId(userId, startIndex, recordPerPage, ctx)
 	if err != nil {
 		// c.JSON(http.StatusInternalServerError, gin.H{"error": "error occured while listing user items"})
-		res.Status = http.StatusInternalServerError
 		res.Error = err.Error()
 		res.Message = "error occured while listing user items"
 		res.Data = nil
+		res.Status, err = database.ClassifyError(err, res.Message)
 		return res, err
 	}
 	var allusers []bson.M
 	if err = result.All(ctx, &allusers); err != nil {
-		log.Fatal(err)
+		res.Error = err.Error()
+		res.Message = "error occured while listing user items"
+		res.Data = nil
+		res.Status, err = database.ClassifyError(err, res.Message)
+		return res, err
 	}
 
 	if len(allusers) == 0 {
-		res.Status = http.StatusInternalServerError
+		// the aggregation has nothing to group, answer with an empty page
+		res.Status = http.StatusOK
 		res.Error = "NA"
 		res.Message = "No Records Found"
-		res.Data = nil
+		res.Data = []bson.M{{"total_count": 0, "items": []bson.M{}}}
 		return res, err
 	}
 
@@ -111,8 +141,41 @@ func (s *customerService) GetCustomersByUserId(userId string, recordPerPage int,
 	return res, err
 }
 
-func (s *customerService) GetCustomerByCustomerId(userId string, customerId string) (response interfaces.Response, err error) {
-	var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+// GetCustomersByUserIds fetches the same page of customers for several users
+// at once. Users without customers are left out of the result rather than
+// reported as an error.
+func (s *customerService) GetCustomersByUserIds(ctx context.Context, userIds []string, recordPerPage int, page int, startIndex int) (response interfaces.Response, err error) {
+	ctx, cancel := s.timeouts.Context(ctx, "GetCustomersByUserIds")
+	defer cancel()
+
+	var res interfaces.Response
+
+	result, err := s.customerRepository.GetCustomersByUserIds(userIds, startIndex, recordPerPage, ctx)
+	if err != nil {
+		res.Error = err.Error()
+		res.Message = "error occured while listing user items"
+		res.Data = nil
+		res.Status, err = database.ClassifyError(err, res.Message)
+		return res, err
+	}
+	pages := []bson.M{}
+	if err = result.All(ctx, &pages); err != nil {
+		res.Error = err.Error()
+		res.Message = "error occured while listing user items"
+		res.Data = nil
+		res.Status, err = database.ClassifyError(err, res.Message)
+		return res, err
+	}
+
+	res.Status = http.StatusOK
+	res.Error = "NA"
+	res.Message = "Records Fetched Successfully"
+	res.Data = pages
+	return res, err
+}
+
+func (s *customerService) GetCustomerByCustomerId(ctx context.Context, userId string, customerId string) (response interfaces.Response, err error) {
+	ctx, cancel := s.timeouts.Context(ctx, "GetCustomerByCustomerId")
 	defer cancel()
 
 	var res interfaces.Response
@@ -120,19 +183,23 @@ func (s *customerService) GetCustomerByCustomerId(userId string, customerId stri
 	// err = userCollection.FindOne(ctx, bson.M{"user_id": userId}).Decode(&user)
 	_, err = s.userRepository.GetUserByUserId(ctx, userId)
 	if err != nil {
-		res.Status = http.StatusInternalServerError
 		res.Error = err.Error()
 		res.Message = "The user associated with customer is not present or is deleted"
 		res.Data = nil
+		res.Status, err = database.ClassifyError(err, res.Message)
 		return res, err
 	}
 	customer, err = s.customerRepository.GetCustomerByCustomerId(ctx, customerId)
+	if err == nil && customer.User_id != userId {
+		// a customer of another user is reported the same as a missing one
+		err = mongo.ErrNoDocuments
+	}
 	if err != nil {
 		// c.JSON(http.StatusInternalServerError, gin.H{"message": "Error occured while fetching documents", "error": err.Error()})
-		res.Status = http.StatusInternalServerError
 		res.Error = err.Error()
 		res.Message = "Error occured while listing user items"
 		res.Data = nil
+		res.Status, err = database.ClassifyError(err, "Customer not found")
 		return res, err
 	}
 	// c.JSON(http.StatusOK, user)
@@ -143,18 +210,18 @@ func (s *customerService) GetCustomerByCustomerId(userId string, customerId stri
 	return res, err
 }
 
-func (s *customerService) AddCustomerByUserId(userId string, customer models.Customer) (response interfaces.Response, err error) {
-	var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+func (s *customerService) AddCustomerByUserId(ctx context.Context, actor auditModels.Actor, userId string, customer models.Customer) (response interfaces.Response, err error) {
+	ctx, cancel := s.timeouts.Context(ctx, "AddCustomerByUserId")
 	defer cancel()
 
 	var res interfaces.Response
 
 	_, err = s.userRepository.GetUserByUserId(ctx, userId)
 	if err != nil {
-		res.Status = http.StatusInternalServerError
 		res.Error = err.Error()
 		res.Message = "The user associated with customer is not present or is deleted"
 		res.Data = nil
+		res.Status, err = database.ClassifyError(err, res.Message)
 		return res, err
 	}
 
@@ -165,10 +232,10 @@ func (s *customerService) AddCustomerByUserId(userId string, customer models.Cus
 	if validationError != nil {
 		// c.JSON(http.StatusBadRequest, gin.H{"error": validationError.Error()})
 		res.Status = http.StatusBadRequest
-		res.Error = err.Error()
+		res.Error = validationError.Error()
 		res.Message = "Validation Error"
 		res.Data = nil
-		return res, err
+		return res, apperrors.FromValidator(res.Message, validationError, customer)
 	}
 
 	customer.Created_at = time.Now()
@@ -177,26 +244,33 @@ func (s *customerService) AddCustomerByUserId(userId string, customer models.Cus
 	customer.Customer_id = uuid.New().String()
 	customer.User_id = userId
 
-	insertErr := s.customerRepository.AddCustomerToMongoDb(ctx, customer)
+	change := customerChange{Action: auditModels.ActionCreate, After: &customer}
+	insertErr := s.transactor.WithTransaction(ctx, func(txCtx context.Context) error {
+		if err := s.customerRepository.AddCustomerToMongoDb(txCtx, customer); err != nil {
+			return err
+		}
+		return s.recordChanges(txCtx, actor, change)
+	})
 	if insertErr != nil {
 		// msg := "User item was not created"
 		// c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
-		res.Status = http.StatusInternalServerError
 		res.Error = insertErr.Error()
 		res.Message = "Customer item was not created"
 		res.Data = nil
+		res.Status, err = database.ClassifyError(insertErr, res.Message)
 		return res, err
 	}
+
 	// c.JSON(http.StatusOK, gin.H{"message": "User added successfully", "userID": user.User_id})
 	res.Status = http.StatusOK
 	res.Error = "NA"
 	res.Message = "Customer Added Successfully"
-	res.Data = "user_id: " + customer.User_id + " & customer_id: " + customer.Customer_id
+	res.Data = models.CustomerRef{User_id: customer.User_id, Customer_id: customer.Customer_id}
 	return res, err
 }
 
-func (s *customerService) UpdateCustomerByCustomerId(userId string, customerId string, customer models.Customer) (response interfaces.Response, err error) {
-	var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+func (s *customerService) UpdateCustomerByCustomerId(ctx context.Context, actor auditModels.Actor, userId string, customerId string, customer models.Customer) (response interfaces.Response, err error) {
+	ctx, cancel := s.timeouts.Context(ctx, "UpdateCustomerByCustomerId")
 	defer cancel()
 
 	var res interfaces.Response
@@ -204,10 +278,10 @@ func (s *customerService) UpdateCustomerByCustomerId(userId string, customerId s
 
 	_, err = s.userRepository.GetUserByUserId(ctx, userId)
 	if err != nil {
-		res.Status = http.StatusInternalServerError
 		res.Error = err.Error()
 		res.Message = "The user associated with customer is not present or is deleted"
 		res.Data = nil
+		res.Status, err = database.ClassifyError(err, res.Message)
 		return res, err
 	}
 
@@ -228,27 +302,62 @@ func (s *customerService) UpdateCustomerByCustomerId(userId string, customerId s
 		Upsert: &upsert,
 	}
 
-	filter := bson.M{"customer_id": customerId}
+	filter := bson.M{"customer_id": customerId, "user_id": userId}
+
+	var result *mongo.UpdateResult
+	var changes []customerChange
+	err = s.transactor.WithTransaction(ctx, func(txCtx context.Context) error {
+		changes = nil
+		result = nil
+		before, beforeErr := s.customerRepository.GetCustomerByCustomerId(txCtx, customerId)
+		if beforeErr == nil && before.User_id != userId {
+			beforeErr = mongo.ErrNoDocuments
+		}
+		if beforeErr != nil {
+			return beforeErr
+		}
+		if !customerChanged(before, customer) {
+			return nil
+		}
+		var updateErr error
+		result, updateErr = s.customerRepository.UpdateCustomerByCustomerId(txCtx, opt, filter, updateObject)
+		if updateErr != nil || result.ModifiedCount == 0 {
+			return updateErr
+		}
+		after := applyCustomerUpdate(before, customer, customer.Updated_at)
+		changes = append(changes, customerChange{Action: auditModels.ActionUpdate, Before: &before, After: &after})
+		return s.recordChanges(txCtx, actor, changes...)
+	})
 
-	result, err := s.customerRepository.UpdateCustomerByCustomerId(ctx, opt, filter, updateObject)
+	if err != nil {
+		// msg := "User update failed"
+		// c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		res.Error = err.Error()
+		res.Message = "Customer update failed"
+		res.Data = nil
+		res.Status, err = database.ClassifyError(err, "Customer not found or is already deleted")
+		return res, err
+	}
 
-	if result.ModifiedCount == 0 {
+	if result != nil && result.MatchedCount == 0 {
 		// c.JSON(http.StatusNotFound, gin.H{"message": "User not found or is already deleted"})
 		res.Status = http.StatusNotFound
 		res.Error = "NA"
 		res.Message = "Customer not found or is already deleted"
 		res.Data = nil
-		return res, err
+		return res, apperrors.NotFound(res.Message)
 	}
 
-	if err != nil {
-		// msg := "User update failed"
-		// c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
-		res.Status = http.StatusInternalServerError
-		res.Error = err.Error()
-		res.Message = "Customer update failed"
-		res.Data = nil
-		return res, err
+	if len(changes) == 0 {
+		// the customer already has the requested names, so nothing was written
+		res.Status = http.StatusOK
+		res.Error = "NA"
+		res.Message = "Customer is already up to date"
+		res.Data = result
+		if result == nil {
+			res.Data = &mongo.UpdateResult{MatchedCount: 1}
+		}
+		return res, nil
 	}
 	// c.JSON(http.StatusOK, result)
 
@@ -259,21 +368,37 @@ func (s *customerService) UpdateCustomerByCustomerId(userId string, customerId s
 	return res, err
 }
 
-func (s *customerService) DeleteCustomerByCustomerId(customerId string) (response interfaces.Response, err error) {
-	var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+// DeleteCustomerByCustomerId deletes the customer only if it belongs to
+// userId; a customer of another user is reported as not found.
+func (s *customerService) DeleteCustomerByCustomerId(ctx context.Context, actor auditModels.Actor, userId string, customerId string) (response interfaces.Response, err error) {
+	ctx, cancel := s.timeouts.Context(ctx, "DeleteCustomerByCustomerId")
 	defer cancel()
 
 	var res interfaces.Response
-	filter := bson.M{"customer_id": customerId}
-
-	result, err := s.customerRepository.DeleteCustomerByCustomerId(customerId, ctx, filter)
+	filter := bson.M{"customer_id": customerId, "user_id": userId}
+
+	var result *mongo.DeleteResult
+	var change customerChange
+	err = s.transactor.WithTransaction(ctx, func(txCtx context.Context) error {
+		before, beforeErr := s.customerRepository.GetCustomerByCustomerId(txCtx, customerId)
+		if beforeErr != nil || before.User_id != userId {
+			before = models.Customer{Customer_id: customerId, User_id: userId}
+		}
+		var deleteErr error
+		result, deleteErr = s.customerRepository.DeleteCustomerByCustomerId(customerId, txCtx, filter)
+		if deleteErr != nil || result.DeletedCount == 0 {
+			return deleteErr
+		}
+		change = customerChange{Action: auditModels.ActionDelete, Before: &before}
+		return s.recordChanges(txCtx, actor, change)
+	})
 	if err != nil {
 		// c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
-		res.Status = http.StatusInternalServerError
 		res.Error = err.Error()
 		res.Message = "Failed to delete customer"
 		res.Data = nil
-		return res, nil
+		res.Status, err = database.ClassifyError(err, "Customer not found or is already deleted")
+		return res, err
 	}
 
 	if result.DeletedCount == 0 {
@@ -282,32 +407,54 @@ func (s *customerService) DeleteCustomerByCustomerId(customerId string) (respons
 		res.Error = "NA"
 		res.Message = "Customer not found or is already deleted"
 		res.Data = nil
-		return res, nil
+		return res, apperrors.NotFound(res.Message)
 	}
 
 	// c.JSON(http.StatusOK, gin.H{"message": "User deleted", "userId": userId})
 	res.Status = http.StatusOK
 	res.Error = "NA"
 	res.Message = "Customer deleted successfully"
-	res.Data = "customer_id: " + customerId
+	res.Data = models.CustomerRef{User_id: userId, Customer_id: customerId}
 	return res, nil
 }
 
-func (s *customerService) DeleteCustomersByUserId(userId string) (response interfaces.Response, err error) {
-	var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+func (s *customerService) DeleteCustomersByUserId(ctx context.Context, actor auditModels.Actor, userId string) (response interfaces.Response, err error) {
+	ctx, cancel := s.timeouts.Context(ctx, "DeleteCustomersByUserId")
 	defer cancel()
 
 	var res interfaces.Response
 	filter := bson.M{"user_id": userId}
 
-	result, err := s.customerRepository.DeleteCustomersByUserId(userId, ctx, filter)
+	var result *mongo.DeleteResult
+	var changes []customerChange
+	err = s.transactor.WithTransaction(ctx, func(txCtx context.Context) error {
+		var before []models.Customer
+		cursor, findErr := s.customerRepository.FindCustomers(txCtx, filter, 0, 0)
+		if findErr == nil {
+			findErr = cursor.All(txCtx, &before)
+		}
+		if findErr != nil {
+			return findErr
+		}
+
+		var deleteErr error
+		result, deleteErr = s.customerRepository.DeleteCustomersByUserId(userId, txCtx, filter)
+		if deleteErr != nil || result.DeletedCount == 0 {
+			return deleteErr
+		}
+		changes = make([]customerChange, 0, len(before))
+		for i := range before {
+			changes = append(changes, customerChange{Action: auditModels.ActionDelete, Before: &before[i]})
+		}
+		return s.recordChanges(txCtx, actor, changes...)
+	})
 	if err != nil {
 		// c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
-		res.Status = http.StatusInternalServerError
 		res.Error = err.Error()
 		res.Message = "Failed to delete customer"
 		res.Data = nil
-		return res, nil
+		res.Status, err = database.ClassifyError(err, "Customer not found or is already deleted")
+		return res, err
 	}
 
 	if result.DeletedCount == 0 {
@@ -316,14 +463,14 @@ func (s *customerService) DeleteCustomersByUserId(userId string) (response inter
 		res.Error = "NA"
 		res.Message = "Customer not found or is already deleted"
 		res.Data = nil
-		return res, nil
+		return res, apperrors.NotFound(res.Message)
 	}
 
 	// c.JSON(http.StatusOK, gin.H{"message": "User deleted", "userId": userId})
 	res.Status = http.StatusOK
 	res.Error = "NA"
 	res.Message = "Customer deleted successfully"
-	res.Data = "user_id: " + userId
+	res.Data = models.CustomerRef{User_id: userId}
 	return res, nil
 }
 
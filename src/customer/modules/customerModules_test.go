@@ -0,0 +1,154 @@
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	auditInterfaces "somdeep-demo-app/src/audit/interfaces"
+	auditModels "somdeep-demo-app/src/audit/models"
+	"somdeep-demo-app/src/customer/interfaces"
+	"somdeep-demo-app/src/customer/models"
+	eventModels "somdeep-demo-app/src/events/models"
+	"somdeep-demo-app/src/timeouts"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type stubTransactor struct{}
+
+func (stubTransactor) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (stubTransactor) Enabled() bool { return true }
+
+// customerStore holds customers by ID and deletes the ones a filter on
+// customer_id and user_id matches.
+type customerStore struct {
+	interfaces.CustomerRepository
+	customers map[string]models.Customer
+}
+
+func (r *customerStore) GetCustomerByCustomerId(ctx context.Context, customerId string) (models.Customer, error) {
+	customer, ok := r.customers[customerId]
+	if !ok {
+		return models.Customer{}, mongo.ErrNoDocuments
+	}
+	return customer, nil
+}
+
+func (r *customerStore) DeleteCustomerByCustomerId(customerId string, ctx context.Context, filter primitive.M) (*mongo.DeleteResult, error) {
+	customer, ok := r.customers[filter["customer_id"].(string)]
+	if !ok || customer.User_id != filter["user_id"] {
+		return &mongo.DeleteResult{}, nil
+	}
+	delete(r.customers, customer.Customer_id)
+	return &mongo.DeleteResult{DeletedCount: 1}, nil
+}
+
+type stubAuditService struct {
+	auditInterfaces.AuditService
+	entries []auditModels.AuditEntry
+}
+
+func (s *stubAuditService) Record(ctx context.Context, actor auditModels.Actor, entries ...auditModels.AuditEntry) error {
+	s.entries = append(s.entries, entries...)
+	return nil
+}
+
+type stubPublisher struct {
+	events []eventModels.Event
+}
+
+func (p *stubPublisher) Publish(ctx context.Context, events ...eventModels.Event) error {
+	p.events = append(p.events, events...)
+	return nil
+}
+
+func (p *stubPublisher) Close() error { return nil }
+
+func TestDeleteCustomerByCustomerId(t *testing.T) {
+	tests := []struct {
+		name   string
+		userId string
+		status int
+	}{
+		{name: "own customer", userId: "u-1", status: http.StatusOK},
+		{name: "customer of another user", userId: "u-2", status: http.StatusNotFound},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := &customerStore{customers: map[string]models.Customer{"c-1": {Customer_id: "c-1", User_id: "u-1"}}}
+			audit := &stubAuditService{}
+			publisher := &stubPublisher{}
+			service := NewCustomerService(store, nil, audit, publisher, nil, stubTransactor{}, timeouts.Defaults())
+
+			response, _ := service.DeleteCustomerByCustomerId(context.Background(), auditModels.Actor{}, test.userId, "c-1")
+			if response.Status != test.status {
+				t.Fatalf("status = %d, want %d", response.Status, test.status)
+			}
+			if test.status != http.StatusOK {
+				if _, ok := store.customers["c-1"]; !ok || len(audit.entries) != 0 || len(publisher.events) != 0 {
+					t.Errorf("the customer of another user was deleted or recorded")
+				}
+				return
+			}
+
+			if response.Data != (models.CustomerRef{User_id: "u-1", Customer_id: "c-1"}) {
+				t.Errorf("data = %#v, want the deleted customer's ref", response.Data)
+			}
+			if len(audit.entries) != 1 || audit.entries[0].Parent_id != "u-1" {
+				t.Errorf("audit entries = %+v, want one under u-1", audit.entries)
+			}
+			if len(publisher.events) != 1 {
+				t.Fatalf("published %d events, want 1", len(publisher.events))
+			}
+			var payload eventModels.CustomerDeletedPayload
+			if err := json.Unmarshal(publisher.events[0].Data, &payload); err != nil {
+				t.Fatal(err)
+			}
+			if payload.User_id != "u-1" || payload.Customer_id != "c-1" {
+				t.Errorf("event payload = %+v, want c-1 of u-1", payload)
+			}
+		})
+	}
+}
+
+// updateStore updates the customers a filter on customer_id and user_id
+// matches and keeps the last filter it was given.
+type updateStore struct {
+	customerStore
+	filter primitive.M
+}
+
+func (r *updateStore) UpdateCustomerByCustomerId(ctx context.Context, opt options.UpdateOptions, filter primitive.M, updateObject primitive.D) (*mongo.UpdateResult, error) {
+	r.filter = filter
+	customer, ok := r.customers[filter["customer_id"].(string)]
+	if !ok || customer.User_id != filter["user_id"] {
+		return &mongo.UpdateResult{}, nil
+	}
+	return &mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 1}, nil
+}
+
+func TestUpdateCustomerByCustomerIdIsScopedToTheUser(t *testing.T) {
+	store := &updateStore{customerStore: customerStore{customers: map[string]models.Customer{
+		"c-1": {Customer_id: "c-1", User_id: "u-1", First_name: ptr("Ada")},
+	}}}
+	audit := &stubAuditService{}
+	service := NewCustomerService(store, stubUserRepository{}, audit, &stubPublisher{}, nil, stubTransactor{}, timeouts.Defaults())
+
+	response, err := service.UpdateCustomerByCustomerId(context.Background(), auditModels.Actor{}, "u-1", "c-1", models.Customer{First_name: ptr("Grace")})
+	if err != nil || response.Status != http.StatusOK {
+		t.Fatalf("status = %d, error = %v, want 200", response.Status, err)
+	}
+	if store.filter["customer_id"] != "c-1" || store.filter["user_id"] != "u-1" {
+		t.Errorf("filter = %v, want c-1 of u-1", store.filter)
+	}
+	if len(audit.entries) != 1 {
+		t.Errorf("audit entries = %+v, want one update", audit.entries)
+	}
+}
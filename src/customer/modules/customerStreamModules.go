@@ -0,0 +1,69 @@
+package modules
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"somdeep-demo-app/src/customer/interfaces"
+	"somdeep-demo-app/src/database"
+	eventModels "somdeep-demo-app/src/events/models"
+)
+
+// StreamCustomerChanges follows the customer events of one user, resuming
+// after lastEventId when it is given. The channel is closed when ctx is done
+// or the underlying stream breaks.
+func (s *customerService) StreamCustomerChanges(ctx context.Context, userId string, lastEventId string) (changes <-chan eventModels.StreamedEvent, response interfaces.Response, err error) {
+	var res interfaces.Response
+
+	if s.eventStream == nil {
+		res.Status = http.StatusServiceUnavailable
+		res.Error = "NA"
+		res.Message = "Customer change stream is not available"
+		res.Data = nil
+		return nil, res, errors.New(res.Message)
+	}
+
+	// only opening the stream is bounded, not how long the client follows it
+	lookupCtx, cancel := s.timeouts.Context(ctx, "StreamCustomerChanges")
+	defer cancel()
+	_, err = s.userRepository.GetUserByUserId(lookupCtx, userId)
+	if err != nil {
+		res.Error = err.Error()
+		res.Message = "The user associated with customer is not present or is deleted"
+		res.Data = nil
+		res.Status, err = database.ClassifyError(err, res.Message)
+		return nil, res, err
+	}
+
+	events, err := s.eventStream.Follow(ctx, lastEventId)
+	if err != nil {
+		res.Status = http.StatusServiceUnavailable
+		res.Error = err.Error()
+		res.Message = "Customer change stream is not available"
+		res.Data = nil
+		return nil, res, err
+	}
+
+	filtered := make(chan eventModels.StreamedEvent)
+	go func() {
+		defer close(filtered)
+		for message := range events {
+			if !message.Reset && (!strings.HasPrefix(message.Event.Type, "customer.") || message.Event.UserId() != userId) {
+				continue
+			}
+			select {
+			case filtered <- message:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	res.Status = http.StatusOK
+	res.Error = "NA"
+	res.Message = "Streaming customer changes"
+	res.Data = nil
+	return filtered, res, nil
+}
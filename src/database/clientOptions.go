@@ -0,0 +1,125 @@
+package database
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"somdeep-demo-app/src/config"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+)
+
+// clientOptions applies the URL and then whatever cfg sets on top of it.
+func clientOptions(cfg config.Mongo) (*options.ClientOptions, error) {
+	clientOptions := options.Client().ApplyURI(cfg.URL).
+		SetMonitor(commandMonitor()).
+		SetPoolMonitor(poolMonitor())
+
+	if cfg.Max_pool_size > 0 {
+		clientOptions.SetMaxPoolSize(uint64(cfg.Max_pool_size))
+	}
+	if cfg.Min_pool_size > 0 {
+		clientOptions.SetMinPoolSize(uint64(cfg.Min_pool_size))
+	}
+	if cfg.Connect_timeout > 0 {
+		clientOptions.SetConnectTimeout(cfg.Connect_timeout)
+	}
+	if cfg.Socket_timeout > 0 {
+		clientOptions.SetSocketTimeout(cfg.Socket_timeout)
+	}
+	if cfg.Server_selection_timeout > 0 {
+		clientOptions.SetServerSelectionTimeout(cfg.Server_selection_timeout)
+	}
+
+	if cfg.Read_preference != "" {
+		mode, err := readpref.ModeFromString(cfg.Read_preference)
+		if err != nil {
+			return nil, err
+		}
+		preference, err := readpref.New(mode)
+		if err != nil {
+			return nil, err
+		}
+		clientOptions.SetReadPreference(preference)
+	}
+	if cfg.Read_concern != "" {
+		clientOptions.SetReadConcern(&readconcern.ReadConcern{Level: cfg.Read_concern})
+	}
+	if cfg.Write_concern != "" {
+		var w interface{} = cfg.Write_concern
+		if servers, err := strconv.Atoi(cfg.Write_concern); err == nil {
+			w = servers
+		}
+		clientOptions.SetWriteConcern(&writeconcern.WriteConcern{W: w})
+	}
+	if cfg.Retry_writes != nil {
+		clientOptions.SetRetryWrites(*cfg.Retry_writes)
+	}
+	if cfg.Compressors != "" {
+		var compressors []string
+		for _, compressor := range strings.Split(cfg.Compressors, ",") {
+			if compressor = strings.TrimSpace(compressor); compressor != "" {
+				compressors = append(compressors, compressor)
+			}
+		}
+		clientOptions.SetCompressors(compressors)
+	}
+
+	if cfg.TLS || cfg.TLS_ca_file != "" || cfg.TLS_cert_file != "" {
+		tlsConfig, err := newTLSConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		clientOptions.SetTLSConfig(tlsConfig)
+	}
+
+	if cfg.Username != "" || cfg.Auth_mechanism != "" {
+		credential := options.Credential{
+			Username:      cfg.Username,
+			AuthSource:    cfg.Auth_source,
+			AuthMechanism: cfg.Auth_mechanism,
+		}
+		if cfg.Password_file != "" {
+			password, err := os.ReadFile(cfg.Password_file)
+			if err != nil {
+				return nil, fmt.Errorf("reading the MongoDB password: %w", err)
+			}
+			credential.Password = strings.TrimRight(string(password), "\r\n")
+			credential.PasswordSet = true
+		}
+		clientOptions.SetAuth(credential)
+	}
+
+	return clientOptions, clientOptions.Validate()
+}
+
+// newTLSConfig trusts the CAs in the CA file, or the system's when there is
+// none, and presents the client certificate when there is one.
+func newTLSConfig(cfg config.Mongo) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.TLS_ca_file != "" {
+		pem, err := os.ReadFile(cfg.TLS_ca_file)
+		if err != nil {
+			return nil, fmt.Errorf("reading the MongoDB CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in the MongoDB CA file %s", cfg.TLS_ca_file)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if cfg.TLS_cert_file != "" {
+		certificate, err := tls.LoadX509KeyPair(cfg.TLS_cert_file, cfg.TLS_key_file)
+		if err != nil {
+			return nil, fmt.Errorf("loading the MongoDB client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+	return tlsConfig, nil
+}
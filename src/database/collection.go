@@ -0,0 +1,279 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"somdeep-demo-app/src/tenant"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Collection is a collection of whichever tenant the context of each call is
+// for. Calls without a tenant fail with tenant.ErrMissing while tenancy is on.
+// In shared mode every filter is narrowed to the tenant, every document is
+// stamped with it and every index leads with it, so callers never spell out
+// tenant_id themselves.
+type Collection struct {
+	tenancy *Tenancy
+	name    string
+}
+
+// collection returns the collection the tenant of ctx uses and, in shared
+// mode, the tenant_id it has to be filtered on.
+func (c *Collection) collection(ctx context.Context) (*mongo.Collection, string, error) {
+	db, tenantId, err := c.tenancy.scope(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	return db.Collection(c.name), tenantId, nil
+}
+
+func (c *Collection) Aggregate(ctx context.Context, pipeline mongo.Pipeline, opts ...*options.AggregateOptions) (*mongo.Cursor, error) {
+	collection, tenantId, err := c.collection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return collection.Aggregate(ctx, scopePipeline(pipeline, tenantField, tenantId), opts...)
+}
+
+func (c *Collection) BulkWrite(ctx context.Context, models []mongo.WriteModel, opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error) {
+	collection, tenantId, err := c.collection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if tenantId != "" {
+		if models, err = scopeWriteModels(models, tenantId); err != nil {
+			return nil, err
+		}
+	}
+	return collection.BulkWrite(ctx, models, opts...)
+}
+
+func (c *Collection) CountDocuments(ctx context.Context, filter interface{}, opts ...*options.CountOptions) (int64, error) {
+	collection, tenantId, err := c.collection(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return collection.CountDocuments(ctx, scopeFilter(filter, tenantId), opts...)
+}
+
+func (c *Collection) DeleteMany(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	collection, tenantId, err := c.collection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return collection.DeleteMany(ctx, scopeFilter(filter, tenantId), opts...)
+}
+
+func (c *Collection) DeleteOne(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	collection, tenantId, err := c.collection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return collection.DeleteOne(ctx, scopeFilter(filter, tenantId), opts...)
+}
+
+func (c *Collection) Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (*mongo.Cursor, error) {
+	collection, tenantId, err := c.collection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return collection.Find(ctx, scopeFilter(filter, tenantId), opts...)
+}
+
+func (c *Collection) FindOne(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) *mongo.SingleResult {
+	collection, tenantId, err := c.collection(ctx)
+	if err != nil {
+		return mongo.NewSingleResultFromDocument(bson.D{}, err, nil)
+	}
+	return collection.FindOne(ctx, scopeFilter(filter, tenantId), opts...)
+}
+
+func (c *Collection) FindOneAndUpdate(ctx context.Context, filter interface{}, update interface{}, opts ...*options.FindOneAndUpdateOptions) *mongo.SingleResult {
+	collection, tenantId, err := c.collection(ctx)
+	if err != nil {
+		return mongo.NewSingleResultFromDocument(bson.D{}, err, nil)
+	}
+	return collection.FindOneAndUpdate(ctx, scopeFilter(filter, tenantId), update, opts...)
+}
+
+func (c *Collection) InsertOne(ctx context.Context, document interface{}, opts ...*options.InsertOneOptions) (*mongo.InsertOneResult, error) {
+	collection, tenantId, err := c.collection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if tenantId != "" {
+		if document, err = stampDocument(document, tenantId); err != nil {
+			return nil, err
+		}
+	}
+	return collection.InsertOne(ctx, document, opts...)
+}
+
+func (c *Collection) InsertMany(ctx context.Context, documents []interface{}, opts ...*options.InsertManyOptions) (*mongo.InsertManyResult, error) {
+	collection, tenantId, err := c.collection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if tenantId != "" {
+		stamped := make([]interface{}, len(documents))
+		for i, document := range documents {
+			if stamped[i], err = stampDocument(document, tenantId); err != nil {
+				return nil, err
+			}
+		}
+		documents = stamped
+	}
+	return collection.InsertMany(ctx, documents, opts...)
+}
+
+func (c *Collection) UpdateOne(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	collection, tenantId, err := c.collection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return collection.UpdateOne(ctx, scopeFilter(filter, tenantId), update, opts...)
+}
+
+func (c *Collection) UpdateByID(ctx context.Context, id interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	return c.UpdateOne(ctx, bson.D{{Key: "_id", Value: id}}, update, opts...)
+}
+
+// Watch follows the changes of the tenant of ctx. With a context from
+// tenant.WithAllTenants it follows every tenant's instead, for the workers
+// that serve all of them; the change documents then say which tenant they
+// belong to in ns.db or fullDocument.tenant_id.
+func (c *Collection) Watch(ctx context.Context, pipeline mongo.Pipeline, opts ...*options.ChangeStreamOptions) (*mongo.ChangeStream, error) {
+	if tenant.AllTenants(ctx) && c.tenancy.Enabled() {
+		if c.tenancy.Mode() == TenancyShared {
+			return c.tenancy.db.Collection(c.name).Watch(ctx, pipeline, opts...)
+		}
+		match := bson.D{{Key: "$match", Value: bson.M{"ns.coll": c.name, "ns.db": c.tenancy.tenantDatabases()}}}
+		return c.tenancy.client.Watch(ctx, append(mongo.Pipeline{match}, pipeline...), opts...)
+	}
+	collection, tenantId, err := c.collection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return collection.Watch(ctx, scopePipeline(pipeline, "fullDocument."+tenantField, tenantId), opts...)
+}
+
+// CreateIndexes creates models in the collection of the tenant of ctx. In
+// shared mode each index leads with tenant_id, so lookups stay selective and
+// unique keys are unique per tenant. TTL indexes are left alone because they
+// have to be on a single field.
+func (c *Collection) CreateIndexes(ctx context.Context, models []mongo.IndexModel) ([]string, error) {
+	collection, tenantId, err := c.collection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if tenantId != "" {
+		scoped := make([]mongo.IndexModel, len(models))
+		for i, model := range models {
+			scoped[i] = model
+			if model.Options != nil && model.Options.ExpireAfterSeconds != nil {
+				continue
+			}
+			keys, err := toDocument(model.Keys)
+			if err != nil {
+				return nil, err
+			}
+			scoped[i].Keys = append(bson.D{{Key: tenantField, Value: 1}}, keys...)
+		}
+		models = scoped
+	}
+	return collection.Indexes().CreateMany(ctx, models)
+}
+
+// scopeFilter narrows filter to tenantId, or leaves it as is without one.
+func scopeFilter(filter interface{}, tenantId string) interface{} {
+	if tenantId == "" {
+		return filter
+	}
+	scope := bson.D{{Key: tenantField, Value: tenantId}}
+	if filter == nil {
+		return scope
+	}
+	return bson.D{{Key: "$and", Value: bson.A{scope, filter}}}
+}
+
+// scopePipeline puts a $match on field in front of pipeline.
+func scopePipeline(pipeline mongo.Pipeline, field string, tenantId string) mongo.Pipeline {
+	if tenantId == "" {
+		return pipeline
+	}
+	match := bson.D{{Key: "$match", Value: bson.D{{Key: field, Value: tenantId}}}}
+	return append(mongo.Pipeline{match}, pipeline...)
+}
+
+// stampDocument returns document with its tenant_id set to tenantId.
+func stampDocument(document interface{}, tenantId string) (bson.D, error) {
+	fields, err := toDocument(document)
+	if err != nil {
+		return nil, err
+	}
+	stamped := make(bson.D, 0, len(fields)+1)
+	for _, field := range fields {
+		if field.Key != tenantField {
+			stamped = append(stamped, field)
+		}
+	}
+	return append(stamped, bson.E{Key: tenantField, Value: tenantId}), nil
+}
+
+func scopeWriteModels(models []mongo.WriteModel, tenantId string) ([]mongo.WriteModel, error) {
+	scoped := make([]mongo.WriteModel, len(models))
+	for i, model := range models {
+		switch model := model.(type) {
+		case *mongo.InsertOneModel:
+			document, err := stampDocument(model.Document, tenantId)
+			if err != nil {
+				return nil, err
+			}
+			scoped[i] = &mongo.InsertOneModel{Document: document}
+		case *mongo.ReplaceOneModel:
+			replacement, err := stampDocument(model.Replacement, tenantId)
+			if err != nil {
+				return nil, err
+			}
+			copied := *model
+			copied.Filter, copied.Replacement = scopeFilter(model.Filter, tenantId), replacement
+			scoped[i] = &copied
+		case *mongo.UpdateOneModel:
+			copied := *model
+			copied.Filter = scopeFilter(model.Filter, tenantId)
+			scoped[i] = &copied
+		case *mongo.UpdateManyModel:
+			copied := *model
+			copied.Filter = scopeFilter(model.Filter, tenantId)
+			scoped[i] = &copied
+		case *mongo.DeleteOneModel:
+			copied := *model
+			copied.Filter = scopeFilter(model.Filter, tenantId)
+			scoped[i] = &copied
+		case *mongo.DeleteManyModel:
+			copied := *model
+			copied.Filter = scopeFilter(model.Filter, tenantId)
+			scoped[i] = &copied
+		default:
+			return nil, fmt.Errorf("cannot scope a %T to a tenant", model)
+		}
+	}
+	return scoped, nil
+}
+
+// toDocument converts a struct, map or document to a bson.D.
+func toDocument(value interface{}) (bson.D, error) {
+	if document, ok := value.(bson.D); ok {
+		return document, nil
+	}
+	data, err := bson.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var document bson.D
+	err = bson.Unmarshal(data, &document)
+	return document, err
+}
@@ -0,0 +1,117 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestScopeFilter(t *testing.T) {
+	scope := bson.D{{Key: tenantField, Value: "acme"}}
+	filter := bson.M{"user_id": "u-1"}
+
+	tests := []struct {
+		name     string
+		filter   interface{}
+		tenantId string
+		want     interface{}
+	}{
+		{name: "no tenant", filter: filter, want: filter},
+		{name: "no filter", filter: nil, tenantId: "acme", want: scope},
+		{name: "filter", filter: filter, tenantId: "acme", want: bson.D{{Key: "$and", Value: bson.A{scope, filter}}}},
+		// a filter naming another tenant still has to match acme too
+		{name: "filter on tenant_id", filter: bson.M{tenantField: "globex"}, tenantId: "acme", want: bson.D{{Key: "$and", Value: bson.A{scope, bson.M{tenantField: "globex"}}}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := scopeFilter(test.filter, test.tenantId); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("scopeFilter = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestStampDocument(t *testing.T) {
+	type customer struct {
+		Customer_id string `bson:"customer_id"`
+		Tenant_id   string `bson:"tenant_id,omitempty"`
+	}
+	tests := []struct {
+		name     string
+		document interface{}
+	}{
+		{name: "struct", document: customer{Customer_id: "c-1"}},
+		{name: "struct claiming another tenant", document: customer{Customer_id: "c-1", Tenant_id: "globex"}},
+		{name: "map", document: bson.M{"customer_id": "c-1"}},
+		{name: "document claiming another tenant", document: bson.D{{Key: tenantField, Value: "globex"}, {Key: "customer_id", Value: "c-1"}}},
+	}
+	want := bson.D{{Key: "customer_id", Value: "c-1"}, {Key: tenantField, Value: "acme"}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stamped, err := stampDocument(test.document, "acme")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(stamped, want) {
+				t.Errorf("stamped = %v, want %v", stamped, want)
+			}
+		})
+	}
+
+	if _, err := stampDocument("c-1", "acme"); err == nil {
+		t.Error("stamping a string succeeded")
+	}
+}
+
+func TestScopeWriteModels(t *testing.T) {
+	filter := bson.M{"customer_id": "c-1"}
+	scoped := bson.D{{Key: "$and", Value: bson.A{bson.D{{Key: tenantField, Value: "acme"}}, filter}}}
+	update := bson.M{"$set": bson.M{"first_name": "Ada"}}
+	stamped := bson.D{{Key: "customer_id", Value: "c-1"}, {Key: tenantField, Value: "acme"}}
+
+	models := []mongo.WriteModel{
+		mongo.NewInsertOneModel().SetDocument(bson.M{"customer_id": "c-1", tenantField: "globex"}),
+		mongo.NewReplaceOneModel().SetFilter(filter).SetReplacement(bson.M{"customer_id": "c-1"}).SetUpsert(true),
+		mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update),
+		mongo.NewUpdateManyModel().SetFilter(filter).SetUpdate(update),
+		mongo.NewDeleteOneModel().SetFilter(filter),
+		mongo.NewDeleteManyModel().SetFilter(filter),
+	}
+	got, err := scopeWriteModels(models, "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if insert := got[0].(*mongo.InsertOneModel); !reflect.DeepEqual(insert.Document, stamped) {
+		t.Errorf("insert document = %v, want %v", insert.Document, stamped)
+	}
+	replace := got[1].(*mongo.ReplaceOneModel)
+	if !reflect.DeepEqual(replace.Filter, scoped) || !reflect.DeepEqual(replace.Replacement, stamped) || replace.Upsert == nil || !*replace.Upsert {
+		t.Errorf("replace = %+v, want a scoped filter, a stamped replacement and the upsert kept", replace)
+	}
+	filters := []interface{}{
+		got[2].(*mongo.UpdateOneModel).Filter,
+		got[3].(*mongo.UpdateManyModel).Filter,
+		got[4].(*mongo.DeleteOneModel).Filter,
+		got[5].(*mongo.DeleteManyModel).Filter,
+	}
+	for i, filter := range filters {
+		if !reflect.DeepEqual(filter, scoped) {
+			t.Errorf("filter of model %d = %v, want %v", i+2, filter, scoped)
+		}
+	}
+	if update := got[2].(*mongo.UpdateOneModel).Update; !reflect.DeepEqual(update, bson.M{"$set": bson.M{"first_name": "Ada"}}) {
+		t.Errorf("update = %v, want it unchanged", update)
+	}
+
+	// the caller's models are left alone, so a retry scopes them afresh
+	if models[2].(*mongo.UpdateOneModel).Filter.(bson.M)["customer_id"] != "c-1" || len(models[2].(*mongo.UpdateOneModel).Filter.(bson.M)) != 1 {
+		t.Errorf("the caller's filter was changed to %v", models[2].(*mongo.UpdateOneModel).Filter)
+	}
+
+	if _, err = scopeWriteModels([]mongo.WriteModel{nil}, "acme"); err == nil {
+		t.Error("scoping an unknown write model succeeded")
+	}
+}
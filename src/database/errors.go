@@ -0,0 +1,39 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"somdeep-demo-app/src/apperrors"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/topology"
+)
+
+// Unavailable reports whether err means MongoDB could not be reached or did
+// not answer in time, as opposed to rejecting the operation.
+func Unavailable(err error) bool {
+	var selectionErr topology.ServerSelectionError
+	return mongo.IsNetworkError(err) ||
+		mongo.IsTimeout(err) ||
+		errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, mongo.ErrClientDisconnected) ||
+		errors.As(err, &selectionErr)
+}
+
+// ClassifyError maps a failed database call onto the status it is reported
+// with. A missing document becomes apperrors.NotFound(notFound) and a
+// duplicate key a conflict; otherwise err is returned unchanged with 503 when
+// the database is unavailable and 500 for anything else.
+func ClassifyError(err error, notFound string) (int, error) {
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return http.StatusNotFound, apperrors.NotFound(notFound)
+	case mongo.IsDuplicateKeyError(err):
+		return http.StatusConflict, apperrors.Conflict("A record with the same key already exists")
+	case Unavailable(err):
+		return http.StatusServiceUnavailable, err
+	}
+	return http.StatusInternalServerError, err
+}
@@ -0,0 +1,61 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"somdeep-demo-app/src/apperrors"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestClassifyError(t *testing.T) {
+	duplicate := mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 11000, Message: "E11000 duplicate key error"}}}
+	driverErr := errors.New("(BadValue) unknown operator: $foo")
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		kind   string
+		same   bool
+	}{
+		{name: "no documents", err: mongo.ErrNoDocuments, status: http.StatusNotFound, kind: "not-found"},
+		{name: "wrapped no documents", err: fmt.Errorf("finding user: %w", mongo.ErrNoDocuments), status: http.StatusNotFound, kind: "not-found"},
+		{name: "duplicate key", err: duplicate, status: http.StatusConflict, kind: "conflict"},
+		{name: "deadline exceeded", err: context.DeadlineExceeded, status: http.StatusServiceUnavailable, same: true},
+		{name: "client disconnected", err: mongo.ErrClientDisconnected, status: http.StatusServiceUnavailable, same: true},
+		{name: "anything else", err: driverErr, status: http.StatusInternalServerError, same: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			status, err := ClassifyError(test.err, "User not found")
+			if status != test.status {
+				t.Errorf("status = %d, want %d", status, test.status)
+			}
+			if test.same {
+				if err != test.err {
+					t.Errorf("err = %v, want the error unchanged", err)
+				}
+				return
+			}
+			var typed apperrors.Error
+			if !errors.As(err, &typed) {
+				t.Fatalf("err = %T, want an apperrors.Error", err)
+			}
+			if typed.Kind() != test.kind || typed.Status() != test.status {
+				t.Errorf("kind, status = %q, %d, want %q, %d", typed.Kind(), typed.Status(), test.kind, test.status)
+			}
+		})
+	}
+}
+
+func TestClassifyErrorNotFoundMessage(t *testing.T) {
+	_, err := ClassifyError(mongo.ErrNoDocuments, "Customer not found")
+	if err.Error() != "Customer not found" {
+		t.Errorf("err = %q, want the not found message", err)
+	}
+}
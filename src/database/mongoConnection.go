@@ -3,23 +3,25 @@ package database
 import (
 	"context"
 	"fmt"
-	"log"
-	"os"
+	"log/slog"
+	"somdeep-demo-app/src/config"
 	"time"
 
-	"github.com/joho/godotenv"
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-func DBinstance() *mongo.Client {
-	err := godotenv.Load(".env")
+// maxPingBackoff caps the pause between startup pings.
+const maxPingBackoff = 30 * time.Second
+
+// DBinstance returns a client for the server cfg names once the server
+// answers a ping. Failed pings are retried with backoff, up to
+// cfg.Ping_attempts in all or until ctx ends.
+func DBinstance(ctx context.Context, cfg config.Mongo) (*mongo.Client, error) {
+	clientOptions, err := clientOptions(cfg)
 	if err != nil {
-		log.Fatal("Error loading the .env file")
+		return nil, fmt.Errorf("invalid MongoDB options: %w", err)
 	}
 
-	MongoDB := os.Getenv("MONGODB_URL")
-
 	// I have commented this way of connecting to mongodb because it has been Deprecated
 
 	// client, err := mongo.NewClient(options.Client().ApplyURI(MongoDB))
@@ -35,20 +37,40 @@ func DBinstance() *mongo.Client {
 	// 	log.Fatal(err)
 	// }
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(MongoDB))
+	client, err := mongo.Connect(ctx, clientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return nil, fmt.Errorf("connecting to MongoDB: %w", err)
 	}
-	fmt.Println("Connected to MongoDB!")
 
-	return client
+	backoff := cfg.Ping_backoff
+	for attempt := 1; ; attempt++ {
+		// the ping goes wherever the read preference sends reads
+		if err = client.Ping(ctx, nil); err == nil {
+			break
+		}
+		if attempt >= cfg.Ping_attempts || ctx.Err() != nil {
+			client.Disconnect(context.Background())
+			return nil, fmt.Errorf("MongoDB did not answer %d pings: %w", attempt, err)
+		}
+		slog.Warn("mongo: ping failed, retrying", "attempt", attempt, "retry_in", backoff, "error", err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+		}
+		backoff = min(2*backoff, maxPingBackoff)
+	}
+	slog.Info("Connected to MongoDB!", "database", cfg.Database)
+
+	return client, nil
 }
 
-var Client *mongo.Client = DBinstance()
+// Database returns the database cfg names, which every repository keeps its
+// collections in.
+func Database(client *mongo.Client, cfg config.Mongo) *mongo.Database {
+	return client.Database(cfg.Database)
+}
 
-func OpenCollection(client *mongo.Client, collectionName string) *mongo.Collection {
-	var collection *mongo.Collection = client.Database("users-project").Collection(collectionName)
+func OpenCollection(db *mongo.Database, collectionName string) *mongo.Collection {
+	var collection *mongo.Collection = db.Collection(collectionName)
 	return collection
 }
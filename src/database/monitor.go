@@ -0,0 +1,98 @@
+package database
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+
+	"somdeep-demo-app/src/metrics"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo/otelmongo"
+)
+
+// commandMonitor traces, counts and times every command the driver sends and
+// logs it at debug level, with the ID of the request the repository was
+// called for. Command bodies are left out of both spans and logs since they
+// hold user data.
+func commandMonitor() *event.CommandMonitor {
+	tracer := otelmongo.NewMonitor(otelmongo.WithCommandAttributeDisabled(true))
+	return &event.CommandMonitor{
+		Started: tracer.Started,
+		Succeeded: func(ctx context.Context, succeeded *event.CommandSucceededEvent) {
+			tracer.Succeeded(ctx, succeeded)
+			metrics.MongoCommands.WithLabelValues(succeeded.CommandName, "success").Inc()
+			metrics.MongoCommandDuration.WithLabelValues(succeeded.CommandName).Observe(succeeded.Duration.Seconds())
+			slog.DebugContext(ctx, "mongo: command succeeded",
+				"command", succeeded.CommandName,
+				"duration", succeeded.Duration,
+				"connection_id", succeeded.ConnectionID)
+		},
+		Failed: func(ctx context.Context, failed *event.CommandFailedEvent) {
+			tracer.Failed(ctx, failed)
+			metrics.MongoCommands.WithLabelValues(failed.CommandName, "failure").Inc()
+			metrics.MongoCommandDuration.WithLabelValues(failed.CommandName).Observe(failed.Duration.Seconds())
+			slog.WarnContext(ctx, "mongo: command failed",
+				"command", failed.CommandName,
+				"duration", failed.Duration,
+				"connection_id", failed.ConnectionID,
+				"error", failed.Failure)
+		},
+	}
+}
+
+// PoolStats is the state of the connection pools of every server together.
+type PoolStats struct {
+	Open              int64 `json:"open"`
+	In_use            int64 `json:"in_use"`
+	Checkouts         int64 `json:"checkouts"`
+	Checkout_failures int64 `json:"checkout_failures"`
+	Checkout_timeouts int64 `json:"checkout_timeouts"`
+}
+
+var pool struct {
+	open, inUse, checkouts, checkoutFailures, checkoutTimeouts atomic.Int64
+}
+
+// Pool returns the state of the connection pools as poolMonitor saw it.
+func Pool() PoolStats {
+	return PoolStats{
+		Open:              pool.open.Load(),
+		In_use:            pool.inUse.Load(),
+		Checkouts:         pool.checkouts.Load(),
+		Checkout_failures: pool.checkoutFailures.Load(),
+		Checkout_timeouts: pool.checkoutTimeouts.Load(),
+	}
+}
+
+// poolMonitor tracks the connection pool of each server: open and checked out
+// connections, checkouts, and checkouts that failed, timeouts among them.
+func poolMonitor() *event.PoolMonitor {
+	return &event.PoolMonitor{
+		Event: func(poolEvent *event.PoolEvent) {
+			switch poolEvent.Type {
+			case event.ConnectionCreated:
+				pool.open.Add(1)
+				metrics.MongoPoolConnections.WithLabelValues(poolEvent.Address).Inc()
+			case event.ConnectionClosed:
+				pool.open.Add(-1)
+				metrics.MongoPoolConnections.WithLabelValues(poolEvent.Address).Dec()
+			case event.GetSucceeded:
+				pool.checkouts.Add(1)
+				pool.inUse.Add(1)
+				metrics.MongoPoolCheckouts.WithLabelValues(poolEvent.Address).Inc()
+				metrics.MongoPoolInUse.WithLabelValues(poolEvent.Address).Inc()
+			case event.ConnectionReturned:
+				pool.inUse.Add(-1)
+				metrics.MongoPoolInUse.WithLabelValues(poolEvent.Address).Dec()
+			case event.GetFailed:
+				pool.checkoutFailures.Add(1)
+				metrics.MongoPoolCheckoutFailures.WithLabelValues(poolEvent.Address, poolEvent.Reason).Inc()
+				if poolEvent.Reason == event.ReasonTimedOut {
+					pool.checkoutTimeouts.Add(1)
+					slog.Warn("mongo: timed out waiting for a pooled connection", "address", poolEvent.Address)
+				}
+			}
+		},
+	}
+}
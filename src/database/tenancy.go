@@ -0,0 +1,129 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"somdeep-demo-app/src/config"
+	"somdeep-demo-app/src/tenant"
+	"sort"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	TenancyNone     = "none"
+	TenancyDatabase = "database"
+	TenancyShared   = "shared"
+)
+
+// tenantField is the field every document carries in shared mode.
+const tenantField = "tenant_id"
+
+// Tenancy decides where each tenant's documents live. With tenancy off every
+// collection is in the configured database. In database mode each tenant has
+// a database of its own, and in shared mode tenants share the configured
+// database's collections and every document carries its tenant_id. Either
+// way, a query is only sent once the tenant it is for is known.
+type Tenancy struct {
+	client *mongo.Client
+	db     *mongo.Database
+	mode   string
+	prefix string
+
+	mu          sync.Mutex
+	collections map[string]bool
+}
+
+func NewTenancy(client *mongo.Client, mongoConfig config.Mongo, cfg config.Tenancy) *Tenancy {
+	return &Tenancy{
+		client:      client,
+		db:          Database(client, mongoConfig),
+		mode:        cfg.Mode,
+		prefix:      cfg.Database_prefix,
+		collections: map[string]bool{},
+	}
+}
+
+// Enabled reports whether queries need a tenant.
+func (t *Tenancy) Enabled() bool {
+	return t.mode != TenancyNone
+}
+
+func (t *Tenancy) Mode() string {
+	return t.mode
+}
+
+// Database is the configured database. It holds the tenant registry and, in
+// shared mode, every tenant's documents.
+func (t *Tenancy) Database() *mongo.Database {
+	return t.db
+}
+
+// DatabaseName is the name of a tenant's own database in database mode.
+func (t *Tenancy) DatabaseName(tenantId string) string {
+	if t.mode != TenancyDatabase {
+		return t.db.Name()
+	}
+	return t.prefix + tenantId
+}
+
+// scope returns the database the tenant of ctx keeps its documents in and, in
+// shared mode, the tenant_id they are filtered on.
+func (t *Tenancy) scope(ctx context.Context) (*mongo.Database, string, error) {
+	if t.mode == TenancyNone {
+		return t.db, "", nil
+	}
+	tenantId := tenant.Id(ctx)
+	if tenantId == "" {
+		return nil, "", tenant.ErrMissing
+	}
+	if t.mode == TenancyShared {
+		return t.db, tenantId, nil
+	}
+	return t.client.Database(t.DatabaseName(tenantId)), "", nil
+}
+
+// Drop deletes everything a tenant stored: its database in database mode,
+// its documents in every collection in shared mode.
+func (t *Tenancy) Drop(ctx context.Context, tenantId string) error {
+	switch t.mode {
+	case TenancyDatabase:
+		return t.client.Database(t.DatabaseName(tenantId)).Drop(ctx)
+	case TenancyShared:
+		for _, name := range t.collectionNames() {
+			if _, err := t.db.Collection(name).DeleteMany(ctx, bson.D{{Key: tenantField, Value: tenantId}}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return errors.New("tenancy is off")
+}
+
+func (t *Tenancy) collectionNames() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	names := make([]string, 0, len(t.collections))
+	for name := range t.collections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// OpenTenantCollection returns the collection called collectionName of
+// whichever tenant each query is for.
+func OpenTenantCollection(t *Tenancy, collectionName string) *Collection {
+	t.mu.Lock()
+	t.collections[collectionName] = true
+	t.mu.Unlock()
+	return &Collection{tenancy: t, name: collectionName}
+}
+
+// tenantDatabases matches the databases of every tenant in database mode.
+func (t *Tenancy) tenantDatabases() bson.M {
+	return bson.M{"$regex": "^" + regexp.QuoteMeta(t.prefix)}
+}
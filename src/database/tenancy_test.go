@@ -0,0 +1,59 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"somdeep-demo-app/src/config"
+	"somdeep-demo-app/src/tenant"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestTenancyScope(t *testing.T) {
+	// the client is never used to reach a server, only to name databases
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://127.0.0.1:1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Disconnect(context.Background())
+
+	acme := tenant.WithTenant(context.Background(), "acme")
+	tests := []struct {
+		mode     string
+		ctx      context.Context
+		database string
+		tenantId string
+		err      error
+	}{
+		{mode: TenancyNone, ctx: context.Background(), database: "app"},
+		{mode: TenancyNone, ctx: acme, database: "app"},
+		{mode: TenancyDatabase, ctx: acme, database: "tenant_acme"},
+		{mode: TenancyDatabase, ctx: context.Background(), err: tenant.ErrMissing},
+		{mode: TenancyShared, ctx: acme, database: "app", tenantId: "acme"},
+		{mode: TenancyShared, ctx: context.Background(), err: tenant.ErrMissing},
+	}
+	for _, test := range tests {
+		tenancy := NewTenancy(client, config.Mongo{Database: "app"}, config.Tenancy{Mode: test.mode, Database_prefix: "tenant_"})
+		db, tenantId, err := tenancy.scope(test.ctx)
+		if test.err != nil {
+			if !errors.Is(err, test.err) {
+				t.Errorf("%s: error = %v, want %v", test.mode, err, test.err)
+			}
+			continue
+		}
+		if err != nil || db.Name() != test.database || tenantId != test.tenantId {
+			t.Errorf("%s: scope = %s, %q, %v, want %s, %q", test.mode, db.Name(), tenantId, err, test.database, test.tenantId)
+		}
+	}
+
+	tenancy := NewTenancy(client, config.Mongo{Database: "app"}, config.Tenancy{Mode: TenancyDatabase, Database_prefix: "tenant_"})
+	if name := tenancy.DatabaseName("acme"); name != "tenant_acme" {
+		t.Errorf("DatabaseName = %q, want tenant_acme", name)
+	}
+	if match := tenancy.tenantDatabases()["$regex"]; match != "^tenant_" {
+		t.Errorf("tenant databases match %v, want ^tenant_", match)
+	}
+}
@@ -0,0 +1,65 @@
+package database
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Transactor runs a unit of work atomically. Repositories join the transaction
+// simply by being called with the context passed to fn.
+type Transactor interface {
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+	Enabled() bool
+}
+
+type mongoTransactor struct {
+	client  *mongo.Client
+	enabled bool
+}
+
+// NewTransactor returns a transactor backed by client sessions. Transactions
+// need a replica set or sharded cluster; with enabled set to false (for a
+// standalone development server) fn simply runs without one.
+func NewTransactor(client *mongo.Client, enabled bool) Transactor {
+	return &mongoTransactor{
+		client:  client,
+		enabled: enabled,
+	}
+}
+
+func (t *mongoTransactor) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if !t.enabled {
+		return fn(ctx)
+	}
+
+	session, err := t.client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sessCtx)
+	})
+	return err
+}
+
+func (t *mongoTransactor) Enabled() bool {
+	return t.enabled
+}
+
+// SupportsTransactions reports whether the deployment client is connected to
+// is a replica set or a mongos, the topologies that support transactions.
+func SupportsTransactions(ctx context.Context, client *mongo.Client) bool {
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello)
+	if err != nil {
+		return false
+	}
+	return hello.SetName != "" || hello.Msg == "isdbgrid"
+}
@@ -0,0 +1,196 @@
+package broker
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const maxPayload = 1 << 20
+
+// NATSStandIn is a small in-memory broker that understands enough of the NATS
+// core protocol (PUB, SUB, UNSUB, PING/PONG) to run the service and its NATS
+// publisher locally or in tests without a real nats-server.
+type NATSStandIn struct {
+	listener net.Listener
+
+	// OnPublish, when set, is called for every message the broker receives.
+	OnPublish func(subject string, payload []byte)
+
+	mu      sync.Mutex
+	clients map[*standInClient]bool
+}
+
+type standInClient struct {
+	conn    net.Conn
+	writeMu sync.Mutex
+	writer  *bufio.Writer
+	subs    map[string]string // sid -> subject
+}
+
+func NewNATSStandIn(addr string) (*NATSStandIn, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &NATSStandIn{
+		listener: listener,
+		clients:  map[*standInClient]bool{},
+	}, nil
+}
+
+func (b *NATSStandIn) Addr() string {
+	return b.listener.Addr().String()
+}
+
+// Serve accepts connections until Close is called.
+func (b *NATSStandIn) Serve() error {
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+			return err
+		}
+		client := &standInClient{conn: conn, writer: bufio.NewWriter(conn), subs: map[string]string{}}
+		b.mu.Lock()
+		b.clients[client] = true
+		b.mu.Unlock()
+		go b.handle(client)
+	}
+}
+
+func (b *NATSStandIn) Close() error {
+	err := b.listener.Close()
+	b.mu.Lock()
+	for client := range b.clients {
+		client.conn.Close()
+	}
+	b.mu.Unlock()
+	return err
+}
+
+func (b *NATSStandIn) handle(client *standInClient) {
+	defer func() {
+		b.mu.Lock()
+		delete(b.clients, client)
+		b.mu.Unlock()
+		client.conn.Close()
+	}()
+
+	client.send(fmt.Sprintf(`INFO {"server_id":"nats-stand-in","version":"0.0.0","proto":0,"max_payload":%d}`+"\r\n", maxPayload))
+	reader := bufio.NewReader(client.conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch strings.ToUpper(fields[0]) {
+		case "CONNECT", "PONG":
+		case "PING":
+			client.send("PONG\r\n")
+		case "SUB":
+			// SUB <subject> [queue group] <sid>
+			if len(fields) < 3 {
+				client.send("-ERR 'Invalid Subscription'\r\n")
+				continue
+			}
+			b.mu.Lock()
+			client.subs[fields[len(fields)-1]] = fields[1]
+			b.mu.Unlock()
+		case "UNSUB":
+			if len(fields) >= 2 {
+				b.mu.Lock()
+				delete(client.subs, fields[1])
+				b.mu.Unlock()
+			}
+		case "PUB":
+			// PUB <subject> [reply-to] <#bytes>
+			if len(fields) < 3 {
+				client.send("-ERR 'Unknown Protocol Operation'\r\n")
+				return
+			}
+			size, err := strconv.Atoi(fields[len(fields)-1])
+			if err != nil || size < 0 || size > maxPayload {
+				client.send("-ERR 'Maximum Payload Violation'\r\n")
+				return
+			}
+			payload := make([]byte, size+2)
+			if _, err = io.ReadFull(reader, payload); err != nil {
+				return
+			}
+			reply := ""
+			if len(fields) == 4 {
+				reply = fields[2]
+			}
+			b.route(fields[1], reply, payload[:size])
+		default:
+			client.send("-ERR 'Unknown Protocol Operation'\r\n")
+		}
+	}
+}
+
+func (b *NATSStandIn) route(subject string, reply string, payload []byte) {
+	if b.OnPublish != nil {
+		b.OnPublish(subject, payload)
+	}
+
+	type delivery struct {
+		client *standInClient
+		sid    string
+	}
+	var deliveries []delivery
+	b.mu.Lock()
+	for client := range b.clients {
+		for sid, pattern := range client.subs {
+			if SubjectMatches(pattern, subject) {
+				deliveries = append(deliveries, delivery{client, sid})
+			}
+		}
+	}
+	b.mu.Unlock()
+
+	for _, d := range deliveries {
+		header := "MSG " + subject + " " + d.sid
+		if reply != "" {
+			header += " " + reply
+		}
+		d.client.send(header + " " + strconv.Itoa(len(payload)) + "\r\n" + string(payload) + "\r\n")
+	}
+}
+
+func (c *standInClient) send(data string) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.writer.WriteString(data)
+	if err := c.writer.Flush(); err != nil {
+		slog.Warn("nats stand-in: write failed", "remote_addr", c.conn.RemoteAddr().String(), "error", err)
+	}
+}
+
+// SubjectMatches implements NATS subject wildcards: "*" matches one token and
+// a trailing ">" matches one or more.
+func SubjectMatches(pattern string, subject string) bool {
+	patternTokens := strings.Split(pattern, ".")
+	subjectTokens := strings.Split(subject, ".")
+	for i, token := range patternTokens {
+		if token == ">" {
+			return len(subjectTokens) > i
+		}
+		if i >= len(subjectTokens) {
+			return false
+		}
+		if token != "*" && token != subjectTokens[i] {
+			return false
+		}
+	}
+	return len(patternTokens) == len(subjectTokens)
+}
@@ -0,0 +1,23 @@
+package interfaces
+
+import (
+	"context"
+	"somdeep-demo-app/src/events/models"
+)
+
+type EventPublisher interface {
+	Publish(ctx context.Context, events ...models.Event) error
+	Close() error
+}
+
+// EventSubscriber is implemented by publishers that can also deliver events
+// back into this process, such as the in-process bus.
+type EventSubscriber interface {
+	Subscribe(handler func(event models.Event)) (unsubscribe func())
+}
+
+// EventPinger is implemented by publishers that deliver to a broker, which
+// readiness checks can ping.
+type EventPinger interface {
+	Ping(ctx context.Context) error
+}
@@ -0,0 +1,13 @@
+package interfaces
+
+import (
+	"context"
+	"somdeep-demo-app/src/events/models"
+)
+
+// EventStream follows committed events, starting after lastEventId when it is
+// given. The channel is closed when ctx is done or the stream breaks; callers
+// resume by following again with the ID of the last event they received.
+type EventStream interface {
+	Follow(ctx context.Context, lastEventId string) (<-chan models.StreamedEvent, error)
+}
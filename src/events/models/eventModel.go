@@ -0,0 +1,117 @@
+package models
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	UserCreated     = "user.created"
+	UserUpdated     = "user.updated"
+	UserDeleted     = "user.deleted"
+	CustomerCreated = "customer.created"
+	CustomerUpdated = "customer.updated"
+	CustomerDeleted = "customer.deleted"
+)
+
+// SchemaVersion is the version of the payload schemas under src/events/schemas.
+// Bump it, and add new schema files, for any change that is not purely additive.
+const SchemaVersion = 1
+
+const source = "somdeep-demo-app"
+
+// Event is the envelope every domain event is published in. Data holds the
+// JSON payload described by the schema named in Schema. Traceparent and
+// Tracestate carry the W3C trace context of the request that caused it, and
+// Tenant_id the tenant it happened in when tenancy is on.
+type Event struct {
+	ID          string          `json:"id"`
+	Type        string          `json:"type"`
+	Version     int             `json:"version"`
+	Schema      string          `json:"schema"`
+	Source      string          `json:"source"`
+	Subject     string          `json:"subject"`
+	Actor       string          `json:"actor,omitempty"`
+	Request_id  string          `json:"request_id,omitempty"`
+	Tenant_id   string          `json:"tenant_id,omitempty"`
+	Traceparent string          `json:"traceparent,omitempty"`
+	Tracestate  string          `json:"tracestate,omitempty"`
+	Time        time.Time       `json:"time"`
+	Data        json.RawMessage `json:"data"`
+}
+
+func NewEvent(eventType string, subject string, data any) (Event, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, err
+	}
+	return Event{
+		ID:      uuid.New().String(),
+		Type:    eventType,
+		Version: SchemaVersion,
+		Schema:  SchemaURI(eventType, SchemaVersion),
+		Source:  source,
+		Subject: subject,
+		Time:    time.Now().UTC(),
+		Data:    payload,
+	}, nil
+}
+
+// UserId returns the user the event belongs to. Every user and customer
+// payload carries a user_id.
+func (e Event) UserId() string {
+	var payload struct {
+		User_id string `json:"user_id"`
+	}
+	if err := json.Unmarshal(e.Data, &payload); err != nil {
+		return ""
+	}
+	return payload.User_id
+}
+
+func SchemaURI(eventType string, version int) string {
+	return "https://" + source + "/schemas/events/" + eventType + ".v" + strconv.Itoa(version) + ".json"
+}
+
+type UserPayload struct {
+	User_id    string    `json:"user_id"`
+	First_name string    `json:"first_name"`
+	Last_name  string    `json:"last_name"`
+	Email      string    `json:"email"`
+	Phone      string    `json:"phone"`
+	Created_at time.Time `json:"created_at"`
+	Updated_at time.Time `json:"updated_at"`
+	Changed    []string  `json:"changed,omitempty"`
+}
+
+type UserDeletedPayload struct {
+	User_id string `json:"user_id"`
+}
+
+type CustomerPayload struct {
+	Customer_id string    `json:"customer_id"`
+	User_id     string    `json:"user_id"`
+	First_name  string    `json:"first_name"`
+	Last_name   string    `json:"last_name"`
+	Created_at  time.Time `json:"created_at"`
+	Updated_at  time.Time `json:"updated_at"`
+	Changed     []string  `json:"changed,omitempty"`
+}
+
+type CustomerDeletedPayload struct {
+	Customer_id string `json:"customer_id"`
+	User_id     string `json:"user_id"`
+}
+
+// StreamedEvent is an event as delivered by an EventStream. ID is the opaque
+// position to resume from. A Reset message has no event: it tells the
+// consumer that the requested position could not be resumed and that events
+// in between may have been missed.
+type StreamedEvent struct {
+	ID    string
+	Event Event
+	Reset bool
+}
@@ -0,0 +1,71 @@
+package publishers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"somdeep-demo-app/src/events/models"
+)
+
+// InProcessPublisher fans events out synchronously to handlers registered in
+// this process. Handlers run on the publishing goroutine and must not block.
+type InProcessPublisher struct {
+	mu       sync.RWMutex
+	next     int
+	handlers map[int]func(event models.Event)
+}
+
+func NewInProcessPublisher() *InProcessPublisher {
+	return &InProcessPublisher{
+		handlers: map[int]func(event models.Event){},
+	}
+}
+
+func (p *InProcessPublisher) Publish(ctx context.Context, events ...models.Event) error {
+	p.mu.RLock()
+	handlers := make([]func(event models.Event), 0, len(p.handlers))
+	for _, handler := range p.handlers {
+		handlers = append(handlers, handler)
+	}
+	p.mu.RUnlock()
+
+	for _, event := range events {
+		for _, handler := range handlers {
+			deliver(handler, event)
+		}
+	}
+	return nil
+}
+
+func (p *InProcessPublisher) Subscribe(handler func(event models.Event)) (unsubscribe func()) {
+	p.mu.Lock()
+	id := p.next
+	p.next++
+	p.handlers[id] = handler
+	p.mu.Unlock()
+
+	return func() {
+		p.mu.Lock()
+		delete(p.handlers, id)
+		p.mu.Unlock()
+	}
+}
+
+func (p *InProcessPublisher) Close() error {
+	p.mu.Lock()
+	p.handlers = map[int]func(event models.Event){}
+	p.mu.Unlock()
+	return nil
+}
+
+// deliver keeps one misbehaving handler from taking the publisher down.
+func deliver(handler func(event models.Event), event models.Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("events: handler panicked", "type", event.Type, "event_id", event.ID, "request_id", event.Request_id, "panic", fmt.Sprint(r))
+		}
+	}()
+	handler(event)
+}
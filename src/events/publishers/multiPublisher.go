@@ -0,0 +1,40 @@
+package publishers
+
+import (
+	"context"
+	"errors"
+
+	"somdeep-demo-app/src/events/interfaces"
+	"somdeep-demo-app/src/events/models"
+)
+
+// MultiPublisher publishes every event to each of its publishers and fails if
+// any of them fails. Retrying a failed publish repeats it on all of them, so
+// the publishers must tolerate duplicates.
+type MultiPublisher struct {
+	publishers []interfaces.EventPublisher
+}
+
+func NewMultiPublisher(publishers ...interfaces.EventPublisher) *MultiPublisher {
+	return &MultiPublisher{publishers: publishers}
+}
+
+func (p *MultiPublisher) Publish(ctx context.Context, events ...models.Event) error {
+	var errs []error
+	for _, publisher := range p.publishers {
+		if err := publisher.Publish(ctx, events...); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (p *MultiPublisher) Close() error {
+	var errs []error
+	for _, publisher := range p.publishers {
+		if err := publisher.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
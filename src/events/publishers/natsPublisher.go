@@ -0,0 +1,220 @@
+package publishers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"somdeep-demo-app/src/events/models"
+)
+
+const natsDialTimeout = 5 * time.Second
+
+// NATSPublisher speaks the NATS core text protocol directly. Each event is
+// published on <prefix>.<event type>; after a batch is written it round-trips a
+// PING so a nil error means the broker has accepted every message.
+type NATSPublisher struct {
+	addr   string
+	prefix string
+
+	mu     sync.Mutex
+	conn   net.Conn
+	writer *bufio.Writer
+	pongs  chan error
+
+	// writeMu serialises writes between Publish and the PONG replies sent by
+	// readLoop, which cannot take mu while Publish is waiting on it.
+	writeMu sync.Mutex
+}
+
+// NewNATSPublisher accepts nats://host:port or a bare host:port.
+func NewNATSPublisher(rawURL string, prefix string) (*NATSPublisher, error) {
+	addr := rawURL
+	if strings.Contains(rawURL, "://") {
+		parsed, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, err
+		}
+		addr = parsed.Host
+	}
+	if prefix == "" {
+		prefix = "events"
+	}
+
+	p := &NATSPublisher{addr: addr, prefix: prefix}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.connect(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *NATSPublisher) Publish(ctx context.Context, events ...models.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn == nil {
+		if err := p.connect(); err != nil {
+			return err
+		}
+	}
+
+	var buf strings.Builder
+	for _, event := range events {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&buf, "PUB %s.%s %d\r\n%s\r\n", p.prefix, event.Type, len(payload), payload)
+	}
+	return p.roundTrip(ctx, buf.String())
+}
+
+// Ping checks the broker answers, connecting first if needed.
+func (p *NATSPublisher) Ping(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn == nil {
+		if err := p.connect(); err != nil {
+			return err
+		}
+	}
+	return p.roundTrip(ctx, "")
+}
+
+// roundTrip writes data followed by a PING and waits for the PONG. It must be
+// called with p.mu held.
+func (p *NATSPublisher) roundTrip(ctx context.Context, data string) error {
+	if err := p.write(p.writer, data+"PING\r\n"); err != nil {
+		p.disconnect()
+		return err
+	}
+
+	timeout := time.NewTimer(natsDialTimeout)
+	defer timeout.Stop()
+	select {
+	case err := <-p.pongs:
+		if err != nil {
+			p.disconnect()
+		}
+		return err
+	case <-timeout.C:
+		p.disconnect()
+		return errors.New("nats: timed out waiting for the broker to acknowledge")
+	case <-ctx.Done():
+		p.disconnect()
+		return ctx.Err()
+	}
+}
+
+func (p *NATSPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.disconnect()
+	return nil
+}
+
+// connect must be called with p.mu held.
+func (p *NATSPublisher) connect() error {
+	conn, err := net.DialTimeout("tcp", p.addr, natsDialTimeout)
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(natsDialTimeout))
+	reader := bufio.NewReader(conn)
+
+	info, err := reader.ReadString('\n')
+	if err != nil || !strings.HasPrefix(info, "INFO") {
+		conn.Close()
+		return fmt.Errorf("nats: unexpected greeting %q: %v", strings.TrimSpace(info), err)
+	}
+
+	writer := bufio.NewWriter(conn)
+	writer.WriteString(`CONNECT {"verbose":false,"pedantic":false,"name":"somdeep-demo-app","lang":"go","version":"1"}` + "\r\n")
+	writer.WriteString("PING\r\n")
+	if err = writer.Flush(); err != nil {
+		conn.Close()
+		return err
+	}
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			conn.Close()
+			return err
+		}
+		line = strings.TrimSpace(line)
+		if line == "PONG" {
+			break
+		}
+		if strings.HasPrefix(line, "-ERR") {
+			conn.Close()
+			return errors.New("nats: " + line)
+		}
+	}
+	conn.SetDeadline(time.Time{})
+
+	p.conn = conn
+	p.writer = writer
+	p.pongs = make(chan error, 1)
+	go p.readLoop(reader, writer, p.pongs)
+	return nil
+}
+
+// disconnect must be called with p.mu held.
+func (p *NATSPublisher) disconnect() {
+	if p.conn != nil {
+		p.conn.Close()
+		p.conn = nil
+		p.writer = nil
+	}
+}
+
+func (p *NATSPublisher) write(writer *bufio.Writer, data string) error {
+	p.writeMu.Lock()
+	defer p.writeMu.Unlock()
+	writer.WriteString(data)
+	return writer.Flush()
+}
+
+func (p *NATSPublisher) readLoop(reader *bufio.Reader, writer *bufio.Writer, pongs chan error) {
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			select {
+			case pongs <- err:
+			default:
+			}
+			return
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "PING":
+			p.write(writer, "PONG\r\n")
+		case line == "PONG":
+			select {
+			case pongs <- nil:
+			default:
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			slog.Error("events: nats broker error", "error", line)
+			select {
+			case pongs <- errors.New("nats: " + line):
+			default:
+			}
+		}
+	}
+}
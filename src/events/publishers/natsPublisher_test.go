@@ -0,0 +1,133 @@
+package publishers
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"somdeep-demo-app/src/events/broker"
+	"somdeep-demo-app/src/events/models"
+)
+
+type published struct {
+	subject string
+	event   models.Event
+}
+
+// startBroker runs a NATS stand-in on a free port and records what it
+// receives.
+func startBroker(t *testing.T) (*broker.NATSStandIn, func() []published) {
+	t.Helper()
+	standIn, err := broker.NewNATSStandIn("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mu sync.Mutex
+	var received []published
+	standIn.OnPublish = func(subject string, payload []byte) {
+		var event models.Event
+		if err := json.Unmarshal(payload, &event); err != nil {
+			t.Errorf("broker received %q: %v", payload, err)
+			return
+		}
+		mu.Lock()
+		received = append(received, published{subject, event})
+		mu.Unlock()
+	}
+	go standIn.Serve()
+	t.Cleanup(func() { standIn.Close() })
+
+	return standIn, func() []published {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]published(nil), received...)
+	}
+}
+
+func TestNATSPublisherPublishesEachEventOnItsSubject(t *testing.T) {
+	standIn, received := startBroker(t)
+	publisher, err := NewNATSPublisher("nats://"+standIn.Addr(), "events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer publisher.Close()
+
+	created, _ := models.NewEvent(models.UserCreated, "u-1", models.UserPayload{User_id: "u-1"})
+	deleted, _ := models.NewEvent(models.CustomerDeleted, "u-1", models.CustomerDeletedPayload{Customer_id: "c-1", User_id: "u-1"})
+	if err = publisher.Publish(context.Background(), created, deleted); err != nil {
+		t.Fatal(err)
+	}
+
+	// Publish returns once the broker has answered the PING sent after the
+	// batch, so both messages have been received by now.
+	got := received()
+	if len(got) != 2 {
+		t.Fatalf("broker received %d messages, want 2", len(got))
+	}
+	for i, want := range []published{{"events.user.created", created}, {"events.customer.deleted", deleted}} {
+		if got[i].subject != want.subject {
+			t.Errorf("message %d subject = %q, want %q", i, got[i].subject, want.subject)
+		}
+		if got[i].event.ID != want.event.ID || got[i].event.UserId() != "u-1" {
+			t.Errorf("message %d = %+v, want event %s for u-1", i, got[i].event, want.event.ID)
+		}
+	}
+}
+
+func TestNATSPublisherReconnects(t *testing.T) {
+	standIn, received := startBroker(t)
+	publisher, err := NewNATSPublisher(standIn.Addr(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer publisher.Close()
+
+	if err = publisher.Ping(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// a dropped connection is redialled on the next publish
+	publisher.Close()
+	event, _ := models.NewEvent(models.UserDeleted, "u-1", models.UserDeletedPayload{User_id: "u-1"})
+	if err = publisher.Publish(context.Background(), event); err != nil {
+		t.Fatal(err)
+	}
+	if got := received(); len(got) != 1 || got[0].subject != "events.user.deleted" {
+		t.Errorf("broker received %+v, want one message on events.user.deleted", got)
+	}
+}
+
+func TestNATSPublisherFailsWhenTheBrokerIsGone(t *testing.T) {
+	standIn, _ := startBroker(t)
+	publisher, err := NewNATSPublisher(standIn.Addr(), "events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer publisher.Close()
+
+	standIn.Close()
+	event, _ := models.NewEvent(models.UserDeleted, "u-1", models.UserDeletedPayload{User_id: "u-1"})
+	if err = publisher.Publish(context.Background(), event); err == nil {
+		t.Error("expected publishing to a closed broker to fail")
+	}
+}
+
+func TestSubjectMatches(t *testing.T) {
+	tests := []struct {
+		pattern string
+		subject string
+		match   bool
+	}{
+		{"events.user.created", "events.user.created", true},
+		{"events.*.created", "events.customer.created", true},
+		{"events.*", "events.user.created", false},
+		{"events.>", "events.user.created", true},
+		{"events.>", "events", false},
+		{"events.user.created", "events.user", false},
+	}
+	for _, test := range tests {
+		if got := broker.SubjectMatches(test.pattern, test.subject); got != test.match {
+			t.Errorf("SubjectMatches(%q, %q) = %v, want %v", test.pattern, test.subject, got, test.match)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package schemas
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+//go:embed *.json
+var files embed.FS
+
+// Get returns the JSON schema document for an event type and payload version.
+func Get(eventType string, version int) (json.RawMessage, error) {
+	data, err := files.ReadFile(eventType + ".v" + strconv.Itoa(version) + ".json")
+	if err != nil {
+		return nil, fmt.Errorf("no schema for %s version %d", eventType, version)
+	}
+	return data, nil
+}
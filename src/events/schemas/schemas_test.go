@@ -0,0 +1,120 @@
+package schemas
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"somdeep-demo-app/src/events/models"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// compile loads the schema for eventType through Get under the URI events
+// name in their schema field.
+func compile(t *testing.T, eventType string) *jsonschema.Schema {
+	t.Helper()
+	data, err := Get(eventType, models.SchemaVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compiler := jsonschema.NewCompiler()
+	compiler.AssertFormat = true
+	uri := models.SchemaURI(eventType, models.SchemaVersion)
+	if err = compiler.AddResource(uri, bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	schema, err := compiler.Compile(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return schema
+}
+
+// validate checks value against schema as it looks once marshalled.
+func validate(t *testing.T, schema *jsonschema.Schema, value any) {
+	t.Helper()
+	data, err := json.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var document any
+	if err = json.Unmarshal(data, &document); err != nil {
+		t.Fatal(err)
+	}
+	if err = schema.Validate(document); err != nil {
+		t.Errorf("%s does not match its schema: %v", data, err)
+	}
+}
+
+func TestEventsMatchTheirSchemas(t *testing.T) {
+	now := time.Now().UTC()
+	user := models.UserPayload{
+		User_id:    "u-1",
+		First_name: "Ada",
+		Last_name:  "Lovelace",
+		Email:      "ada@example.com",
+		Phone:      "5550100",
+		Created_at: now,
+		Updated_at: now,
+	}
+	updatedUser := user
+	updatedUser.Changed = []string{"email"}
+	customer := models.CustomerPayload{
+		Customer_id: "c-1",
+		User_id:     "u-1",
+		First_name:  "Charles",
+		Last_name:   "Babbage",
+		Created_at:  now,
+		Updated_at:  now,
+	}
+	updatedCustomer := customer
+	updatedCustomer.Changed = []string{"last_name"}
+
+	payloads := map[string]any{
+		models.UserCreated:     user,
+		models.UserUpdated:     updatedUser,
+		models.UserDeleted:     models.UserDeletedPayload{User_id: "u-1"},
+		models.CustomerCreated: customer,
+		models.CustomerUpdated: updatedCustomer,
+		models.CustomerDeleted: models.CustomerDeletedPayload{Customer_id: "c-1", User_id: "u-1"},
+	}
+
+	envelope := compile(t, "envelope")
+	for eventType, payload := range payloads {
+		t.Run(eventType, func(t *testing.T) {
+			event, err := models.NewEvent(eventType, "u-1", payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+			event.Actor = "tester"
+			event.Request_id = "req-1"
+
+			validate(t, envelope, event)
+			validate(t, compile(t, event.Type), json.RawMessage(event.Data))
+			if event.Schema != models.SchemaURI(eventType, models.SchemaVersion) {
+				t.Errorf("schema = %q", event.Schema)
+			}
+		})
+	}
+}
+
+func TestGetUnknownSchema(t *testing.T) {
+	if _, err := Get(models.UserCreated, models.SchemaVersion+1); err == nil {
+		t.Error("expected an error for a version without a schema")
+	}
+	if _, err := Get("order.created", models.SchemaVersion); err == nil {
+		t.Error("expected an error for an unknown event type")
+	}
+}
+
+func TestSchemasRejectUndeclaredFields(t *testing.T) {
+	schema := compile(t, models.UserDeleted)
+	if err := schema.Validate(map[string]any{"user_id": "u-1", "email": "ada@example.com"}); err == nil {
+		t.Error("expected a payload with an undeclared field to be rejected")
+	}
+	if err := schema.Validate(map[string]any{}); err == nil {
+		t.Error("expected a payload without user_id to be rejected")
+	}
+}
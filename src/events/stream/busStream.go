@@ -0,0 +1,124 @@
+package stream
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+
+	"somdeep-demo-app/src/events/interfaces"
+	"somdeep-demo-app/src/events/models"
+	"somdeep-demo-app/src/tenant"
+
+	"github.com/google/uuid"
+)
+
+// subscriberBuffer is how many events a follower may fall behind before it is
+// dropped. A dropped follower resumes from the replay buffer.
+const subscriberBuffer = 256
+
+// BusStream follows an in-process event bus. It keeps the last events in a
+// replay buffer so followers can resume with Last-Event-ID. Stream IDs are
+// only valid within one process lifetime; older IDs get a Reset.
+//
+// It only sees events relayed by this process, so with several instances it
+// is only complete when there is a single relay. A follower whose context
+// names a tenant only gets that tenant's events.
+type BusStream struct {
+	mu        sync.Mutex
+	boot      string
+	seq       uint64
+	buffer    []models.StreamedEvent
+	next      int
+	followers map[chan models.StreamedEvent]string
+}
+
+func NewBusStream(subscriber interfaces.EventSubscriber, replaySize int) *BusStream {
+	s := &BusStream{
+		boot:      uuid.New().String()[:8],
+		buffer:    make([]models.StreamedEvent, 0, replaySize),
+		followers: map[chan models.StreamedEvent]string{},
+	}
+	subscriber.Subscribe(s.append)
+	return s
+}
+
+func (s *BusStream) append(event models.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+	message := models.StreamedEvent{ID: s.boot + "-" + strconv.FormatUint(s.seq, 10), Event: event}
+	if len(s.buffer) < cap(s.buffer) {
+		s.buffer = append(s.buffer, message)
+	} else if cap(s.buffer) > 0 {
+		s.buffer[s.next] = message
+		s.next = (s.next + 1) % cap(s.buffer)
+	}
+
+	for follower, tenantId := range s.followers {
+		if !follows(tenantId, event) {
+			continue
+		}
+		select {
+		case follower <- message:
+		default:
+			delete(s.followers, follower)
+			close(follower)
+		}
+	}
+}
+
+func (s *BusStream) Follow(ctx context.Context, lastEventId string) (<-chan models.StreamedEvent, error) {
+	tenantId := tenant.Id(ctx)
+	s.mu.Lock()
+	replay, ok := s.replayAfter(lastEventId)
+	follower := make(chan models.StreamedEvent, len(replay)+subscriberBuffer+1)
+	if !ok {
+		follower <- models.StreamedEvent{Reset: true}
+	}
+	for _, message := range replay {
+		if follows(tenantId, message.Event) {
+			follower <- message
+		}
+	}
+	s.followers[follower] = tenantId
+	s.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		s.mu.Lock()
+		if _, active := s.followers[follower]; active {
+			delete(s.followers, follower)
+			close(follower)
+		}
+		s.mu.Unlock()
+	}()
+	return follower, nil
+}
+
+// follows reports whether a follower of tenantId gets event. Without a
+// tenant it gets every event.
+func follows(tenantId string, event models.Event) bool {
+	return tenantId == "" || event.Tenant_id == tenantId
+}
+
+// replayAfter returns the buffered events after lastEventId, and false when
+// that position is unknown or has already left the buffer.
+func (s *BusStream) replayAfter(lastEventId string) ([]models.StreamedEvent, bool) {
+	if lastEventId == "" {
+		return nil, true
+	}
+	boot, seqText, found := strings.Cut(lastEventId, "-")
+	seq, err := strconv.ParseUint(seqText, 10, 64)
+	if !found || err != nil || boot != s.boot || seq > s.seq {
+		return nil, false
+	}
+
+	ordered := append(append([]models.StreamedEvent{}, s.buffer[s.next:]...), s.buffer[:s.next]...)
+	oldest := s.seq - uint64(len(ordered)) + 1
+	if seq+1 < oldest {
+		return nil, false
+	}
+	return ordered[seq+1-oldest:], true
+}
@@ -0,0 +1,141 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"somdeep-demo-app/src/events/models"
+	"somdeep-demo-app/src/tenant"
+)
+
+// stubSubscriber hands the events it is given to the stream it feeds.
+type stubSubscriber struct {
+	handler func(event models.Event)
+}
+
+func (s *stubSubscriber) Subscribe(handler func(event models.Event)) func() {
+	s.handler = handler
+	return func() {}
+}
+
+func (s *stubSubscriber) publish(eventIds ...string) {
+	for _, eventId := range eventIds {
+		s.handler(models.Event{ID: eventId})
+	}
+}
+
+// received returns what follower holds without waiting for more: the event
+// IDs, "reset" for a Reset and "closed" once the channel is closed.
+func received(follower <-chan models.StreamedEvent) []string {
+	var got []string
+	for {
+		select {
+		case message, open := <-follower:
+			switch {
+			case !open:
+				return append(got, "closed")
+			case message.Reset:
+				got = append(got, "reset")
+			default:
+				got = append(got, message.Event.ID)
+			}
+		default:
+			return got
+		}
+	}
+}
+
+// streamIds publishes e-1 to e-5 through a stream replaying 3 events and
+// returns the stream IDs they were given.
+func streamIds(t *testing.T) (*BusStream, *stubSubscriber, []string) {
+	t.Helper()
+	subscriber := &stubSubscriber{}
+	stream := NewBusStream(subscriber, 3)
+	observer, err := stream.Follow(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	subscriber.publish("e-1", "e-2", "e-3", "e-4", "e-5")
+	var ids []string
+	for i := 0; i < 5; i++ {
+		ids = append(ids, (<-observer).ID)
+	}
+	return stream, subscriber, ids
+}
+
+func TestBusStreamReplaysAfterLastEventId(t *testing.T) {
+	tests := []struct {
+		name        string
+		lastEventId func(ids []string) string
+		want        []string
+	}{
+		{name: "no last event", lastEventId: func(ids []string) string { return "" }, want: []string{"e-6"}},
+		{name: "within the buffer", lastEventId: func(ids []string) string { return ids[2] }, want: []string{"e-4", "e-5", "e-6"}},
+		{name: "oldest buffered", lastEventId: func(ids []string) string { return ids[1] }, want: []string{"e-3", "e-4", "e-5", "e-6"}},
+		{name: "latest", lastEventId: func(ids []string) string { return ids[4] }, want: []string{"e-6"}},
+		{name: "left the buffer", lastEventId: func(ids []string) string { return ids[0] }, want: []string{"reset", "e-6"}},
+		{name: "earlier process", lastEventId: func(ids []string) string { return "0badf00d-3" }, want: []string{"reset", "e-6"}},
+		{name: "not sent yet", lastEventId: func(ids []string) string { return ids[4][:len(ids[4])-1] + "9" }, want: []string{"reset", "e-6"}},
+		{name: "malformed", lastEventId: func(ids []string) string { return "e-3" }, want: []string{"reset", "e-6"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stream, subscriber, ids := streamIds(t)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			follower, err := stream.Follow(ctx, test.lastEventId(ids))
+			if err != nil {
+				t.Fatal(err)
+			}
+			subscriber.publish("e-6")
+			if got := received(follower); fmt.Sprint(got) != fmt.Sprint(test.want) {
+				t.Errorf("received %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestBusStreamFollowsTheTenant(t *testing.T) {
+	subscriber := &stubSubscriber{}
+	stream := NewBusStream(subscriber, 10)
+	subscriber.handler(models.Event{ID: "e-1", Tenant_id: "acme"})
+	subscriber.handler(models.Event{ID: "e-2", Tenant_id: "globex"})
+
+	// replay and live events are both limited to the follower's tenant
+	ctx, cancel := context.WithCancel(tenant.WithTenant(context.Background(), "acme"))
+	follower, _ := stream.Follow(ctx, stream.boot+"-0")
+	subscriber.handler(models.Event{ID: "e-3", Tenant_id: "globex"})
+	subscriber.handler(models.Event{ID: "e-4", Tenant_id: "acme"})
+	if got := received(follower); fmt.Sprint(got) != "[e-1 e-4]" {
+		t.Errorf("acme received %v, want [e-1 e-4]", got)
+	}
+
+	// a follower is closed once its context is done
+	cancel()
+	deadline := time.After(2 * time.Second)
+	for open := true; open; {
+		select {
+		case _, open = <-follower:
+		case <-deadline:
+			t.Fatal("the follower was not closed after its context was done")
+		}
+	}
+}
+
+func TestBusStreamDropsSlowFollowers(t *testing.T) {
+	subscriber := &stubSubscriber{}
+	stream := NewBusStream(subscriber, 10)
+
+	// a follower that falls too far behind is closed, to resume from the
+	// replay buffer
+	follower, _ := stream.Follow(context.Background(), "")
+	for i := 0; i < subscriberBuffer+2; i++ {
+		subscriber.handler(models.Event{ID: fmt.Sprintf("e-%d", i)})
+	}
+	got := received(follower)
+	if len(got) != subscriberBuffer+2 || got[len(got)-1] != "closed" {
+		t.Errorf("a slow follower received %d messages ending in %q, want %d and closed", len(got), got[len(got)-1], subscriberBuffer+2)
+	}
+}
@@ -0,0 +1,56 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+type csvWriter struct {
+	out    io.Writer
+	writer *csv.Writer
+	rows   int
+}
+
+func newCSVWriter(w io.Writer, columns []string) (*csvWriter, error) {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(columns); err != nil {
+		return nil, err
+	}
+	return &csvWriter{out: w, writer: writer}, nil
+}
+
+// formulaPrefixes start the cells a spreadsheet would evaluate as a formula
+// when it opens the export.
+const formulaPrefixes = "=+-@\t\r"
+
+// neutralise prefixes value with a quote when a spreadsheet would read it as a
+// formula, so exported user input cannot run as one (CSV injection).
+func neutralise(value string) string {
+	if value != "" && strings.ContainsRune(formulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func (w *csvWriter) WriteRow(values []string) error {
+	cells := make([]string, len(values))
+	for i, value := range values {
+		cells[i] = neutralise(value)
+	}
+	if err := w.writer.Write(cells); err != nil {
+		return err
+	}
+	w.rows++
+	if w.rows%flushEvery == 0 {
+		w.writer.Flush()
+		flush(w.out)
+	}
+	return w.writer.Error()
+}
+
+func (w *csvWriter) Close() error {
+	w.writer.Flush()
+	flush(w.out)
+	return w.writer.Error()
+}
@@ -0,0 +1,49 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+)
+
+func TestCSVWriterNeutralisesFormulas(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "Ada", want: "Ada"},
+		{value: "", want: ""},
+		{value: "a=b", want: "a=b"},
+		{value: "=HYPERLINK(\"http://evil\")", want: "'=HYPERLINK(\"http://evil\")"},
+		{value: "+1+1", want: "'+1+1"},
+		{value: "-2+3", want: "'-2+3"},
+		{value: "@SUM(A1)", want: "'@SUM(A1)"},
+		{value: "\t=1", want: "'\t=1"},
+		{value: "\r=1", want: "'\r=1"},
+	}
+	for _, test := range tests {
+		var out bytes.Buffer
+		writer, err := NewRowWriter(FormatCSV, &out, []string{"customer_id", "first_name"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		values := []string{"c-1", test.value}
+		if err = writer.WriteRow(values); err != nil {
+			t.Fatal(err)
+		}
+		if err = writer.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if values[1] != test.value {
+			t.Errorf("WriteRow changed the values it was given to %q", values[1])
+		}
+
+		records, err := csv.NewReader(&out).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != 2 || records[0][1] != "first_name" || records[1][1] != test.want {
+			t.Errorf("%q was exported as %q, want %q", test.value, records, test.want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+	FormatXLSX   = "xlsx"
+)
+
+// flushEvery is the number of rows after which buffered output is pushed to
+// the client so large exports start arriving before the cursor is drained.
+const flushEvery = 500
+
+type RowWriter interface {
+	WriteRow(values []string) error
+	Close() error
+}
+
+func ContentType(format string) (string, error) {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8", nil
+	case FormatNDJSON:
+		return "application/x-ndjson", nil
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", nil
+	}
+	return "", fmt.Errorf("unsupported export format %q, expected csv, ndjson or xlsx", format)
+}
+
+// NewRowWriter writes the header (where the format has one) straight away, so
+// callers should only create it once the data source is known to be readable.
+func NewRowWriter(format string, w io.Writer, columns []string) (RowWriter, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, columns)
+	case FormatNDJSON:
+		return newNDJSONWriter(w, columns), nil
+	case FormatXLSX:
+		return newXLSXWriter(w, columns)
+	}
+	_, err := ContentType(format)
+	return nil, err
+}
+
+// SelectColumns returns the requested fields in the canonical column order.
+// An empty selection returns every column.
+func SelectColumns(columns []string, fields []string) ([]string, error) {
+	if len(fields) == 0 {
+		return columns, nil
+	}
+
+	requested := map[string]bool{}
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		requested[field] = true
+	}
+
+	var selected []string
+	for _, column := range columns {
+		if requested[column] {
+			selected = append(selected, column)
+			delete(requested, column)
+		}
+	}
+	for field := range requested {
+		return nil, fmt.Errorf("unknown export field %q", field)
+	}
+	if len(selected) == 0 {
+		return nil, errors.New("no export fields selected")
+	}
+	return selected, nil
+}
+
+func flush(w io.Writer) {
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
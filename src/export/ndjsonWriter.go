@@ -0,0 +1,58 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+)
+
+type ndjsonWriter struct {
+	out     io.Writer
+	writer  *bufio.Writer
+	columns [][]byte
+	rows    int
+}
+
+func newNDJSONWriter(w io.Writer, columns []string) *ndjsonWriter {
+	keys := make([][]byte, len(columns))
+	for i, column := range columns {
+		keys[i], _ = json.Marshal(column)
+	}
+	return &ndjsonWriter{out: w, writer: bufio.NewWriter(w), columns: keys}
+}
+
+// WriteRow emits the object keys in column order, which encoding/json would
+// not preserve for a map.
+func (w *ndjsonWriter) WriteRow(values []string) error {
+	w.writer.WriteByte('{')
+	for i, key := range w.columns {
+		if i > 0 {
+			w.writer.WriteByte(',')
+		}
+		w.writer.Write(key)
+		w.writer.WriteByte(':')
+		value, err := json.Marshal(values[i])
+		if err != nil {
+			return err
+		}
+		w.writer.Write(value)
+	}
+	if _, err := w.writer.WriteString("}\n"); err != nil {
+		return err
+	}
+
+	w.rows++
+	if w.rows%flushEvery == 0 {
+		if err := w.writer.Flush(); err != nil {
+			return err
+		}
+		flush(w.out)
+	}
+	return nil
+}
+
+func (w *ndjsonWriter) Close() error {
+	err := w.writer.Flush()
+	flush(w.out)
+	return err
+}
@@ -0,0 +1,25 @@
+package interfaces
+
+import (
+	"context"
+	"somdeep-demo-app/src/health/models"
+)
+
+type Response struct {
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+	Error   string `json:"error"`
+	Data    any    `json:"data"`
+}
+
+type HealthService interface {
+	GetLiveness() (response Response, err error)
+	GetReadiness(ctx context.Context) (response Response, err error)
+	// RegisterCheck adds a dependency readiness depends on.
+	RegisterCheck(check models.HealthCheck)
+	// StartDraining fails readiness from now on, so traffic is routed away
+	// before the process shuts down.
+	StartDraining()
+	// Draining is closed once StartDraining has been called.
+	Draining() <-chan struct{}
+}
@@ -0,0 +1,29 @@
+package models
+
+import "context"
+
+const (
+	StatusUp       = "up"
+	StatusDown     = "down"
+	StatusDraining = "draining"
+)
+
+// HealthCheck probes one dependency. Check returns details reported with the
+// result, such as the state of a connection pool, even when it fails.
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) (details any, err error)
+}
+
+type CheckResult struct {
+	Name       string  `json:"name"`
+	Status     string  `json:"status"`
+	Latency_ms float64 `json:"latency_ms"`
+	Error      string  `json:"error,omitempty"`
+	Details    any     `json:"details,omitempty"`
+}
+
+type HealthReport struct {
+	Status string        `json:"status"`
+	Checks []CheckResult `json:"checks"`
+}
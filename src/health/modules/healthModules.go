@@ -0,0 +1,129 @@
+package modules
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"somdeep-demo-app/src/health/interfaces"
+	"somdeep-demo-app/src/health/models"
+)
+
+// checkTimeout bounds each dependency check, so one hanging dependency cannot
+// hold up the probe past the orchestrator's own timeout.
+const checkTimeout = 2 * time.Second
+
+type healthService struct {
+	mu       sync.RWMutex
+	checks   []models.HealthCheck
+	draining atomic.Bool
+	drained  chan struct{}
+	drain    sync.Once
+}
+
+func NewHealthService(checks ...models.HealthCheck) interfaces.HealthService {
+	return &healthService{
+		checks:  checks,
+		drained: make(chan struct{}),
+	}
+}
+
+func (s *healthService) RegisterCheck(check models.HealthCheck) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checks = append(s.checks, check)
+}
+
+func (s *healthService) StartDraining() {
+	s.draining.Store(true)
+	s.drain.Do(func() { close(s.drained) })
+}
+
+func (s *healthService) Draining() <-chan struct{} {
+	return s.drained
+}
+
+// GetLiveness only tells the process is serving requests; dependencies are
+// left to readiness so an outage does not get every instance restarted.
+func (s *healthService) GetLiveness() (response interfaces.Response, err error) {
+	var res interfaces.Response
+
+	res.Status = http.StatusOK
+	res.Error = "NA"
+	res.Message = "Alive"
+	res.Data = models.HealthReport{Status: models.StatusUp, Checks: []models.CheckResult{}}
+	return res, nil
+}
+
+// GetReadiness runs every check in parallel and is ready when all of them
+// pass and the service is not shutting down.
+func (s *healthService) GetReadiness(ctx context.Context) (response interfaces.Response, err error) {
+	var res interfaces.Response
+
+	if s.draining.Load() {
+		res.Status = http.StatusServiceUnavailable
+		res.Error = "NA"
+		res.Message = "Shutting down"
+		res.Data = models.HealthReport{Status: models.StatusDraining, Checks: []models.CheckResult{}}
+		return res, errors.New(res.Message)
+	}
+
+	s.mu.RLock()
+	checks := append([]models.HealthCheck(nil), s.checks...)
+	s.mu.RUnlock()
+
+	report := models.HealthReport{Status: models.StatusUp, Checks: make([]models.CheckResult, len(checks))}
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check models.HealthCheck) {
+			defer wg.Done()
+			report.Checks[i] = runCheck(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	var failed []string
+	for _, result := range report.Checks {
+		if result.Status != models.StatusUp {
+			failed = append(failed, result.Name)
+		}
+	}
+	if len(failed) > 0 {
+		report.Status = models.StatusDown
+		res.Status = http.StatusServiceUnavailable
+		res.Error = "NA"
+		res.Message = "Dependencies are unavailable"
+		res.Data = report
+		return res, errors.New("failing checks: " + strings.Join(failed, ", "))
+	}
+
+	res.Status = http.StatusOK
+	res.Error = "NA"
+	res.Message = "Ready"
+	res.Data = report
+	return res, nil
+}
+
+func runCheck(ctx context.Context, check models.HealthCheck) models.CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	start := time.Now()
+	details, err := check.Check(ctx)
+	result := models.CheckResult{
+		Name:       check.Name,
+		Status:     models.StatusUp,
+		Latency_ms: float64(time.Since(start).Microseconds()) / 1000,
+		Details:    details,
+	}
+	if err != nil {
+		result.Status = models.StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
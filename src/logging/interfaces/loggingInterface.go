@@ -0,0 +1,18 @@
+package interfaces
+
+import (
+	"context"
+	"somdeep-demo-app/src/logging/models"
+)
+
+type Response struct {
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+	Error   string `json:"error"`
+	Data    any    `json:"data"`
+}
+
+type LoggingService interface {
+	GetLogLevel() (response Response, err error)
+	SetLogLevel(ctx context.Context, level models.LogLevel) (response Response, err error)
+}
@@ -0,0 +1,87 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"somdeep-demo-app/src/tenant"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Level is the minimum level of the default logger. It can be changed while
+// the process runs, which is what the /admin/log-level endpoint does.
+var Level = new(slog.LevelVar)
+
+// Importing the package installs the JSON logger as slog's default, and with
+// it the standard log package's, so even lines written while other packages
+// initialise are structured.
+func init() {
+	slog.SetDefault(New(os.Stdout, Level))
+}
+
+// New returns a JSON logger writing to w at level that adds the request ID,
+// tenant and trace carried by the context to every record logged with one.
+func New(w io.Writer, level slog.Leveler) *slog.Logger {
+	return slog.New(contextHandler{slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})})
+}
+
+// ParseLevel reads a level name such as "debug" or "WARN".
+func ParseLevel(value string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(value)); err != nil {
+		return level, fmt.Errorf("unknown log level %q", value)
+	}
+	return level, nil
+}
+
+// LevelName is the lower case name of a level, as ParseLevel accepts it.
+func LevelName(level slog.Level) string {
+	return strings.ToLower(level.String())
+}
+
+type requestIdKey struct{}
+
+// WithRequestId returns a context carrying the ID of the request it serves.
+func WithRequestId(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestIdKey{}, requestId)
+}
+
+// RequestId returns the request ID ctx carries, or "".
+func RequestId(ctx context.Context) string {
+	requestId, _ := ctx.Value(requestIdKey{}).(string)
+	return requestId
+}
+
+// contextHandler adds the request ID, the tenant when there is one, and the
+// trace and span IDs when the request is traced, to records logged with a
+// request's context.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if ctx != nil {
+		if requestId := RequestId(ctx); requestId != "" {
+			record.AddAttrs(slog.String("request_id", requestId))
+		}
+		if tenantId := tenant.Id(ctx); tenantId != "" {
+			record.AddAttrs(slog.String("tenant_id", tenantId))
+		}
+		if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+			record.AddAttrs(slog.String("trace_id", span.TraceID().String()), slog.String("span_id", span.SpanID().String()))
+		}
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
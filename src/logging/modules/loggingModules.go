@@ -0,0 +1,69 @@
+package modules
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"somdeep-demo-app/src/apperrors"
+	"somdeep-demo-app/src/logging"
+	"somdeep-demo-app/src/logging/interfaces"
+	"somdeep-demo-app/src/logging/models"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var validate = validator.New()
+
+type loggingService struct {
+	level *slog.LevelVar
+}
+
+// NewLoggingService reads and changes level, normally logging.Level.
+func NewLoggingService(level *slog.LevelVar) interfaces.LoggingService {
+	return &loggingService{
+		level: level,
+	}
+}
+
+func (s *loggingService) GetLogLevel() (response interfaces.Response, err error) {
+	var res interfaces.Response
+
+	res.Status = http.StatusOK
+	res.Error = "NA"
+	res.Message = "Record Fetched Successfully"
+	res.Data = models.LogLevel{Level: logging.LevelName(s.level.Level())}
+	return res, nil
+}
+
+func (s *loggingService) SetLogLevel(ctx context.Context, level models.LogLevel) (response interfaces.Response, err error) {
+	var res interfaces.Response
+
+	if validationError := validate.Struct(level); validationError != nil {
+		res.Status = http.StatusBadRequest
+		res.Error = validationError.Error()
+		res.Message = "Validation Error"
+		res.Data = nil
+		return res, apperrors.FromValidator("Validation Error", validationError, level)
+	}
+
+	parsed, err := logging.ParseLevel(level.Level)
+	if err != nil {
+		res.Status = http.StatusBadRequest
+		res.Error = err.Error()
+		res.Message = "Validation Error"
+		res.Data = nil
+		return res, apperrors.Validation("Validation Error", apperrors.FieldError{Field: "level", Rule: "oneof", Message: err.Error()})
+	}
+
+	previous := s.level.Level()
+	s.level.Set(parsed)
+	// logged at warn so the change shows up whichever way the level moved
+	slog.WarnContext(ctx, "logging: level changed", "from", logging.LevelName(previous), "to", logging.LevelName(parsed))
+
+	res.Status = http.StatusOK
+	res.Error = "NA"
+	res.Message = "Log level updated"
+	res.Data = models.LogLevel{Level: logging.LevelName(parsed)}
+	return res, nil
+}
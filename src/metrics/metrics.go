@@ -0,0 +1,131 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Registry holds every collector served on /metrics, along with the Go
+// runtime and process collectors.
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// HTTP requests, labelled by route template rather than path so user and
+// customer IDs do not each become a series.
+var (
+	HTTPRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests served, by route template, method and status.",
+	}, []string{"route", "method", "status"})
+	HTTPRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Time taken to serve HTTP requests, by route template, method and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+)
+
+// ServiceDuration times every UserService and CustomerService method.
+var ServiceDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "service_operation_duration_seconds",
+	Help:    "Time taken by service methods, by service, method and response status.",
+	Buckets: prometheus.DefBuckets,
+}, []string{"service", "method", "status"})
+
+// MongoDB commands and connection pool, fed by the driver's monitors.
+var (
+	MongoCommands = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "mongo_commands_total",
+		Help: "Commands sent to MongoDB, by command name and outcome.",
+	}, []string{"command", "outcome"})
+	MongoCommandDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "mongo_command_duration_seconds",
+		Help:    "Round trip time of MongoDB commands, by command name.",
+		Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"command"})
+	MongoPoolConnections = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "mongo_pool_connections",
+		Help: "Open connections in the MongoDB pool of each server.",
+	}, []string{"address"})
+	MongoPoolInUse = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "mongo_pool_connections_in_use",
+		Help: "Connections checked out of the MongoDB pool of each server.",
+	}, []string{"address"})
+	MongoPoolCheckouts = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "mongo_pool_checkouts_total",
+		Help: "Connections checked out of the MongoDB pool of each server.",
+	}, []string{"address"})
+	MongoPoolCheckoutFailures = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "mongo_pool_checkout_failures_total",
+		Help: "Failed checkouts from the MongoDB pool, by server and reason; reason=\"timeout\" means the pool was exhausted.",
+	}, []string{"address", "reason"})
+)
+
+// Business counters.
+var (
+	UsersCreated = factory.NewCounter(prometheus.CounterOpts{
+		Name: "users_created_total",
+		Help: "Users created.",
+	})
+	CustomersCreated = factory.NewCounter(prometheus.CounterOpts{
+		Name: "customers_created_total",
+		Help: "Customers created, one at a time, in batches or by imports.",
+	})
+)
+
+// Outbox backlog, refreshed by the relay, by tenant. The tenant is empty when
+// tenancy is off.
+var (
+	OutboxPending = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "outbox_pending_messages",
+		Help: "Outbox messages waiting to be delivered, by tenant.",
+	}, []string{"tenant"})
+	OutboxDead = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "outbox_dead_messages",
+		Help: "Outbox messages the relay gave up on, by tenant.",
+	}, []string{"tenant"})
+	OutboxLag = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "outbox_lag_seconds",
+		Help: "Age of the oldest outbox message waiting to be delivered, by tenant.",
+	}, []string{"tenant"})
+)
+
+// Cache use, by cached repository.
+var (
+	CacheLookups = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "cache_lookups_total",
+		Help: "Cache lookups, by repository and result (hit or miss).",
+	}, []string{"repository", "result"})
+	CacheInvalidations = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "cache_invalidations_total",
+		Help: "Cache entries invalidated, by repository.",
+	}, []string{"repository"})
+	CacheErrors = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "cache_errors_total",
+		Help: "Failed cache operations, by repository.",
+	}, []string{"repository"})
+)
+
+// ObserveService records how long a service method that started at start
+// took. A failed call without a status counts as a 500.
+func ObserveService(service string, method string, start time.Time, status int, err error) {
+	if status == 0 {
+		status = http.StatusOK
+		if err != nil {
+			status = http.StatusInternalServerError
+		}
+	}
+	ServiceDuration.WithLabelValues(service, method, strconv.Itoa(status)).Observe(time.Since(start).Seconds())
+}
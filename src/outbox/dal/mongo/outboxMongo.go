@@ -0,0 +1,117 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"somdeep-demo-app/src/database"
+	"somdeep-demo-app/src/outbox/interfaces"
+	"somdeep-demo-app/src/outbox/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type outboxRepository struct {
+	outboxCollection *database.Collection
+}
+
+func NewOutboxRepository(tenancy *database.Tenancy) interfaces.OutboxRepository {
+	outboxCollection := database.OpenTenantCollection(tenancy, "outbox")
+	return &outboxRepository{
+		outboxCollection: outboxCollection,
+	}
+}
+
+func (r *outboxRepository) AddOutboxMessages(ctx context.Context, messages []models.OutboxMessage) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	documents := make([]interface{}, len(messages))
+	for i, message := range messages {
+		documents[i] = message
+	}
+	_, err := r.outboxCollection.InsertMany(ctx, documents)
+	return err
+}
+
+func (r *outboxRepository) ClaimDueMessage(ctx context.Context, now time.Time, lease time.Duration) (*models.OutboxMessage, error) {
+	filter := bson.M{"status": models.StatusPending, "next_attempt_at": bson.M{"$lte": now}}
+	update := bson.M{"$set": bson.M{"next_attempt_at": now.Add(lease)}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var message models.OutboxMessage
+	err := r.outboxCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&message)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+func (r *outboxRepository) MarkDelivered(ctx context.Context, message models.OutboxMessage, at time.Time) error {
+	_, err := r.outboxCollection.UpdateByID(ctx, message.ID, bson.M{
+		"$set": bson.M{"status": models.StatusDelivered, "delivered_at": at},
+		"$inc": bson.M{"attempts": 1},
+	})
+	return err
+}
+
+func (r *outboxRepository) MarkFailed(ctx context.Context, message models.OutboxMessage, lastError string, nextAttemptAt time.Time, dead bool) error {
+	status := models.StatusPending
+	if dead {
+		status = models.StatusDead
+	}
+	_, err := r.outboxCollection.UpdateByID(ctx, message.ID, bson.M{
+		"$set": bson.M{"status": status, "last_error": lastError, "next_attempt_at": nextAttemptAt},
+		"$inc": bson.M{"attempts": 1},
+	})
+	return err
+}
+
+func (r *outboxRepository) RequeueDeadMessage(ctx context.Context, eventId string, now time.Time) (bool, error) {
+	result, err := r.outboxCollection.UpdateOne(ctx,
+		bson.M{"event.id": eventId, "status": models.StatusDead},
+		bson.M{"$set": bson.M{"status": models.StatusPending, "attempts": 0, "next_attempt_at": now}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+func (r *outboxRepository) CountByStatus(ctx context.Context, status string) (int64, error) {
+	return r.outboxCollection.CountDocuments(ctx, bson.M{"status": status})
+}
+
+func (r *outboxRepository) OldestPendingCreatedAt(ctx context.Context) (*time.Time, error) {
+	opts := options.FindOne().
+		SetSort(bson.D{{Key: "created_at", Value: 1}}).
+		SetProjection(bson.M{"created_at": 1})
+
+	var message models.OutboxMessage
+	err := r.outboxCollection.FindOne(ctx, bson.M{"status": models.StatusPending}, opts).Decode(&message)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &message.Created_at, nil
+}
+
+// EnsureIndexes creates the index the relay polls on and a TTL index that
+// expires delivered messages after the retention period.
+func (r *outboxRepository) EnsureIndexes(ctx context.Context, deliveredRetention time.Duration) error {
+	_, err := r.outboxCollection.CreateIndexes(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}, {Key: "created_at", Value: 1}}},
+		{Keys: bson.D{{Key: "event.id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "delivered_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(int32(deliveredRetention.Seconds()))},
+	})
+	return err
+}
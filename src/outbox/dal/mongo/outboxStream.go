@@ -0,0 +1,87 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"somdeep-demo-app/src/database"
+	eventInterfaces "somdeep-demo-app/src/events/interfaces"
+	eventModels "somdeep-demo-app/src/events/models"
+	"somdeep-demo-app/src/outbox/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// outboxStream follows inserts into the outbox with a change stream. The
+// outbox holds every committed event, including deletes with the owning
+// user_id, which a change stream on the entity collections would not carry.
+// Stream IDs are change stream resume tokens, so any instance can resume a
+// stream started on another one while the oplog still covers it.
+type outboxStream struct {
+	outboxCollection *database.Collection
+}
+
+func NewOutboxStream(tenancy *database.Tenancy) eventInterfaces.EventStream {
+	outboxCollection := database.OpenTenantCollection(tenancy, "outbox")
+	return &outboxStream{
+		outboxCollection: outboxCollection,
+	}
+}
+
+func (s *outboxStream) Follow(ctx context.Context, lastEventId string) (<-chan eventModels.StreamedEvent, error) {
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: bson.M{"operationType": "insert"}}}}
+
+	reset := false
+	opts := options.ChangeStream()
+	if lastEventId != "" {
+		opts.SetResumeAfter(bson.M{"_data": lastEventId})
+	}
+	changeStream, err := s.outboxCollection.Watch(ctx, pipeline, opts)
+	// the server rejects tokens that are malformed or older than the oplog
+	var serverErr mongo.ServerError
+	if err != nil && lastEventId != "" && errors.As(err, &serverErr) {
+		reset = true
+		changeStream, err = s.outboxCollection.Watch(ctx, pipeline)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	follower := make(chan eventModels.StreamedEvent)
+	go func() {
+		defer close(follower)
+		defer changeStream.Close(context.Background())
+
+		if reset && !send(ctx, follower, eventModels.StreamedEvent{Reset: true}) {
+			return
+		}
+		for changeStream.Next(ctx) {
+			var change struct {
+				FullDocument models.OutboxMessage `bson:"fullDocument"`
+			}
+			if err := changeStream.Decode(&change); err != nil {
+				slog.Error("outbox: failed to decode change", "error", err)
+				continue
+			}
+			id, _ := changeStream.ResumeToken().Lookup("_data").StringValueOK()
+			if !send(ctx, follower, eventModels.StreamedEvent{ID: id, Event: change.FullDocument.Event}) {
+				return
+			}
+		}
+		if err := changeStream.Err(); err != nil && ctx.Err() == nil {
+			slog.Error("outbox: change stream stopped", "error", err)
+		}
+	}()
+	return follower, nil
+}
+
+func send(ctx context.Context, follower chan<- eventModels.StreamedEvent, message eventModels.StreamedEvent) bool {
+	select {
+	case follower <- message:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,20 @@
+package interfaces
+
+import (
+	"context"
+	"somdeep-demo-app/src/outbox/models"
+	"time"
+)
+
+type OutboxRepository interface {
+	AddOutboxMessages(ctx context.Context, messages []models.OutboxMessage) error
+	// ClaimDueMessage leases the oldest pending message that is due by pushing
+	// its next attempt past lease, so concurrent relays do not pick it up too.
+	ClaimDueMessage(ctx context.Context, now time.Time, lease time.Duration) (*models.OutboxMessage, error)
+	MarkDelivered(ctx context.Context, message models.OutboxMessage, at time.Time) error
+	MarkFailed(ctx context.Context, message models.OutboxMessage, lastError string, nextAttemptAt time.Time, dead bool) error
+	RequeueDeadMessage(ctx context.Context, eventId string, now time.Time) (bool, error)
+	CountByStatus(ctx context.Context, status string) (int64, error)
+	OldestPendingCreatedAt(ctx context.Context) (*time.Time, error)
+	EnsureIndexes(ctx context.Context, deliveredRetention time.Duration) error
+}
@@ -0,0 +1,15 @@
+package interfaces
+
+import "context"
+
+type Response struct {
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+	Error   string `json:"error"`
+	Data    any    `json:"data"`
+}
+
+type OutboxService interface {
+	GetOutboxStats(ctx context.Context) (response Response, err error)
+	RequeueDeadMessage(ctx context.Context, eventId string) (response Response, err error)
+}
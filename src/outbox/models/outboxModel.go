@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	eventModels "somdeep-demo-app/src/events/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	StatusPending   = "pending"
+	StatusDelivered = "delivered"
+	StatusDead      = "dead"
+)
+
+type OutboxMessage struct {
+	ID              primitive.ObjectID `bson:"_id"`
+	Event           eventModels.Event  `json:"event"`
+	Status          string             `json:"status"`
+	Attempts        int                `json:"attempts"`
+	Last_error      string             `json:"last_error,omitempty"`
+	Created_at      time.Time          `json:"created_at"`
+	Next_attempt_at time.Time          `json:"next_attempt_at"`
+	Delivered_at    *time.Time         `json:"delivered_at,omitempty"`
+}
+
+// EventRef identifies the outbox event a requeue applied to.
+type EventRef struct {
+	Event_id string `json:"event_id"`
+}
+
+type OutboxStats struct {
+	Pending         int64   `json:"pending"`
+	Dead            int64   `json:"dead"`
+	Delivered_total int64   `json:"delivered_total"`
+	Failed_total    int64   `json:"failed_total"`
+	Dead_total      int64   `json:"dead_total"`
+	Lag_seconds     float64 `json:"lag_seconds"`
+}
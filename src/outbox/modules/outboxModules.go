@@ -0,0 +1,76 @@
+package modules
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"somdeep-demo-app/src/apperrors"
+	"somdeep-demo-app/src/outbox/interfaces"
+	"somdeep-demo-app/src/outbox/models"
+)
+
+type outboxService struct {
+	outboxRepository interfaces.OutboxRepository
+	relay            *Relay
+}
+
+func NewOutboxService(outboxRepository interfaces.OutboxRepository, relay *Relay) interfaces.OutboxService {
+	return &outboxService{
+		outboxRepository: outboxRepository,
+		relay:            relay,
+	}
+}
+
+func (s *outboxService) GetOutboxStats(ctx context.Context) (response interfaces.Response, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Second)
+	defer cancel()
+
+	var res interfaces.Response
+
+	stats, err := s.relay.Stats(ctx)
+	if err != nil {
+		res.Status = http.StatusInternalServerError
+		res.Error = err.Error()
+		res.Message = "Error occured while reading outbox stats"
+		res.Data = nil
+		return res, err
+	}
+
+	res.Status = http.StatusOK
+	res.Error = "NA"
+	res.Message = "Record Fetched Successfully"
+	res.Data = stats
+	return res, nil
+}
+
+func (s *outboxService) RequeueDeadMessage(ctx context.Context, eventId string) (response interfaces.Response, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Second)
+	defer cancel()
+
+	var res interfaces.Response
+
+	requeued, err := s.outboxRepository.RequeueDeadMessage(ctx, eventId, time.Now())
+	if err != nil {
+		res.Status = http.StatusInternalServerError
+		res.Error = err.Error()
+		res.Message = "Failed to requeue event"
+		res.Data = nil
+		return res, err
+	}
+
+	if !requeued {
+		res.Status = http.StatusNotFound
+		res.Error = "NA"
+		res.Message = "Dead-lettered event not found"
+		res.Data = nil
+		return res, apperrors.NotFound(res.Message)
+	}
+
+	s.relay.Notify()
+	res.Status = http.StatusOK
+	res.Error = "NA"
+	res.Message = "Event requeued for delivery"
+	res.Data = models.EventRef{Event_id: eventId}
+	return res, nil
+}
@@ -0,0 +1,48 @@
+package modules
+
+import (
+	"context"
+	"time"
+
+	eventModels "somdeep-demo-app/src/events/models"
+	"somdeep-demo-app/src/outbox/interfaces"
+	"somdeep-demo-app/src/outbox/models"
+	"somdeep-demo-app/src/tenant"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// outboxPublisher is the EventPublisher handed to the services. Publishing
+// only stores the events in the outbox, using the caller's context, so when it
+// is called inside a transaction the events commit or roll back together with
+// the entity change. The Relay delivers them afterwards. Events are stamped
+// with the tenant of ctx, so consumers can tell whose data changed.
+type outboxPublisher struct {
+	outboxRepository interfaces.OutboxRepository
+}
+
+func NewOutboxPublisher(outboxRepository interfaces.OutboxRepository) *outboxPublisher {
+	return &outboxPublisher{
+		outboxRepository: outboxRepository,
+	}
+}
+
+func (p *outboxPublisher) Publish(ctx context.Context, events ...eventModels.Event) error {
+	now := time.Now()
+	messages := make([]models.OutboxMessage, len(events))
+	for i, event := range events {
+		event.Tenant_id = tenant.Id(ctx)
+		messages[i] = models.OutboxMessage{
+			ID:              primitive.NewObjectID(),
+			Event:           event,
+			Status:          models.StatusPending,
+			Created_at:      now,
+			Next_attempt_at: now,
+		}
+	}
+	return p.outboxRepository.AddOutboxMessages(ctx, messages)
+}
+
+func (p *outboxPublisher) Close() error {
+	return nil
+}
@@ -0,0 +1,228 @@
+package modules
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"somdeep-demo-app/src/backoff"
+	eventInterfaces "somdeep-demo-app/src/events/interfaces"
+	"somdeep-demo-app/src/logging"
+	"somdeep-demo-app/src/metrics"
+	"somdeep-demo-app/src/outbox/interfaces"
+	"somdeep-demo-app/src/outbox/models"
+	"somdeep-demo-app/src/tenant"
+	"somdeep-demo-app/src/tracing"
+)
+
+type RelayOptions struct {
+	PollInterval time.Duration
+	BatchSize    int
+	// Lease is how long a claimed message is hidden from other relays. If the
+	// process dies mid-delivery the message becomes due again afterwards,
+	// which is what makes delivery at-least-once.
+	Lease       time.Duration
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// StatsInterval is how often the outbox gauges on /metrics are refreshed.
+	StatsInterval time.Duration
+	// Tenants lists the tenants whose outboxes are relayed. Nil means
+	// tenancy is off and there is a single outbox.
+	Tenants tenant.Lister
+}
+
+func DefaultRelayOptions() RelayOptions {
+	return RelayOptions{
+		PollInterval: 500 * time.Millisecond,
+		BatchSize:    100,
+		Lease:        30 * time.Second,
+		MaxAttempts:  10,
+		BaseBackoff:  time.Second,
+		MaxBackoff:   5 * time.Minute,
+
+		StatsInterval: 15 * time.Second,
+	}
+}
+
+// Relay moves outbox messages to the real event publisher.
+type Relay struct {
+	outboxRepository interfaces.OutboxRepository
+	publisher        eventInterfaces.EventPublisher
+	options          RelayOptions
+
+	delivered atomic.Int64
+	failed    atomic.Int64
+	dead      atomic.Int64
+
+	wake chan struct{}
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+func NewRelay(outboxRepository interfaces.OutboxRepository, publisher eventInterfaces.EventPublisher, options RelayOptions) *Relay {
+	return &Relay{
+		outboxRepository: outboxRepository,
+		publisher:        publisher,
+		options:          options,
+		wake:             make(chan struct{}, 1),
+		stop:             make(chan struct{}),
+		done:             make(chan struct{}),
+	}
+}
+
+// Start runs the relay loop in the background until Stop is called.
+func (r *Relay) Start() {
+	go r.run()
+}
+
+// Stop lets the current batch finish, drains whatever is already due and
+// returns once the loop has exited or ctx expires.
+func (r *Relay) Stop(ctx context.Context) error {
+	r.once.Do(func() { close(r.stop) })
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Notify asks the relay to poll now instead of waiting for the next tick.
+func (r *Relay) Notify() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (r *Relay) run() {
+	defer close(r.done)
+	ticker := time.NewTicker(r.options.PollInterval)
+	defer ticker.Stop()
+	statsTicker := time.NewTicker(r.options.StatsInterval)
+	defer statsTicker.Stop()
+
+	r.observe()
+	for {
+		select {
+		case <-r.stop:
+			r.drain()
+			return
+		case <-ticker.C:
+		case <-r.wake:
+		case <-statsTicker.C:
+			r.observe()
+			continue
+		}
+		r.drain()
+	}
+}
+
+// observe refreshes the outbox gauges of every tenant.
+func (r *Relay) observe() {
+	for _, ctx := range tenant.Contexts(context.Background(), r.options.Tenants) {
+		statsCtx, cancel := context.WithTimeout(ctx, r.options.StatsInterval)
+		stats, err := r.Stats(statsCtx)
+		cancel()
+		if err != nil {
+			slog.WarnContext(ctx, "outbox: reading stats failed", "error", err)
+			continue
+		}
+		tenantId := tenant.Id(ctx)
+		metrics.OutboxPending.WithLabelValues(tenantId).Set(float64(stats.Pending))
+		metrics.OutboxDead.WithLabelValues(tenantId).Set(float64(stats.Dead))
+		metrics.OutboxLag.WithLabelValues(tenantId).Set(stats.Lag_seconds)
+	}
+}
+
+// drain keeps relaying batches until nothing is due, one tenant at a time.
+func (r *Relay) drain() {
+	for _, ctx := range tenant.Contexts(context.Background(), r.options.Tenants) {
+		for {
+			relayed, err := r.RelayBatch(ctx)
+			if err != nil {
+				slog.ErrorContext(ctx, "outbox: relay failed", "error", err)
+				break
+			}
+			if relayed < r.options.BatchSize {
+				break
+			}
+		}
+	}
+}
+
+// RelayBatch claims and delivers up to BatchSize due messages and returns how
+// many it handled.
+func (r *Relay) RelayBatch(ctx context.Context) (int, error) {
+	handled := 0
+	for handled < r.options.BatchSize {
+		now := time.Now()
+		message, err := r.outboxRepository.ClaimDueMessage(ctx, now, r.options.Lease)
+		if err != nil {
+			return handled, err
+		}
+		if message == nil {
+			return handled, nil
+		}
+		handled++
+
+		// publishing and logging carry on the request and trace that caused
+		// the event
+		messageCtx := tracing.Extract(logging.WithRequestId(ctx, message.Event.Request_id), message.Event.Traceparent, message.Event.Tracestate)
+		publishErr := r.publisher.Publish(messageCtx, message.Event)
+		if publishErr == nil {
+			if err = r.outboxRepository.MarkDelivered(ctx, *message, time.Now()); err != nil {
+				// the lease will expire and the event will be delivered again
+				return handled, err
+			}
+			r.delivered.Add(1)
+			continue
+		}
+
+		r.failed.Add(1)
+		dead := message.Attempts+1 >= r.options.MaxAttempts
+		if dead {
+			r.dead.Add(1)
+			slog.WarnContext(messageCtx, "outbox: giving up on event",
+				"type", message.Event.Type,
+				"event_id", message.Event.ID,
+				"attempts", message.Attempts+1,
+				"error", publishErr)
+		}
+		nextAttempt := time.Now().Add(backoff.Exponential(r.options.BaseBackoff, r.options.MaxBackoff, message.Attempts))
+		if err = r.outboxRepository.MarkFailed(ctx, *message, publishErr.Error(), nextAttempt, dead); err != nil {
+			return handled, err
+		}
+	}
+	return handled, nil
+}
+
+// Stats reports the backlog and the relay lag, the age of the oldest message
+// still waiting to be delivered.
+func (r *Relay) Stats(ctx context.Context) (models.OutboxStats, error) {
+	stats := models.OutboxStats{
+		Delivered_total: r.delivered.Load(),
+		Failed_total:    r.failed.Load(),
+		Dead_total:      r.dead.Load(),
+	}
+
+	var err error
+	if stats.Pending, err = r.outboxRepository.CountByStatus(ctx, models.StatusPending); err != nil {
+		return stats, err
+	}
+	if stats.Dead, err = r.outboxRepository.CountByStatus(ctx, models.StatusDead); err != nil {
+		return stats, err
+	}
+	oldest, err := r.outboxRepository.OldestPendingCreatedAt(ctx)
+	if err != nil {
+		return stats, err
+	}
+	if oldest != nil {
+		stats.Lag_seconds = time.Since(*oldest).Seconds()
+	}
+	return stats, nil
+}
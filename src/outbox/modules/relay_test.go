@@ -0,0 +1,241 @@
+package modules
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	eventModels "somdeep-demo-app/src/events/models"
+	"somdeep-demo-app/src/outbox/interfaces"
+	"somdeep-demo-app/src/outbox/models"
+)
+
+// memoryOutbox is an OutboxRepository over a slice, claiming and marking
+// messages the way the Mongo repository does.
+type memoryOutbox struct {
+	interfaces.OutboxRepository
+
+	mu           sync.Mutex
+	messages     []models.OutboxMessage
+	deliveredErr error
+}
+
+func (o *memoryOutbox) ClaimDueMessage(ctx context.Context, now time.Time, lease time.Duration) (*models.OutboxMessage, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for i := range o.messages {
+		message := &o.messages[i]
+		if message.Status == models.StatusPending && !message.Next_attempt_at.After(now) {
+			message.Next_attempt_at = now.Add(lease)
+			claimed := *message
+			return &claimed, nil
+		}
+	}
+	return nil, nil
+}
+
+func (o *memoryOutbox) find(eventId string) *models.OutboxMessage {
+	for i := range o.messages {
+		if o.messages[i].Event.ID == eventId {
+			return &o.messages[i]
+		}
+	}
+	return nil
+}
+
+func (o *memoryOutbox) MarkDelivered(ctx context.Context, message models.OutboxMessage, at time.Time) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.deliveredErr != nil {
+		return o.deliveredErr
+	}
+	stored := o.find(message.Event.ID)
+	stored.Status = models.StatusDelivered
+	stored.Delivered_at = &at
+	return nil
+}
+
+func (o *memoryOutbox) MarkFailed(ctx context.Context, message models.OutboxMessage, lastError string, nextAttemptAt time.Time, dead bool) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	stored := o.find(message.Event.ID)
+	stored.Attempts++
+	stored.Last_error = lastError
+	stored.Next_attempt_at = nextAttemptAt
+	if dead {
+		stored.Status = models.StatusDead
+	}
+	return nil
+}
+
+// makeDue brings the next attempt of every pending message forward to now.
+func (o *memoryOutbox) makeDue() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for i := range o.messages {
+		o.messages[i].Next_attempt_at = time.Now().Add(-time.Second)
+	}
+}
+
+func (o *memoryOutbox) message(eventId string) models.OutboxMessage {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return *o.find(eventId)
+}
+
+// flakyPublisher fails the first failures attempts of every event.
+type flakyPublisher struct {
+	mu        sync.Mutex
+	failures  int
+	attempts  map[string]int
+	published []string
+}
+
+func (p *flakyPublisher) Publish(ctx context.Context, events ...eventModels.Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, event := range events {
+		p.attempts[event.ID]++
+		if p.attempts[event.ID] <= p.failures {
+			return errors.New("nats: no responders available for request")
+		}
+		p.published = append(p.published, event.ID)
+	}
+	return nil
+}
+
+func (p *flakyPublisher) Close() error { return nil }
+
+func newOutbox(eventIds ...string) *memoryOutbox {
+	outbox := &memoryOutbox{}
+	for _, eventId := range eventIds {
+		outbox.messages = append(outbox.messages, models.OutboxMessage{
+			Event:           eventModels.Event{ID: eventId, Type: "customer.created"},
+			Status:          models.StatusPending,
+			Created_at:      time.Now(),
+			Next_attempt_at: time.Now().Add(-time.Second),
+		})
+	}
+	return outbox
+}
+
+func testRelayOptions() RelayOptions {
+	options := DefaultRelayOptions()
+	options.MaxAttempts = 4
+	options.BaseBackoff = time.Second
+	options.MaxBackoff = 3 * time.Second
+	return options
+}
+
+// relayOnce relays one batch and fails the test on an error.
+func relayOnce(t *testing.T, relay *Relay) int {
+	t.Helper()
+	handled, err := relay.RelayBatch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return handled
+}
+
+func TestRelayBatchRetriesWithBackoff(t *testing.T) {
+	outbox := newOutbox("e-1")
+	publisher := &flakyPublisher{failures: 2, attempts: map[string]int{}}
+	relay := NewRelay(outbox, publisher, testRelayOptions())
+
+	for attempt, backoff := range []time.Duration{time.Second, 2 * time.Second} {
+		started := time.Now()
+		if handled := relayOnce(t, relay); handled != 1 {
+			t.Fatalf("attempt %d handled %d messages, want 1", attempt+1, handled)
+		}
+		message := outbox.message("e-1")
+		if message.Status != models.StatusPending || message.Attempts != attempt+1 || message.Last_error == "" {
+			t.Fatalf("after attempt %d message = %+v, want it pending with the error", attempt+1, message)
+		}
+		if delay := message.Next_attempt_at.Sub(started); delay < backoff || delay > backoff+time.Second {
+			t.Errorf("attempt %d is retried after %v, want %v", attempt+1, delay, backoff)
+		}
+
+		// not due again until the backoff has passed
+		if handled := relayOnce(t, relay); handled != 0 {
+			t.Fatalf("a message in backoff was relayed again")
+		}
+		outbox.makeDue()
+	}
+
+	if handled := relayOnce(t, relay); handled != 1 {
+		t.Fatalf("handled %d messages, want 1", handled)
+	}
+	if message := outbox.message("e-1"); message.Status != models.StatusDelivered || message.Delivered_at == nil {
+		t.Errorf("message = %+v, want it delivered", message)
+	}
+	if len(publisher.published) != 1 || relay.delivered.Load() != 1 || relay.failed.Load() != 2 || relay.dead.Load() != 0 {
+		t.Errorf("published %v, delivered %d, failed %d and dead %d, want e-1 once after 2 failures",
+			publisher.published, relay.delivered.Load(), relay.failed.Load(), relay.dead.Load())
+	}
+}
+
+func TestRelayBatchDeadLetters(t *testing.T) {
+	outbox := newOutbox("e-1")
+	publisher := &flakyPublisher{failures: 100, attempts: map[string]int{}}
+	options := testRelayOptions()
+	relay := NewRelay(outbox, publisher, options)
+
+	for attempt := 1; attempt <= options.MaxAttempts; attempt++ {
+		started := time.Now()
+		relayOnce(t, relay)
+		message := outbox.message("e-1")
+		if attempt < options.MaxAttempts && message.Status != models.StatusPending {
+			t.Fatalf("message is %s after %d attempts, want pending", message.Status, attempt)
+		}
+		// the backoff is capped at MaxBackoff
+		if delay := message.Next_attempt_at.Sub(started); delay > options.MaxBackoff+time.Second {
+			t.Errorf("attempt %d is retried after %v, more than %v", attempt, delay, options.MaxBackoff)
+		}
+		outbox.makeDue()
+	}
+
+	if message := outbox.message("e-1"); message.Status != models.StatusDead || message.Attempts != options.MaxAttempts {
+		t.Fatalf("message = %+v, want it dead after %d attempts", message, options.MaxAttempts)
+	}
+	// a dead message is never claimed again
+	if handled := relayOnce(t, relay); handled != 0 || publisher.attempts["e-1"] != options.MaxAttempts {
+		t.Errorf("a dead message was published again, %d attempts", publisher.attempts["e-1"])
+	}
+	if relay.dead.Load() != 1 || relay.failed.Load() != int64(options.MaxAttempts) {
+		t.Errorf("dead %d and failed %d, want 1 and %d", relay.dead.Load(), relay.failed.Load(), options.MaxAttempts)
+	}
+}
+
+func TestRelayBatchStopsAtBatchSize(t *testing.T) {
+	outbox := newOutbox("e-1", "e-2", "e-3", "e-4", "e-5")
+	publisher := &flakyPublisher{attempts: map[string]int{}}
+	options := testRelayOptions()
+	options.BatchSize = 2
+	relay := NewRelay(outbox, publisher, options)
+
+	for _, want := range []int{2, 2, 1, 0} {
+		if handled := relayOnce(t, relay); handled != want {
+			t.Fatalf("handled %d messages, want %d", handled, want)
+		}
+	}
+	if len(publisher.published) != 5 || publisher.published[0] != "e-1" || publisher.published[4] != "e-5" {
+		t.Errorf("published %v, want e-1 to e-5 in order", publisher.published)
+	}
+}
+
+func TestRelayBatchLeavesUnmarkedMessagesToTheLease(t *testing.T) {
+	outbox := newOutbox("e-1", "e-2")
+	outbox.deliveredErr = errors.New("server selection timeout")
+	relay := NewRelay(outbox, &flakyPublisher{attempts: map[string]int{}}, testRelayOptions())
+
+	handled, err := relay.RelayBatch(context.Background())
+	if err == nil || handled != 1 {
+		t.Fatalf("handled %d, error = %v, want the batch to stop at the first message", handled, err)
+	}
+	// the message stays pending under its lease, to be delivered again
+	if message := outbox.message("e-1"); message.Status != models.StatusPending || !message.Next_attempt_at.After(time.Now()) || relay.delivered.Load() != 0 {
+		t.Errorf("message = %+v, delivered %d, want it pending under its lease", message, relay.delivered.Load())
+	}
+}
@@ -0,0 +1,62 @@
+package mongo
+
+import (
+	"context"
+	"somdeep-demo-app/src/database"
+	"somdeep-demo-app/src/tenant/interfaces"
+	"somdeep-demo-app/src/tenant/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// tenantRepository keeps the registry of tenants in the configured database,
+// outside of any tenant.
+type tenantRepository struct {
+	tenantCollection *mongo.Collection
+}
+
+func NewTenantRepository(tenancy *database.Tenancy) interfaces.TenantRepository {
+	tenantCollection := database.OpenCollection(tenancy.Database(), "tenants")
+	return &tenantRepository{
+		tenantCollection: tenantCollection,
+	}
+}
+
+func (r *tenantRepository) AddTenant(ctx context.Context, tenant models.Tenant) error {
+	_, err := r.tenantCollection.InsertOne(ctx, tenant)
+	return err
+}
+
+func (r *tenantRepository) GetTenants(ctx context.Context) ([]models.Tenant, error) {
+	result, err := r.tenantCollection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "tenant_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	tenants := []models.Tenant{}
+	if err = result.All(ctx, &tenants); err != nil {
+		return nil, err
+	}
+	return tenants, nil
+}
+
+func (r *tenantRepository) GetTenantByTenantId(ctx context.Context, tenantId string) (models.Tenant, error) {
+	var tenant models.Tenant
+	err := r.tenantCollection.FindOne(ctx, bson.M{"tenant_id": tenantId}).Decode(&tenant)
+	return tenant, err
+}
+
+func (r *tenantRepository) DeleteTenantByTenantId(ctx context.Context, tenantId string) (*mongo.DeleteResult, error) {
+	return r.tenantCollection.DeleteOne(ctx, bson.M{"tenant_id": tenantId})
+}
+
+// EnsureIndexes makes tenant IDs unique, so two instances provisioning the
+// same tenant cannot both succeed.
+func (r *tenantRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.tenantCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "tenant_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
@@ -0,0 +1,16 @@
+package interfaces
+
+import (
+	"context"
+	"somdeep-demo-app/src/tenant/models"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type TenantRepository interface {
+	AddTenant(ctx context.Context, tenant models.Tenant) error
+	GetTenants(ctx context.Context) ([]models.Tenant, error)
+	GetTenantByTenantId(ctx context.Context, tenantId string) (models.Tenant, error)
+	DeleteTenantByTenantId(ctx context.Context, tenantId string) (*mongo.DeleteResult, error)
+	EnsureIndexes(ctx context.Context) error
+}
@@ -0,0 +1,20 @@
+package interfaces
+
+import (
+	"context"
+	"somdeep-demo-app/src/tenant/models"
+)
+
+type Response struct {
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+	Error   string `json:"error"`
+	Data    any    `json:"data"`
+}
+
+type TenantService interface {
+	GetTenants(ctx context.Context) (response Response, err error)
+	GetTenant(ctx context.Context, tenantId string) (response Response, err error)
+	ProvisionTenant(ctx context.Context, tenant models.Tenant) (response Response, err error)
+	DeprovisionTenant(ctx context.Context, tenantId string) (response Response, err error)
+}
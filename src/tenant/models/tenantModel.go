@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Tenant is a provisioned tenant. Database is where its documents live: its
+// own database in database mode, the shared one in shared mode.
+type Tenant struct {
+	ID         primitive.ObjectID `bson:"_id"`
+	Tenant_id  string             `json:"tenant_id" validate:"required,max=40"`
+	Name       string             `json:"name" validate:"required,max=100"`
+	Mode       string             `json:"mode"`
+	Database   string             `json:"database"`
+	Created_at time.Time          `json:"created_at"`
+}
@@ -0,0 +1,118 @@
+package modules
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"somdeep-demo-app/src/tenant/interfaces"
+)
+
+// Registry is the set of provisioned tenants, held in memory so resolving a
+// request's tenant costs no query. It is reloaded every interval to pick up
+// tenants other instances provisioned or deprovisioned; changes made through
+// this instance apply at once.
+type Registry struct {
+	tenantRepository interfaces.TenantRepository
+	interval         time.Duration
+
+	mu      sync.RWMutex
+	tenants map[string]bool
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+func NewRegistry(tenantRepository interfaces.TenantRepository, interval time.Duration) *Registry {
+	return &Registry{
+		tenantRepository: tenantRepository,
+		interval:         interval,
+		tenants:          map[string]bool{},
+		stop:             make(chan struct{}),
+		done:             make(chan struct{}),
+	}
+}
+
+// Load replaces the set with the tenants in the repository.
+func (r *Registry) Load(ctx context.Context) error {
+	tenants, err := r.tenantRepository.GetTenants(ctx)
+	if err != nil {
+		return err
+	}
+	loaded := make(map[string]bool, len(tenants))
+	for _, tenant := range tenants {
+		loaded[tenant.Tenant_id] = true
+	}
+	r.mu.Lock()
+	r.tenants = loaded
+	r.mu.Unlock()
+	return nil
+}
+
+// Start reloads the set in the background until Stop is called.
+func (r *Registry) Start() {
+	go r.run()
+}
+
+func (r *Registry) Stop(ctx context.Context) error {
+	r.once.Do(func() { close(r.stop) })
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *Registry) run() {
+	defer close(r.done)
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), r.interval)
+		if err := r.Load(ctx); err != nil {
+			slog.Error("tenants: failed to reload the registry", "error", err)
+		}
+		cancel()
+	}
+}
+
+// Known reports whether tenantId is provisioned.
+func (r *Registry) Known(tenantId string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.tenants[tenantId]
+}
+
+// TenantIds lists the provisioned tenants in order.
+func (r *Registry) TenantIds() []string {
+	r.mu.RLock()
+	tenantIds := make([]string, 0, len(r.tenants))
+	for tenantId := range r.tenants {
+		tenantIds = append(tenantIds, tenantId)
+	}
+	r.mu.RUnlock()
+	sort.Strings(tenantIds)
+	return tenantIds
+}
+
+func (r *Registry) add(tenantId string) {
+	r.mu.Lock()
+	r.tenants[tenantId] = true
+	r.mu.Unlock()
+}
+
+func (r *Registry) remove(tenantId string) {
+	r.mu.Lock()
+	delete(r.tenants, tenantId)
+	r.mu.Unlock()
+}
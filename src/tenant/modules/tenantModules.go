@@ -0,0 +1,247 @@
+package modules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"somdeep-demo-app/src/apperrors"
+	cacheInterfaces "somdeep-demo-app/src/cache/interfaces"
+	"somdeep-demo-app/src/database"
+	"somdeep-demo-app/src/tenant"
+	"somdeep-demo-app/src/tenant/interfaces"
+	"somdeep-demo-app/src/tenant/models"
+	"somdeep-demo-app/src/timeouts"
+
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var validate = validator.New()
+
+// Provisioner prepares a new tenant's collections, such as creating their
+// indexes. It is called with a context for the tenant.
+type Provisioner func(ctx context.Context) error
+
+type tenantService struct {
+	tenantRepository interfaces.TenantRepository
+	registry         *Registry
+	tenancy          *database.Tenancy
+	cache            cacheInterfaces.Cache
+	provisioners     []Provisioner
+	timeouts         timeouts.Timeouts
+}
+
+// NewTenantService provisions tenants by running provisioners for them and
+// deprovisions them by dropping everything they stored. cache, which may be
+// nil, is cleared on deprovisioning so a tenant provisioned again under the
+// same ID starts without stale entries. Each method is bounded by timeouts as
+// well as the caller's context.
+func NewTenantService(tenantRepository interfaces.TenantRepository, registry *Registry, tenancy *database.Tenancy, cache cacheInterfaces.Cache, timeouts timeouts.Timeouts, provisioners ...Provisioner) interfaces.TenantService {
+	return &tenantService{
+		tenantRepository: tenantRepository,
+		registry:         registry,
+		tenancy:          tenancy,
+		cache:            cache,
+		provisioners:     provisioners,
+		timeouts:         timeouts,
+	}
+}
+
+func (s *tenantService) GetTenants(ctx context.Context) (response interfaces.Response, err error) {
+	ctx, cancel := s.timeouts.Context(ctx, "GetTenants")
+	defer cancel()
+
+	var res interfaces.Response
+
+	tenants, err := s.tenantRepository.GetTenants(ctx)
+	if err != nil {
+		res.Error = err.Error()
+		res.Message = "error occured while listing tenants"
+		res.Data = nil
+		res.Status, err = database.ClassifyError(err, res.Message)
+		return res, err
+	}
+
+	res.Status = http.StatusOK
+	res.Error = "NA"
+	res.Message = "Records Fetched Successfully"
+	res.Data = tenants
+	return res, nil
+}
+
+func (s *tenantService) GetTenant(ctx context.Context, tenantId string) (response interfaces.Response, err error) {
+	ctx, cancel := s.timeouts.Context(ctx, "GetTenant")
+	defer cancel()
+
+	var res interfaces.Response
+
+	found, err := s.tenantRepository.GetTenantByTenantId(ctx, tenantId)
+	if err != nil {
+		res.Error = err.Error()
+		res.Message = "Tenant not found"
+		res.Data = nil
+		res.Status, err = database.ClassifyError(err, res.Message)
+		return res, err
+	}
+
+	res.Status = http.StatusOK
+	res.Error = "NA"
+	res.Message = "Record Fetched Successfully"
+	res.Data = found
+	return res, nil
+}
+
+// ProvisionTenant prepares the tenant's collections before registering it,
+// so requests for it are only accepted once it is ready. Provisioning again
+// after a failure is safe.
+func (s *tenantService) ProvisionTenant(ctx context.Context, newTenant models.Tenant) (response interfaces.Response, err error) {
+	ctx, cancel := s.timeouts.Context(ctx, "ProvisionTenant")
+	defer cancel()
+
+	var res interfaces.Response
+
+	if res, err = s.enabled(); err != nil {
+		return res, err
+	}
+
+	if err = validateTenant(newTenant); err != nil {
+		res.Status = http.StatusBadRequest
+		res.Error = err.Error()
+		res.Message = "Validation Error"
+		res.Data = nil
+		return res, err
+	}
+
+	_, err = s.tenantRepository.GetTenantByTenantId(ctx, newTenant.Tenant_id)
+	if err == nil {
+		res.Status = http.StatusConflict
+		res.Error = "NA"
+		res.Message = fmt.Sprintf("Tenant %q is already provisioned", newTenant.Tenant_id)
+		res.Data = nil
+		return res, apperrors.Conflict(res.Message)
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		res.Error = err.Error()
+		res.Message = "Tenant was not provisioned"
+		res.Data = nil
+		res.Status, err = database.ClassifyError(err, res.Message)
+		return res, err
+	}
+
+	tenantCtx := tenant.WithTenant(ctx, newTenant.Tenant_id)
+	for _, provision := range s.provisioners {
+		if err = provision(tenantCtx); err != nil {
+			res.Error = err.Error()
+			res.Message = "Tenant was not provisioned"
+			res.Data = nil
+			res.Status, err = database.ClassifyError(err, res.Message)
+			return res, err
+		}
+	}
+
+	newTenant.ID = primitive.NewObjectID()
+	newTenant.Mode = s.tenancy.Mode()
+	newTenant.Database = s.tenancy.DatabaseName(newTenant.Tenant_id)
+	newTenant.Created_at = time.Now()
+	if err = s.tenantRepository.AddTenant(ctx, newTenant); err != nil {
+		res.Error = err.Error()
+		res.Message = "Tenant was not provisioned"
+		res.Data = nil
+		res.Status, err = database.ClassifyError(err, res.Message)
+		return res, err
+	}
+	s.registry.add(newTenant.Tenant_id)
+
+	res.Status = http.StatusCreated
+	res.Error = "NA"
+	res.Message = "Tenant Provisioned Successfully"
+	res.Data = newTenant
+	return res, nil
+}
+
+// DeprovisionTenant unregisters the tenant first, so no new request for it
+// is accepted, then deletes its data. When deleting fails the tenant is
+// registered again and the call can be repeated.
+func (s *tenantService) DeprovisionTenant(ctx context.Context, tenantId string) (response interfaces.Response, err error) {
+	ctx, cancel := s.timeouts.Context(ctx, "DeprovisionTenant")
+	defer cancel()
+
+	var res interfaces.Response
+
+	if res, err = s.enabled(); err != nil {
+		return res, err
+	}
+
+	found, err := s.tenantRepository.GetTenantByTenantId(ctx, tenantId)
+	if err != nil {
+		res.Error = err.Error()
+		res.Message = "Tenant not found or is already deprovisioned"
+		res.Data = nil
+		res.Status, err = database.ClassifyError(err, res.Message)
+		return res, err
+	}
+
+	result, err := s.tenantRepository.DeleteTenantByTenantId(ctx, tenantId)
+	if err != nil {
+		res.Error = err.Error()
+		res.Message = "Tenant was not deprovisioned"
+		res.Data = nil
+		res.Status, err = database.ClassifyError(err, res.Message)
+		return res, err
+	}
+	if result.DeletedCount < 1 {
+		res.Status = http.StatusNotFound
+		res.Error = "NA"
+		res.Message = "Tenant not found or is already deprovisioned"
+		res.Data = nil
+		return res, apperrors.NotFound(res.Message)
+	}
+	s.registry.remove(tenantId)
+
+	if err = s.tenancy.Drop(ctx, tenantId); err != nil {
+		if restoreErr := s.tenantRepository.AddTenant(ctx, found); restoreErr == nil {
+			s.registry.add(tenantId)
+		}
+		res.Error = err.Error()
+		res.Message = "Tenant data was not deleted"
+		res.Data = nil
+		res.Status, err = database.ClassifyError(err, res.Message)
+		return res, err
+	}
+	if s.cache != nil {
+		// the cache is not the source of truth, and entries expire anyway
+		_ = s.cache.Clear(ctx)
+	}
+
+	res.Status = http.StatusOK
+	res.Error = "NA"
+	res.Message = "Tenant Deprovisioned Successfully"
+	res.Data = found
+	return res, nil
+}
+
+func (s *tenantService) enabled() (interfaces.Response, error) {
+	if s.tenancy.Enabled() {
+		return interfaces.Response{}, nil
+	}
+	message := "Tenancy is not enabled, set tenancy.mode to database or shared"
+	return interfaces.Response{Status: http.StatusConflict, Error: "NA", Message: message}, apperrors.Conflict(message)
+}
+
+func validateTenant(newTenant models.Tenant) error {
+	if err := validate.Struct(newTenant); err != nil {
+		return apperrors.FromValidator("Validation Error", err, newTenant)
+	}
+	if !tenant.ValidId(newTenant.Tenant_id) {
+		return apperrors.Validation("Validation Error", apperrors.FieldError{
+			Field:   "tenant_id",
+			Rule:    "tenant_id",
+			Message: "must be lower case letters, digits and inner hyphens",
+		})
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package tenant
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"somdeep-demo-app/src/apperrors"
+	"somdeep-demo-app/src/config"
+	"strings"
+)
+
+// Resolver works out which tenant a request is for, from a header, the
+// subdomain it was sent to or a claim of its bearer token, and checks that the
+// tenant is provisioned.
+type Resolver struct {
+	resolvers []string
+	header    string
+	domain    string
+	secret    []byte
+	claim     string
+	known     func(tenantId string) bool
+}
+
+// NewResolver returns a Resolver for cfg that accepts the tenants known
+// reports as provisioned.
+func NewResolver(cfg config.Tenancy, known func(tenantId string) bool) (*Resolver, error) {
+	r := &Resolver{
+		header: cfg.Header,
+		domain: strings.ToLower(strings.Trim(cfg.Domain, ".")),
+		claim:  cfg.Token_claim,
+		known:  known,
+	}
+	for _, resolver := range strings.Split(cfg.Resolvers, ",") {
+		if resolver = strings.TrimSpace(resolver); resolver != "" {
+			r.resolvers = append(r.resolvers, resolver)
+		}
+	}
+	if cfg.Token_secret_file != "" {
+		secret, err := os.ReadFile(cfg.Token_secret_file)
+		if err != nil {
+			return nil, fmt.Errorf("reading the token secret: %w", err)
+		}
+		r.secret = []byte(strings.TrimRight(string(secret), "\r\n"))
+	}
+	return r, nil
+}
+
+// Resolve returns the tenant of a request. header looks up a request header
+// and host is the host the request was sent to. The errors are apperrors:
+// validation when no tenant is named, unauthorized when the token is not
+// valid and not found when the tenant is not provisioned.
+func (r *Resolver) Resolve(header func(name string) string, host string) (string, error) {
+	tenantId := ""
+	for _, resolver := range r.resolvers {
+		switch resolver {
+		case "header":
+			tenantId = strings.TrimSpace(header(r.header))
+		case "subdomain":
+			tenantId = r.subdomain(host)
+		case "token":
+			token, found := strings.CutPrefix(header("Authorization"), "Bearer ")
+			if !found {
+				continue
+			}
+			claim, err := tokenClaim(strings.TrimSpace(token), r.secret, r.claim)
+			if err != nil {
+				return "", apperrors.Unauthorized("Invalid bearer token: " + err.Error())
+			}
+			tenantId = claim
+		}
+		if tenantId != "" {
+			break
+		}
+	}
+
+	if tenantId == "" {
+		return "", apperrors.Validation("The request names no tenant", apperrors.FieldError{
+			Field:   r.header,
+			Rule:    "required",
+			Message: "or another way of naming the tenant (" + strings.Join(r.resolvers, ", ") + ") is required",
+		})
+	}
+	if !ValidId(tenantId) || !r.known(tenantId) {
+		return "", apperrors.NotFound(fmt.Sprintf("Tenant %q is not provisioned", tenantId))
+	}
+	return tenantId, nil
+}
+
+// subdomain returns the label in front of the configured domain, as in
+// acme.api.example.com, or "".
+func (r *Resolver) subdomain(host string) string {
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+	label, found := strings.CutSuffix(strings.ToLower(host), "."+r.domain)
+	if !found || r.domain == "" || strings.Contains(label, ".") {
+		return ""
+	}
+	return label
+}
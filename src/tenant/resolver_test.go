@@ -0,0 +1,72 @@
+package tenant
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"somdeep-demo-app/src/apperrors"
+	"somdeep-demo-app/src/config"
+)
+
+func newTestResolver(t *testing.T, resolvers string) *Resolver {
+	t.Helper()
+	secretFile := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(secretFile, append(testSecret, '\n'), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	known := map[string]bool{"acme": true, "globex": true, "initech": true}
+	resolver, err := NewResolver(config.Tenancy{
+		Resolvers:         resolvers,
+		Header:            "X-Tenant-ID",
+		Domain:            "api.example.com.",
+		Token_secret_file: secretFile,
+		Token_claim:       "tenant",
+	}, func(tenantId string) bool { return known[tenantId] })
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resolver
+}
+
+func TestResolverTriesResolversInOrder(t *testing.T) {
+	token := "Bearer " + signToken(t, map[string]any{"alg": "HS256"}, map[string]any{"tenant": "initech"}, testSecret)
+	forged := "Bearer " + signToken(t, map[string]any{"alg": "HS256"}, map[string]any{"tenant": "initech"}, []byte("other-secret"))
+
+	tests := []struct {
+		name      string
+		resolvers string
+		headers   map[string]string
+		host      string
+		tenantId  string
+		kind      string
+	}{
+		{name: "header first", resolvers: "header, subdomain, token", headers: map[string]string{"X-Tenant-ID": "acme", "Authorization": token}, host: "globex.api.example.com", tenantId: "acme"},
+		{name: "falls through to the subdomain", resolvers: "header,subdomain,token", headers: map[string]string{"Authorization": token}, host: "globex.api.example.com:8080", tenantId: "globex"},
+		{name: "falls through to the token", resolvers: "header,subdomain,token", headers: map[string]string{"Authorization": token}, host: "api.example.com", tenantId: "initech"},
+		{name: "token first", resolvers: "token,header", headers: map[string]string{"X-Tenant-ID": "acme", "Authorization": token}, tenantId: "initech"},
+		{name: "no bearer token", resolvers: "token,header", headers: map[string]string{"X-Tenant-ID": "acme", "Authorization": "Basic YWRhOg=="}, tenantId: "acme"},
+		{name: "invalid token", resolvers: "token,header", headers: map[string]string{"X-Tenant-ID": "acme", "Authorization": forged}, kind: "unauthorized"},
+		{name: "unlisted resolver", resolvers: "subdomain", headers: map[string]string{"X-Tenant-ID": "acme"}, host: "example.com", kind: "validation"},
+		{name: "nested subdomain", resolvers: "subdomain", host: "eu.acme.api.example.com", kind: "validation"},
+		{name: "not provisioned", resolvers: "header", headers: map[string]string{"X-Tenant-ID": "umbrella"}, kind: "not-found"},
+		{name: "invalid id", resolvers: "header", headers: map[string]string{"X-Tenant-ID": "ACME"}, kind: "not-found"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resolver := newTestResolver(t, test.resolvers)
+			tenantId, err := resolver.Resolve(func(name string) string { return test.headers[name] }, test.host)
+			if test.kind != "" {
+				var appErr apperrors.Error
+				if !errors.As(err, &appErr) || appErr.Kind() != test.kind {
+					t.Fatalf("error = %v, want a %s error", err, test.kind)
+				}
+				return
+			}
+			if err != nil || tenantId != test.tenantId {
+				t.Errorf("tenant = %q, error = %v, want %q", tenantId, err, test.tenantId)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"regexp"
+)
+
+// ErrMissing is returned when tenancy is on and a context names no tenant, so
+// nothing can be read or written without knowing whose data it is.
+var ErrMissing = errors.New("no tenant given for a tenant-scoped operation")
+
+// idPattern keeps IDs usable in database names, subdomains and cache keys.
+var idPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,38}[a-z0-9])?$`)
+
+// ValidId reports whether id can name a tenant: 1 to 40 lower case letters,
+// digits and inner hyphens.
+func ValidId(id string) bool {
+	return idPattern.MatchString(id)
+}
+
+type tenantKey struct{}
+
+type allTenantsKey struct{}
+
+// WithTenant returns a context whose queries only see tenantId's data.
+func WithTenant(ctx context.Context, tenantId string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantId)
+}
+
+// Id returns the tenant ctx carries, or "".
+func Id(ctx context.Context) string {
+	tenantId, _ := ctx.Value(tenantKey{}).(string)
+	return tenantId
+}
+
+// WithAllTenants marks ctx as deliberately following every tenant. Only
+// change streams honour it, for infrastructure such as cache invalidation;
+// every other query still needs a single tenant.
+func WithAllTenants(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allTenantsKey{}, true)
+}
+
+// AllTenants reports whether ctx was marked by WithAllTenants.
+func AllTenants(ctx context.Context) bool {
+	all, _ := ctx.Value(allTenantsKey{}).(bool)
+	return all
+}
+
+// Lister lists the provisioned tenants.
+type Lister interface {
+	TenantIds() []string
+}
+
+// Contexts returns a context for each tenant lister lists, for background
+// work that goes through the tenants one at a time. A nil lister means
+// tenancy is off, and ctx is the only context.
+func Contexts(ctx context.Context, lister Lister) []context.Context {
+	if lister == nil {
+		return []context.Context{ctx}
+	}
+	tenantIds := lister.TenantIds()
+	contexts := make([]context.Context, len(tenantIds))
+	for i, tenantId := range tenantIds {
+		contexts[i] = WithTenant(ctx, tenantId)
+	}
+	return contexts
+}
@@ -0,0 +1,31 @@
+package tenant
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidId(t *testing.T) {
+	tests := []struct {
+		id    string
+		valid bool
+	}{
+		{id: "acme", valid: true},
+		{id: "a", valid: true},
+		{id: "acme-42", valid: true},
+		{id: strings.Repeat("a", 40), valid: true},
+		{id: "", valid: false},
+		{id: strings.Repeat("a", 41), valid: false},
+		{id: "-acme", valid: false},
+		{id: "acme-", valid: false},
+		{id: "Acme", valid: false},
+		{id: "acme_corp", valid: false},
+		{id: "acme.corp", valid: false},
+		{id: "../admin", valid: false},
+	}
+	for _, test := range tests {
+		if valid := ValidId(test.id); valid != test.valid {
+			t.Errorf("ValidId(%q) = %v, want %v", test.id, valid, test.valid)
+		}
+	}
+}
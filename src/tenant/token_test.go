@@ -0,0 +1,69 @@
+package tenant
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testSecret = []byte("test-secret")
+
+// signToken returns a JWT of header and claims signed with HS256 and secret,
+// whatever alg header names.
+func signToken(t *testing.T, header map[string]any, claims map[string]any, secret []byte) string {
+	t.Helper()
+	segment := func(value any) string {
+		data, err := json.Marshal(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	signed := segment(header) + "." + segment(claims)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestTokenClaim(t *testing.T) {
+	hs256 := map[string]any{"alg": "HS256", "typ": "JWT"}
+	now := time.Now().Unix()
+	valid := signToken(t, hs256, map[string]any{"tenant": "acme", "exp": now + 60, "nbf": now - 60}, testSecret)
+	unsigned := strings.Join(strings.Split(signToken(t, map[string]any{"alg": "none"}, map[string]any{"tenant": "acme"}, testSecret), ".")[:2], ".") + "."
+
+	tests := []struct {
+		name  string
+		token string
+		err   string
+	}{
+		{name: "valid", token: valid},
+		{name: "no expiry", token: signToken(t, hs256, map[string]any{"tenant": "acme"}, testSecret)},
+		{name: "other secret", token: signToken(t, hs256, map[string]any{"tenant": "acme"}, []byte("other-secret")), err: "invalid signature"},
+		{name: "tampered claims", token: strings.Replace(valid, strings.Split(valid, ".")[1], strings.Split(signToken(t, hs256, map[string]any{"tenant": "evil"}, testSecret), ".")[1], 1), err: "invalid signature"},
+		{name: "alg none", token: unsigned, err: `unsupported signing algorithm "none"`},
+		{name: "alg none signed", token: signToken(t, map[string]any{"alg": "none"}, map[string]any{"tenant": "acme"}, testSecret), err: `unsupported signing algorithm "none"`},
+		{name: "other algorithm", token: signToken(t, map[string]any{"alg": "HS512"}, map[string]any{"tenant": "acme"}, testSecret), err: `unsupported signing algorithm "HS512"`},
+		{name: "expired", token: signToken(t, hs256, map[string]any{"tenant": "acme", "exp": now - 1}, testSecret), err: "token has expired"},
+		{name: "not valid yet", token: signToken(t, hs256, map[string]any{"tenant": "acme", "nbf": now + 60}, testSecret), err: "token is not valid yet"},
+		{name: "not a JWT", token: "acme", err: "not a JWT"},
+		{name: "malformed header", token: "!!." + strings.SplitN(valid, ".", 2)[1], err: "malformed token"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			claim, err := tokenClaim(test.token, testSecret, "tenant")
+			if test.err != "" {
+				if err == nil || err.Error() != test.err {
+					t.Fatalf("error = %v, want %q", err, test.err)
+				}
+				return
+			}
+			if err != nil || claim != "acme" {
+				t.Errorf("claim = %q, error = %v, want acme", claim, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package timeouts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Timeouts bounds how long each service operation may run. Operations maps a
+// method name such as "GetUsers" to its own limit and every other operation
+// uses Default. A limit of zero leaves the caller's deadline as the only one.
+type Timeouts struct {
+	Default    time.Duration
+	Operations map[string]time.Duration
+}
+
+// Defaults gives lookups and writes 30 seconds, the streaming exports, imports
+// and gRPC streams 30 minutes and tenant provisioning, which creates every index, 2
+// minutes.
+func Defaults() Timeouts {
+	return Timeouts{
+		Default: 30 * time.Second,
+		Operations: map[string]time.Duration{
+			"ExportUsers":             30 * time.Minute,
+			"ExportCustomers":         30 * time.Minute,
+			"ImportCustomersByUserId": 30 * time.Minute,
+			"StreamUsers":             30 * time.Minute,
+			"StreamCustomers":         30 * time.Minute,
+			"BatchCustomersByUserId":  2 * time.Minute,
+			"ProvisionTenant":         2 * time.Minute,
+			"DeprovisionTenant":       2 * time.Minute,
+		},
+	}
+}
+
+// Operations names every service operation a limit can be set for, the
+// methods that run with Context.
+var Operations = []string{
+	"AddCustomerByUserId", "AddUser", "AddWebhook", "BatchCustomersByUserId",
+	"DeleteCustomerByCustomerId", "DeleteCustomersByUserId", "DeleteUser", "DeleteWebhook",
+	"DeprovisionTenant", "ExportCustomers", "ExportUsers", "GetAllCustomers",
+	"GetCustomerByCustomerId", "GetCustomersByUserId", "GetCustomersByUserIds", "GetDeliveries",
+	"GetDelivery", "GetTenant", "GetTenants", "GetUser", "GetUsers", "GetWebhook", "GetWebhooks",
+	"ImportCustomersByUserId", "PingWebhook", "ProvisionTenant", "Redeliver",
+	"StreamCustomerChanges", "StreamCustomers", "StreamUsers",
+	"UpdateCustomerByCustomerId", "UpdateUser", "UpdateWebhook",
+}
+
+func known(operation string) bool {
+	for _, name := range Operations {
+		if name == operation {
+			return true
+		}
+	}
+	return false
+}
+
+// For returns the limit of an operation.
+func (t Timeouts) For(operation string) time.Duration {
+	if limit, ok := t.Operations[operation]; ok {
+		return limit
+	}
+	return t.Default
+}
+
+// Context derives the context an operation runs with from the caller's, so
+// that the operation ends at whichever of the two deadlines comes first and
+// when the caller goes away.
+func (t Timeouts) Context(ctx context.Context, operation string) (context.Context, context.CancelFunc) {
+	limit := t.For(operation)
+	if limit <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, limit)
+}
+
+// Parse applies a comma-separated list of operation=duration pairs, such as
+// "default=10s,ExportCustomers=1h", on top of base. The name "default" sets
+// the limit of every operation not listed; any other name must be one of
+// Operations, so a misspelt operation is an error rather than ignored.
+func Parse(value string, base Timeouts) (Timeouts, error) {
+	parsed := Timeouts{Default: base.Default, Operations: map[string]time.Duration{}}
+	for operation, limit := range base.Operations {
+		parsed.Operations[operation] = limit
+	}
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		operation, raw, ok := strings.Cut(pair, "=")
+		if !ok {
+			return Timeouts{}, fmt.Errorf("timeout %q is not operation=duration", pair)
+		}
+		limit, err := time.ParseDuration(strings.TrimSpace(raw))
+		if err != nil || limit < 0 {
+			return Timeouts{}, fmt.Errorf("timeout %q has an invalid duration", pair)
+		}
+		operation = strings.TrimSpace(operation)
+		switch {
+		case operation == "default":
+			parsed.Default = limit
+		case known(operation):
+			parsed.Operations[operation] = limit
+		default:
+			return Timeouts{}, fmt.Errorf("timeout %q names an unknown operation", pair)
+		}
+	}
+	return parsed, nil
+}
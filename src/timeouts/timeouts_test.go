@@ -0,0 +1,76 @@
+package timeouts
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value string
+		check func(parsed Timeouts) bool
+		err   bool
+	}{
+		{value: "", check: func(parsed Timeouts) bool { return parsed.For("ExportUsers") == 30*time.Minute && parsed.For("GetUser") == 30*time.Second }},
+		{value: "default=5s, ExportUsers=1h", check: func(parsed Timeouts) bool { return parsed.For("ExportUsers") == time.Hour && parsed.For("GetUser") == 5*time.Second }},
+		{value: "GetUser=0s", check: func(parsed Timeouts) bool { return parsed.For("GetUser") == 0 }},
+		{value: "GetUsr=5s", err: true},
+		{value: "getuser=5s", err: true},
+		{value: "GetUser", err: true},
+		{value: "GetUser=soon", err: true},
+		{value: "GetUser=-1s", err: true},
+	}
+	for _, test := range tests {
+		parsed, err := Parse(test.value, Defaults())
+		if test.err {
+			if err == nil {
+				t.Errorf("Parse(%q) succeeded, want an error", test.value)
+			}
+			continue
+		}
+		if err != nil || !test.check(parsed) {
+			t.Errorf("Parse(%q) = %+v, %v", test.value, parsed, err)
+		}
+	}
+}
+
+// TestOperationsAreKnown keeps Operations in step with the services: every
+// default and every operation a service runs with Context has to be listed,
+// or its limit could not be configured.
+func TestOperationsAreKnown(t *testing.T) {
+	for operation := range Defaults().Operations {
+		if !known(operation) {
+			t.Errorf("default operation %s is not in Operations", operation)
+		}
+	}
+
+	used := regexp.MustCompile(`timeouts\.Context\(ctx, "(\w+)"\)`)
+	found := 0
+	err := filepath.WalkDir("..", func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return err
+		}
+		source, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, match := range used.FindAllStringSubmatch(string(source), -1) {
+			found++
+			if !known(match[1]) {
+				t.Errorf("%s runs %s, which is not in Operations", path, match[1])
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found == 0 {
+		t.Fatal("found no operations in the services")
+	}
+}
@@ -0,0 +1,138 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ServiceName names the service in exported spans unless OTEL_SERVICE_NAME
+// says otherwise, and names the tracer spans are started with.
+const ServiceName = "somdeep-demo-app"
+
+const (
+	ExporterNone   = "none"
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+)
+
+// Options chooses where spans go. OTLP is configured by the standard
+// OTEL_EXPORTER_OTLP_* variables and sampling by OTEL_TRACES_SAMPLER.
+type Options struct {
+	Exporter string
+	// File is where the file exporter writes, one JSON span per line.
+	File string
+}
+
+// Setup installs the W3C trace-context and baggage propagators and, unless
+// the exporter is none, a tracer provider exporting to it. With none, spans
+// are not recorded but incoming trace context is still passed on. The
+// returned function flushes and stops the exporter.
+func Setup(ctx context.Context, options Options) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var closer io.Closer
+	switch options.Exporter {
+	case "", ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		exporter, err = otlptracehttp.New(ctx)
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterFile:
+		if options.File == "" {
+			return nil, fmt.Errorf("the file exporter needs a file")
+		}
+		var file *os.File
+		file, err = os.OpenFile(options.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err == nil {
+			closer = file
+			exporter, err = stdouttrace.New(stdouttrace.WithWriter(file))
+		}
+	default:
+		return nil, fmt.Errorf("unknown exporter %q, expected one of none, otlp, stdout or file", options.Exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	serviceResource, err := resource.New(ctx,
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(semconv.ServiceName(ServiceName)),
+		// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES win over the default name
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(serviceResource),
+	)
+	otel.SetTracerProvider(provider)
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closer != nil {
+			if closeErr := closer.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		return err
+	}, nil
+}
+
+// Start starts a span as a child of the one ctx carries.
+func Start(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(ServiceName).Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// End ends a span around a call that answered with a service status. Server
+// errors mark the span as failed; client errors such as a missing record are
+// the call working as intended and only record the status.
+func End(span trace.Span, status int, err error) {
+	if status != 0 {
+		span.SetAttributes(attribute.Int("app.response.status", status))
+	}
+	if err != nil && (status == 0 || status >= http.StatusInternalServerError) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Inject returns the W3C traceparent and tracestate of the span ctx carries,
+// for work that continues the trace elsewhere, such as a queued event.
+func Inject(ctx context.Context) (traceparent string, tracestate string) {
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+	return carrier.Get("traceparent"), carrier.Get("tracestate")
+}
+
+// Extract returns ctx continuing the trace Inject described. Empty values
+// leave ctx as it is.
+func Extract(ctx context.Context, traceparent string, tracestate string) context.Context {
+	if traceparent == "" {
+		return ctx
+	}
+	carrier := propagation.MapCarrier{"traceparent": traceparent}
+	if tracestate != "" {
+		carrier.Set("tracestate", tracestate)
+	}
+	return propagation.TraceContext{}.Extract(ctx, carrier)
+}
@@ -0,0 +1,130 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	cacheInterfaces "somdeep-demo-app/src/cache/interfaces"
+	cacheModels "somdeep-demo-app/src/cache/models"
+	eventModels "somdeep-demo-app/src/events/models"
+	"somdeep-demo-app/src/user/interfaces"
+	"somdeep-demo-app/src/user/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// cachedUserRepository is a read-through cache in front of a UserRepository.
+// Only GetUserByUserId is cached. Writes drop the affected entries, and
+// InvalidationKeys lets writes made by other instances do the same; an entry
+// re-read in the meantime lives at most ttl.
+//
+// Reads inside a transaction always go to Mongo so they see the
+// transaction's own writes and never populate the cache with them.
+type cachedUserRepository struct {
+	userRepository interfaces.UserRepository
+	cache          cacheInterfaces.Cache
+	ttl            time.Duration
+	metrics        *cacheModels.Metrics
+}
+
+func NewCachedUserRepository(userRepository interfaces.UserRepository, cache cacheInterfaces.Cache, ttl time.Duration, metrics *cacheModels.Metrics) interfaces.UserRepository {
+	return &cachedUserRepository{
+		userRepository: userRepository,
+		cache:          cache,
+		ttl:            ttl,
+		metrics:        metrics,
+	}
+}
+
+func UserKey(userId string) string {
+	return "user:" + userId
+}
+
+// InvalidationKeys returns the cache keys a user event makes stale.
+func InvalidationKeys(event eventModels.Event) []string {
+	if !strings.HasPrefix(event.Type, "user.") {
+		return nil
+	}
+	return []string{UserKey(event.Subject)}
+}
+
+func (r *cachedUserRepository) GetAllUsers(startIndex int, recordPerPage int, ctx context.Context) (*mongo.Cursor, error) {
+	return r.userRepository.GetAllUsers(startIndex, recordPerPage, ctx)
+}
+
+func (r *cachedUserRepository) FindUsers(ctx context.Context, startIndex int, recordPerPage int) (*mongo.Cursor, error) {
+	return r.userRepository.FindUsers(ctx, startIndex, recordPerPage)
+}
+
+func (r *cachedUserRepository) GetUserByUserId(ctx context.Context, userId string) (models.User, error) {
+	if mongo.SessionFromContext(ctx) != nil {
+		return r.userRepository.GetUserByUserId(ctx, userId)
+	}
+
+	key := UserKey(userId)
+	value, found, err := r.cache.Get(ctx, key)
+	if err != nil {
+		r.metrics.Error()
+	}
+	if found {
+		var user models.User
+		if err = bson.Unmarshal(value, &user); err == nil {
+			r.metrics.Hit()
+			return user, nil
+		}
+		r.metrics.Error()
+	}
+	r.metrics.Miss()
+
+	user, err := r.userRepository.GetUserByUserId(ctx, userId)
+	if err != nil {
+		return user, err
+	}
+	if value, err = bson.Marshal(user); err == nil {
+		err = r.cache.Set(ctx, key, value, r.ttl)
+	}
+	if err != nil {
+		r.metrics.Error()
+	}
+	return user, nil
+}
+
+func (r *cachedUserRepository) CountDocumentBasedOnKey(ctx context.Context, user models.User, key string) (int64, error) {
+	return r.userRepository.CountDocumentBasedOnKey(ctx, user, key)
+}
+
+func (r *cachedUserRepository) AddUserToMongoDb(ctx context.Context, user models.User) error {
+	return r.userRepository.AddUserToMongoDb(ctx, user)
+}
+
+func (r *cachedUserRepository) UpdateOneUserByUserId(ctx context.Context, opt options.UpdateOptions, filter primitive.M, updateObject primitive.D) (*mongo.UpdateResult, error) {
+	result, err := r.userRepository.UpdateOneUserByUserId(ctx, opt, filter, updateObject)
+	r.invalidate(ctx, filter)
+	return result, err
+}
+
+func (r *cachedUserRepository) DeleteOneUserByUserId(ctx context.Context, filter primitive.M) (*mongo.DeleteResult, error) {
+	result, err := r.userRepository.DeleteOneUserByUserId(ctx, filter)
+	r.invalidate(ctx, filter)
+	return result, err
+}
+
+// invalidate drops the user a write filter targets, or the whole cache when
+// the filter does not name a single user.
+func (r *cachedUserRepository) invalidate(ctx context.Context, filter primitive.M) {
+	var err error
+	if userId, ok := filter["user_id"].(string); ok {
+		err = r.cache.Delete(ctx, UserKey(userId))
+	} else {
+		err = r.cache.Clear(ctx)
+	}
+	if err != nil {
+		r.metrics.Error()
+		return
+	}
+	r.metrics.Invalidated(1)
+}
@@ -0,0 +1,212 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"somdeep-demo-app/src/cache/broker"
+	cacheModels "somdeep-demo-app/src/cache/models"
+	"somdeep-demo-app/src/cache/stores"
+	"somdeep-demo-app/src/tenant"
+	"somdeep-demo-app/src/user/interfaces"
+	"somdeep-demo-app/src/user/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// userStore is a UserRepository that keeps users per tenant in memory and
+// counts the reads that reach it.
+type userStore struct {
+	interfaces.UserRepository
+
+	mu    sync.Mutex
+	users map[string]models.User // tenant id + user id -> user
+	reads int
+}
+
+func newUserStore() *userStore {
+	return &userStore{users: map[string]models.User{}}
+}
+
+func (s *userStore) put(ctx context.Context, userId string, firstName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[tenant.Id(ctx)+"/"+userId] = models.User{User_id: userId, First_name: &firstName}
+}
+
+func (s *userStore) GetUserByUserId(ctx context.Context, userId string) (models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reads++
+	user, ok := s.users[tenant.Id(ctx)+"/"+userId]
+	if !ok {
+		return user, mongo.ErrNoDocuments
+	}
+	return user, nil
+}
+
+func (s *userStore) UpdateOneUserByUserId(ctx context.Context, opt options.UpdateOptions, filter primitive.M, updateObject primitive.D) (*mongo.UpdateResult, error) {
+	return &mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 1}, nil
+}
+
+func (s *userStore) DeleteOneUserByUserId(ctx context.Context, filter primitive.M) (*mongo.DeleteResult, error) {
+	return &mongo.DeleteResult{DeletedCount: 1}, nil
+}
+
+func (s *userStore) readCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.reads
+}
+
+// redisCache connects a Redis cache to a stand-in server that lives as long
+// as the test.
+func redisCache(t *testing.T) *stores.Redis {
+	t.Helper()
+	server, err := broker.NewRedisStandIn("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve()
+	t.Cleanup(func() { server.Close() })
+
+	cache, err := stores.NewRedis("redis://"+server.Addr(), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cache.Close() })
+	return cache
+}
+
+func firstName(t *testing.T, repository interfaces.UserRepository, ctx context.Context, userId string) string {
+	t.Helper()
+	user, err := repository.GetUserByUserId(ctx, userId)
+	if err != nil {
+		t.Fatalf("GetUserByUserId(%s): %v", userId, err)
+	}
+	return *user.First_name
+}
+
+func TestCachedUserRepositoryReadsThrough(t *testing.T) {
+	ctx := context.Background()
+	store := newUserStore()
+	store.put(ctx, "u-1", "Ada")
+	metrics := cacheModels.NewMetrics("users")
+	repository := NewCachedUserRepository(store, redisCache(t), time.Minute, metrics)
+
+	for i := 0; i < 3; i++ {
+		if name := firstName(t, repository, ctx, "u-1"); name != "Ada" {
+			t.Fatalf("read %d: first name = %q, want Ada", i, name)
+		}
+	}
+	if store.readCount() != 1 {
+		t.Errorf("store was read %d times, want once", store.readCount())
+	}
+	if stats := metrics.Snapshot(); stats.Hits != 2 || stats.Misses != 1 || stats.Errors != 0 {
+		t.Errorf("stats = %+v, want 2 hits and 1 miss", stats)
+	}
+
+	// missing users are not cached
+	for i := 0; i < 2; i++ {
+		if _, err := repository.GetUserByUserId(ctx, "u-2"); !errors.Is(err, mongo.ErrNoDocuments) {
+			t.Fatalf("GetUserByUserId(u-2) error = %v, want ErrNoDocuments", err)
+		}
+	}
+	if store.readCount() != 3 {
+		t.Errorf("store was read %d times, want 3", store.readCount())
+	}
+}
+
+func TestCachedUserRepositoryInvalidatesOnWrite(t *testing.T) {
+	ctx := context.Background()
+	store := newUserStore()
+	store.put(ctx, "u-1", "Ada")
+	store.put(ctx, "u-2", "Grace")
+	metrics := cacheModels.NewMetrics("users")
+	repository := NewCachedUserRepository(store, redisCache(t), time.Minute, metrics)
+
+	firstName(t, repository, ctx, "u-1")
+	firstName(t, repository, ctx, "u-2")
+
+	store.put(ctx, "u-1", "Augusta")
+	if _, err := repository.UpdateOneUserByUserId(ctx, options.UpdateOptions{}, primitive.M{"user_id": "u-1"}, primitive.D{}); err != nil {
+		t.Fatal(err)
+	}
+	if name := firstName(t, repository, ctx, "u-1"); name != "Augusta" {
+		t.Errorf("first name after update = %q, want Augusta", name)
+	}
+	// other users stay cached
+	reads := store.readCount()
+	firstName(t, repository, ctx, "u-2")
+	if store.readCount() != reads {
+		t.Error("updating u-1 dropped u-2 from the cache")
+	}
+
+	// a filter that does not name one user clears everything
+	store.put(ctx, "u-2", "Hopper")
+	if _, err := repository.DeleteOneUserByUserId(ctx, primitive.M{"email": "grace@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if name := firstName(t, repository, ctx, "u-2"); name != "Hopper" {
+		t.Errorf("first name after clearing = %q, want Hopper", name)
+	}
+	if stats := metrics.Snapshot(); stats.Invalidations != 2 {
+		t.Errorf("invalidations = %d, want 2", stats.Invalidations)
+	}
+}
+
+func TestCachedUserRepositoryKeepsTenantsApart(t *testing.T) {
+	acme := tenant.WithTenant(context.Background(), "acme")
+	globex := tenant.WithTenant(context.Background(), "globex")
+	store := newUserStore()
+	store.put(acme, "u-1", "Ada")
+	store.put(globex, "u-1", "Grace")
+	redis := redisCache(t)
+	metrics := cacheModels.NewMetrics("users")
+	repository := NewCachedUserRepository(store, stores.NewTenant(redis), time.Minute, metrics)
+
+	// twice each, so the second read of each tenant comes from the cache
+	for i := 0; i < 2; i++ {
+		if name := firstName(t, repository, acme, "u-1"); name != "Ada" {
+			t.Errorf("acme read %d: first name = %q, want Ada", i, name)
+		}
+		if name := firstName(t, repository, globex, "u-1"); name != "Grace" {
+			t.Errorf("globex read %d: first name = %q, want Grace", i, name)
+		}
+	}
+	if store.readCount() != 2 {
+		t.Errorf("store was read %d times, want once per tenant", store.readCount())
+	}
+	for _, key := range []string{"tenant:acme:" + UserKey("u-1"), "tenant:globex:" + UserKey("u-1")} {
+		if _, found, err := redis.Get(context.Background(), key); err != nil || !found {
+			t.Errorf("redis has no %s: %v", key, err)
+		}
+	}
+	if _, found, _ := redis.Get(context.Background(), UserKey("u-1")); found {
+		t.Error("an entry was written without a tenant prefix")
+	}
+
+	// a write in one tenant leaves the other's entry alone
+	if _, err := repository.UpdateOneUserByUserId(acme, options.UpdateOptions{}, primitive.M{"user_id": "u-1"}, primitive.D{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, found, _ := redis.Get(context.Background(), "tenant:acme:"+UserKey("u-1")); found {
+		t.Error("the update did not drop the acme entry")
+	}
+	if _, found, _ := redis.Get(context.Background(), "tenant:globex:"+UserKey("u-1")); !found {
+		t.Error("the acme update dropped the globex entry")
+	}
+
+	// without a tenant the cache is bypassed, not shared
+	if _, err := repository.GetUserByUserId(context.Background(), "u-1"); !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Errorf("read without a tenant error = %v, want the store's ErrNoDocuments", err)
+	}
+	if stats := metrics.Snapshot(); stats.Errors == 0 {
+		t.Error("a read without a tenant was not counted as a cache error")
+	}
+}
@@ -14,11 +14,11 @@ import (
 )
 
 type userRepository struct {
-	userCollection *mongo.Collection
+	userCollection *database.Collection
 }
 
-func NewUserRepository(client *mongo.Client) interfaces.UserRepository {
-	userCollection := database.OpenCollection(client, "user")
+func NewUserRepository(tenancy *database.Tenancy) interfaces.UserRepository {
+	userCollection := database.OpenTenantCollection(tenancy, "user")
 	return &userRepository{
 		userCollection: userCollection,
 	}
@@ -42,6 +42,21 @@ func (r *userRepository) GetAllUsers(startIndex int, recordPerPage int, ctx cont
 	return result, err
 }
 
+// FindUsers returns a plain cursor in _id order, which the default index
+// serves without a blocking sort, so callers can stream large result sets
+// instead of buffering them. The password hash is never projected. A
+// recordPerPage of 0 means no limit.
+func (r *userRepository) FindUsers(ctx context.Context, startIndex int, recordPerPage int) (result *mongo.Cursor, err error) {
+	opts := options.Find().
+		SetProjection(bson.M{"password": 0}).
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetSkip(int64(startIndex))
+	if recordPerPage > 0 {
+		opts.SetLimit(int64(recordPerPage))
+	}
+	return r.userCollection.Find(ctx, bson.M{}, opts)
+}
+
 func (r *userRepository) GetUserByUserId(ctx context.Context, userId string) (user models.User, result error) {
 	result = r.userCollection.FindOne(ctx, bson.M{"user_id": userId}).Decode(&user)
 	return user, result
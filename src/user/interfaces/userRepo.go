@@ -11,6 +11,7 @@ import (
 
 type UserRepository interface {
 	GetAllUsers(startIndex int, recordPerPage int, ctx context.Context) (*mongo.Cursor, error)
+	FindUsers(ctx context.Context, startIndex int, recordPerPage int) (*mongo.Cursor, error)
 	GetUserByUserId(ctx context.Context, userId string) (models.User, error)
 	CountDocumentBasedOnKey(ctx context.Context, user models.User, key string) (int64, error)
 	AddUserToMongoDb(ctx context.Context, user models.User) error
@@ -1,6 +1,11 @@
 package interfaces
 
-import "somdeep-demo-app/src/user/models"
+import (
+	"context"
+	"io"
+	auditModels "somdeep-demo-app/src/audit/models"
+	"somdeep-demo-app/src/user/models"
+)
 
 type Response struct {
 	Status  int    `json:"status"`
@@ -10,9 +15,11 @@ type Response struct {
 }
 
 type UserService interface {
-	GetUsers(recordPerPage int, page int, startIndex int) (response Response, err error)
-	GetUser(userId string) (response Response, err error)
-	AddUser(user models.User) (response Response, err error)
-	UpdateUser(userId string, user models.User) (response Response, err error)
-	DeleteUser(userId string) (response Response, err error)
+	GetUsers(ctx context.Context, recordPerPage int, page int, startIndex int) (response Response, err error)
+	GetUser(ctx context.Context, userId string) (response Response, err error)
+	AddUser(ctx context.Context, actor auditModels.Actor, user models.User) (response Response, err error)
+	UpdateUser(ctx context.Context, actor auditModels.Actor, userId string, user models.User) (response Response, err error)
+	DeleteUser(ctx context.Context, actor auditModels.Actor, userId string) (response Response, err error)
+	ExportUsers(ctx context.Context, filter models.UserExportFilter, format string, writer io.Writer) (response Response, err error)
+	StreamUsers(ctx context.Context, send func(user models.User) error) (response Response, err error)
 }
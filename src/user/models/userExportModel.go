@@ -0,0 +1,11 @@
+package models
+
+// UserExportColumns is the fixed column order used by every export format.
+// The password hash is deliberately not exportable.
+var UserExportColumns = []string{"user_id", "first_name", "last_name", "email", "phone", "created_at", "updated_at"}
+
+type UserExportFilter struct {
+	StartIndex    int
+	RecordPerPage int
+	Fields        []string
+}
@@ -17,3 +17,8 @@ type User struct {
 	Created_at time.Time          `json:"created_at"`
 	Updated_at time.Time          `json:"updated_at"`
 }
+
+// UserRef identifies the user a create or delete applied to.
+type UserRef struct {
+	User_id string `json:"user_id"`
+}
@@ -0,0 +1,100 @@
+package modules
+
+import (
+	"context"
+
+	auditModels "somdeep-demo-app/src/audit/models"
+	auditModules "somdeep-demo-app/src/audit/modules"
+	eventModels "somdeep-demo-app/src/events/models"
+	"somdeep-demo-app/src/tracing"
+	"somdeep-demo-app/src/user/models"
+)
+
+var userEventTypes = map[string]string{
+	auditModels.ActionCreate: eventModels.UserCreated,
+	auditModels.ActionUpdate: eventModels.UserUpdated,
+	auditModels.ActionDelete: eventModels.UserDeleted,
+}
+
+// userChange is a change from Before to After, where a nil side means the
+// user did not exist on that side of the change.
+type userChange struct {
+	Action string
+	Before *models.User
+	After  *models.User
+}
+
+// recordChange writes the audit entry and publishes the domain event for
+// change. It must be called with the transaction context of the write so that
+// both are stored atomically with it; an error aborts the transaction.
+func (s *userService) recordChange(ctx context.Context, actor auditModels.Actor, change userChange) error {
+	entry := userAuditEntry(change)
+	event, err := userEvent(change, entry)
+	if err != nil {
+		return err
+	}
+	event.Actor = actor.ID
+	event.Request_id = actor.Request_id
+	event.Traceparent, event.Tracestate = tracing.Inject(ctx)
+	if err = s.auditService.Record(ctx, actor, entry); err != nil {
+		return err
+	}
+	return s.eventPublisher.Publish(ctx, event)
+}
+
+func userAuditEntry(change userChange) auditModels.AuditEntry {
+	entry := auditModels.AuditEntry{Entity_type: auditModels.EntityUser, Action: change.Action}
+	var beforeSnapshot, afterSnapshot map[string]any
+	if change.Before != nil {
+		beforeSnapshot = userSnapshot(*change.Before)
+		entry.Entity_id = change.Before.User_id
+	}
+	if change.After != nil {
+		afterSnapshot = userSnapshot(*change.After)
+		entry.Entity_id = change.After.User_id
+	}
+	entry.Changes = auditModules.Diff(beforeSnapshot, afterSnapshot)
+	return entry
+}
+
+func userEvent(change userChange, entry auditModels.AuditEntry) (eventModels.Event, error) {
+	eventType := userEventTypes[change.Action]
+	before, after := change.Before, change.After
+	if after == nil {
+		return eventModels.NewEvent(eventType, before.User_id, eventModels.UserDeletedPayload{User_id: before.User_id})
+	}
+
+	payload := eventModels.UserPayload{
+		User_id:    after.User_id,
+		First_name: stringValue(after.First_name),
+		Last_name:  stringValue(after.Last_name),
+		Email:      stringValue(after.Email),
+		Phone:      stringValue(after.Phone),
+		Created_at: after.Created_at.UTC(),
+		Updated_at: after.Updated_at.UTC(),
+	}
+	if change.Action == auditModels.ActionUpdate {
+		for _, fieldChange := range entry.Changes {
+			payload.Changed = append(payload.Changed, fieldChange.Field)
+		}
+	}
+	return eventModels.NewEvent(eventType, after.User_id, payload)
+}
+
+// userSnapshot is the audited view of a user. The password hash is left out so
+// it never reaches the audit trail.
+func userSnapshot(user models.User) map[string]any {
+	if user.First_name == nil && user.Email == nil {
+		// only the id is known, there is nothing to diff
+		return nil
+	}
+	return map[string]any{
+		"user_id":    user.User_id,
+		"first_name": stringValue(user.First_name),
+		"last_name":  stringValue(user.Last_name),
+		"email":      stringValue(user.Email),
+		"phone":      stringValue(user.Phone),
+		"created_at": user.Created_at.UTC(),
+		"updated_at": user.Updated_at.UTC(),
+	}
+}
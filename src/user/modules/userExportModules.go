@@ -0,0 +1,160 @@
+package modules
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"somdeep-demo-app/src/database"
+	"somdeep-demo-app/src/export"
+	"somdeep-demo-app/src/user/interfaces"
+	"somdeep-demo-app/src/user/models"
+)
+
+func (s *userService) ExportUsers(ctx context.Context, filter models.UserExportFilter, format string, writer io.Writer) (response interfaces.Response, err error) {
+	ctx, cancel := s.timeouts.Context(ctx, "ExportUsers")
+	defer cancel()
+
+	var res interfaces.Response
+
+	columns, err := export.SelectColumns(models.UserExportColumns, filter.Fields)
+	if err == nil {
+		_, err = export.ContentType(format)
+	}
+	if err != nil {
+		res.Status = http.StatusBadRequest
+		res.Error = err.Error()
+		res.Message = "Validation Error"
+		res.Data = nil
+		return res, err
+	}
+
+	cursor, err := s.userRepository.FindUsers(ctx, filter.StartIndex, filter.RecordPerPage)
+	if err != nil {
+		res.Error = err.Error()
+		res.Message = "error occured while exporting user items"
+		res.Data = nil
+		res.Status, err = database.ClassifyError(err, res.Message)
+		return res, err
+	}
+	defer cursor.Close(ctx)
+
+	rows, err := export.NewRowWriter(format, writer, columns)
+	if err != nil {
+		res.Error = err.Error()
+		res.Message = "error occured while exporting user items"
+		res.Data = nil
+		res.Status, err = database.ClassifyError(err, res.Message)
+		return res, err
+	}
+
+	count := 0
+	for cursor.Next(ctx) {
+		var user models.User
+		if err = cursor.Decode(&user); err == nil {
+			err = rows.WriteRow(userExportValues(user, columns))
+		}
+		if err != nil {
+			break
+		}
+		count++
+	}
+	if err == nil {
+		err = cursor.Err()
+	}
+	if closeErr := rows.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		res.Status = http.StatusInternalServerError
+		res.Error = err.Error()
+		res.Message = "Export was interrupted"
+		res.Data = count
+		return res, err
+	}
+
+	res.Status = http.StatusOK
+	res.Error = "NA"
+	res.Message = "Export completed"
+	res.Data = count
+	return res, nil
+}
+
+// StreamUsers hands every user to send in _id order, reading them from a
+// single cursor, and stops at the first error send returns. Data is the
+// number of users sent.
+func (s *userService) StreamUsers(ctx context.Context, send func(user models.User) error) (response interfaces.Response, err error) {
+	ctx, cancel := s.timeouts.Context(ctx, "StreamUsers")
+	defer cancel()
+
+	var res interfaces.Response
+
+	cursor, err := s.userRepository.FindUsers(ctx, 0, 0)
+	if err != nil {
+		res.Error = err.Error()
+		res.Message = "error occured while streaming user items"
+		res.Data = nil
+		res.Status, err = database.ClassifyError(err, res.Message)
+		return res, err
+	}
+	defer cursor.Close(ctx)
+
+	count := 0
+	for cursor.Next(ctx) {
+		var user models.User
+		if err = cursor.Decode(&user); err == nil {
+			err = send(user)
+		}
+		if err != nil {
+			break
+		}
+		count++
+	}
+	if err == nil {
+		err = cursor.Err()
+	}
+	if err != nil {
+		res.Error = err.Error()
+		res.Message = "Stream was interrupted"
+		res.Data = count
+		res.Status, err = database.ClassifyError(err, res.Message)
+		return res, err
+	}
+
+	res.Status = http.StatusOK
+	res.Error = "NA"
+	res.Message = "Stream completed"
+	res.Data = count
+	return res, nil
+}
+
+func userExportValues(user models.User, columns []string) []string {
+	values := make([]string, len(columns))
+	for i, column := range columns {
+		switch column {
+		case "user_id":
+			values[i] = user.User_id
+		case "first_name":
+			values[i] = stringValue(user.First_name)
+		case "last_name":
+			values[i] = stringValue(user.Last_name)
+		case "email":
+			values[i] = stringValue(user.Email)
+		case "phone":
+			values[i] = stringValue(user.Phone)
+		case "created_at":
+			values[i] = user.Created_at.UTC().Format(time.RFC3339)
+		case "updated_at":
+			values[i] = user.Updated_at.UTC().Format(time.RFC3339)
+		}
+	}
+	return values
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
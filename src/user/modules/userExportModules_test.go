@@ -0,0 +1,60 @@
+package modules
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"somdeep-demo-app/src/timeouts"
+	"somdeep-demo-app/src/user/models"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// cursorUserRepository serves FindUsers from memory and counts the queries.
+type cursorUserRepository struct {
+	stubUserRepository
+	users   []models.User
+	queries int
+}
+
+func (r *cursorUserRepository) FindUsers(ctx context.Context, startIndex int, recordPerPage int) (*mongo.Cursor, error) {
+	r.queries++
+	documents := make([]interface{}, len(r.users))
+	for i, user := range r.users {
+		documents[i] = user
+	}
+	return mongo.NewCursorFromDocuments(documents, nil, nil)
+}
+
+func TestStreamUsersReadsOneCursor(t *testing.T) {
+	repository := &cursorUserRepository{}
+	for _, userId := range []string{"u-1", "u-2", "u-3"} {
+		repository.users = append(repository.users, models.User{User_id: userId})
+	}
+	service := NewUserService(repository, &stubAuditService{}, &stubPublisher{}, stubTransactor{}, timeouts.Defaults())
+
+	var sent []string
+	response, err := service.StreamUsers(context.Background(), func(user models.User) error {
+		sent = append(sent, user.User_id)
+		return nil
+	})
+	if err != nil || response.Status != http.StatusOK || response.Data != 3 {
+		t.Fatalf("response = %+v, error = %v, want 3 users sent", response, err)
+	}
+	if len(sent) != 3 || sent[0] != "u-1" || sent[2] != "u-3" || repository.queries != 1 {
+		t.Errorf("sent %v in %d queries, want every user in one", sent, repository.queries)
+	}
+
+	// a failed send ends the stream
+	sendErr := errors.New("client went away")
+	sent = nil
+	response, err = service.StreamUsers(context.Background(), func(user models.User) error {
+		sent = append(sent, user.User_id)
+		return sendErr
+	})
+	if err == nil || response.Data != 0 || len(sent) != 1 {
+		t.Errorf("response = %+v, error = %v after sending %v, want the stream to stop at the first send", response, err, sent)
+	}
+}
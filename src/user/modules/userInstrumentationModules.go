@@ -0,0 +1,80 @@
+package modules
+
+import (
+	"context"
+	"io"
+	"time"
+
+	auditModels "somdeep-demo-app/src/audit/models"
+	"somdeep-demo-app/src/metrics"
+	"somdeep-demo-app/src/tracing"
+	"somdeep-demo-app/src/user/interfaces"
+	"somdeep-demo-app/src/user/models"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentedUserService times and traces every UserService method and
+// counts the users created. The span it starts is the parent of the
+// repository's Mongo command spans.
+type instrumentedUserService struct {
+	userService interfaces.UserService
+}
+
+func NewInstrumentedUserService(userService interfaces.UserService) interfaces.UserService {
+	return &instrumentedUserService{
+		userService: userService,
+	}
+}
+
+func observeUserService(span trace.Span, method string, start time.Time, response *interfaces.Response, err *error) {
+	metrics.ObserveService("users", method, start, response.Status, *err)
+	tracing.End(span, response.Status, *err)
+}
+
+func (s *instrumentedUserService) GetUsers(ctx context.Context, recordPerPage int, page int, startIndex int) (response interfaces.Response, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetUsers")
+	defer observeUserService(span, "GetUsers", time.Now(), &response, &err)
+	return s.userService.GetUsers(ctx, recordPerPage, page, startIndex)
+}
+
+func (s *instrumentedUserService) GetUser(ctx context.Context, userId string) (response interfaces.Response, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetUser", attribute.String("app.user_id", userId))
+	defer observeUserService(span, "GetUser", time.Now(), &response, &err)
+	return s.userService.GetUser(ctx, userId)
+}
+
+func (s *instrumentedUserService) AddUser(ctx context.Context, actor auditModels.Actor, user models.User) (response interfaces.Response, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.AddUser")
+	defer observeUserService(span, "AddUser", time.Now(), &response, &err)
+	response, err = s.userService.AddUser(ctx, actor, user)
+	if err == nil {
+		metrics.UsersCreated.Inc()
+	}
+	return response, err
+}
+
+func (s *instrumentedUserService) UpdateUser(ctx context.Context, actor auditModels.Actor, userId string, user models.User) (response interfaces.Response, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.UpdateUser", attribute.String("app.user_id", userId))
+	defer observeUserService(span, "UpdateUser", time.Now(), &response, &err)
+	return s.userService.UpdateUser(ctx, actor, userId, user)
+}
+
+func (s *instrumentedUserService) DeleteUser(ctx context.Context, actor auditModels.Actor, userId string) (response interfaces.Response, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.DeleteUser", attribute.String("app.user_id", userId))
+	defer observeUserService(span, "DeleteUser", time.Now(), &response, &err)
+	return s.userService.DeleteUser(ctx, actor, userId)
+}
+
+func (s *instrumentedUserService) ExportUsers(ctx context.Context, filter models.UserExportFilter, format string, writer io.Writer) (response interfaces.Response, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.ExportUsers", attribute.String("app.format", format))
+	defer observeUserService(span, "ExportUsers", time.Now(), &response, &err)
+	return s.userService.ExportUsers(ctx, filter, format, writer)
+}
+
+func (s *instrumentedUserService) StreamUsers(ctx context.Context, send func(user models.User) error) (response interfaces.Response, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.StreamUsers")
+	defer observeUserService(span, "StreamUsers", time.Now(), &response, &err)
+	return s.userService.StreamUsers(ctx, send)
+}
@@ -2,8 +2,14 @@ package modules
 
 import (
 	"context"
-	"log"
+	"errors"
 	"net/http"
+	"somdeep-demo-app/src/apperrors"
+	auditInterfaces "somdeep-demo-app/src/audit/interfaces"
+	auditModels "somdeep-demo-app/src/audit/models"
+	"somdeep-demo-app/src/database"
+	eventInterfaces "somdeep-demo-app/src/events/interfaces"
+	"somdeep-demo-app/src/timeouts"
 	"somdeep-demo-app/src/user/interfaces"
 	"somdeep-demo-app/src/user/models"
 	"time"
@@ -12,6 +18,7 @@ import (
 	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -20,16 +27,27 @@ var validate = validator.New()
 
 type userService struct {
 	userRepository interfaces.UserRepository
+	auditService   auditInterfaces.AuditService
+	eventPublisher eventInterfaces.EventPublisher
+	transactor     database.Transactor
+	timeouts       timeouts.Timeouts
 }
 
-func NewUserService(userRepository interfaces.UserRepository) interfaces.UserService {
+// NewUserService publishes events with the transaction context of the change
+// they describe, so eventPublisher should be the outbox publisher. Every
+// method runs under the caller's context, further bounded by timeouts.
+func NewUserService(userRepository interfaces.UserRepository, auditService auditInterfaces.AuditService, eventPublisher eventInterfaces.EventPublisher, transactor database.Transactor, timeouts timeouts.Timeouts) interfaces.UserService {
 	return &userService{
 		userRepository: userRepository,
+		auditService:   auditService,
+		eventPublisher: eventPublisher,
+		transactor:     transactor,
+		timeouts:       timeouts,
 	}
 }
 
-func (s *userService) GetUsers(recordPerPage int, page int, startIndex int) (response interfaces.Response, err error) {
-	var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+func (s *userService) GetUsers(ctx context.Context, recordPerPage int, page int, startIndex int) (response interfaces.Response, err error) {
+	ctx, cancel := s.timeouts.Context(ctx, "GetUsers")
 
 	var res interfaces.Response
 
@@ -37,22 +55,27 @@ func (s *userService) GetUsers(recordPerPage int, page int, startIndex int) (res
 	defer cancel()
 	if err != nil {
 		// c.JSON(http.StatusInternalServerError, gin.H{"error": "error occured while listing user items"})
-		res.Status = http.StatusInternalServerError
 		res.Error = err.Error()
 		res.Message = "error occured while listing user items"
 		res.Data = nil
+		res.Status, err = database.ClassifyError(err, res.Message)
 		return res, err
 	}
 	var allusers []bson.M
 	if err = result.All(ctx, &allusers); err != nil {
-		log.Fatal(err)
+		res.Error = err.Error()
+		res.Message = "error occured while listing user items"
+		res.Data = nil
+		res.Status, err = database.ClassifyError(err, res.Message)
+		return res, err
 	}
 
 	if len(allusers) == 0 {
-		res.Status = http.StatusInternalServerError
+		// the aggregation has nothing to group, answer with an empty page
+		res.Status = http.StatusOK
 		res.Error = "NA"
 		res.Message = "No Records Found"
-		res.Data = nil
+		res.Data = []bson.M{{"total_count": 0, "items": []bson.M{}}}
 		return res, err
 	}
 
@@ -64,8 +87,8 @@ func (s *userService) GetUsers(recordPerPage int, page int, startIndex int) (res
 	return res, err
 }
 
-func (s *userService) GetUser(userId string) (response interfaces.Response, err error) {
-	var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+func (s *userService) GetUser(ctx context.Context, userId string) (response interfaces.Response, err error) {
+	ctx, cancel := s.timeouts.Context(ctx, "GetUser")
 	defer cancel()
 
 	var res interfaces.Response
@@ -74,10 +97,10 @@ func (s *userService) GetUser(userId string) (response interfaces.Response, err
 	user, err = s.userRepository.GetUserByUserId(ctx, userId)
 	if err != nil {
 		// c.JSON(http.StatusInternalServerError, gin.H{"message": "Error occured while fetching documents", "error": err.Error()})
-		res.Status = http.StatusInternalServerError
 		res.Error = err.Error()
 		res.Message = "Error occured while listing user items"
 		res.Data = nil
+		res.Status, err = database.ClassifyError(err, "User not found")
 		return res, err
 	}
 	// c.JSON(http.StatusOK, user)
@@ -88,8 +111,8 @@ func (s *userService) GetUser(userId string) (response interfaces.Response, err
 	return res, err
 }
 
-func (s *userService) AddUser(user models.User) (response interfaces.Response, err error) {
-	var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+func (s *userService) AddUser(ctx context.Context, actor auditModels.Actor, user models.User) (response interfaces.Response, err error) {
+	ctx, cancel := s.timeouts.Context(ctx, "AddUser")
 	defer cancel()
 
 	var res interfaces.Response
@@ -101,53 +124,67 @@ func (s *userService) AddUser(user models.User) (response interfaces.Response, e
 	if validationError != nil {
 		// c.JSON(http.StatusBadRequest, gin.H{"error": validationError.Error()})
 		res.Status = http.StatusBadRequest
-		res.Error = err.Error()
+		res.Error = validationError.Error()
 		res.Message = "Validation Error"
 		res.Data = nil
-		return res, err
+		return res, apperrors.FromValidator(res.Message, validationError, user)
 	}
 
 	// we will check whether the "email" has already been used by another user or not
 
-	count, err := s.userRepository.CountDocumentBasedOnKey(ctx, user, "email")
+	emailCount, err := s.userRepository.CountDocumentBasedOnKey(ctx, user, "email")
 
 	if err != nil {
-		log.Panic(err)
 		// c.JSON(http.StatusInternalServerError, gin.H{"error": "error occured while checking for the mail"})
-		res.Status = http.StatusInternalServerError
 		res.Error = err.Error()
 		res.Message = "Error occured while checking for e-mail"
 		res.Data = nil
+		res.Status, err = database.ClassifyError(err, res.Message)
 		return res, err
 	}
 
-	// hash the password - HashPassword()
-
-	password := HashPassword(*user.Password)
-	user.Password = &password
-
 	// we will check whether the "phone_number" has already been used by another user or not
 
-	count, err = s.userRepository.CountDocumentBasedOnKey(ctx, user, "phone")
+	phoneCount, err := s.userRepository.CountDocumentBasedOnKey(ctx, user, "phone")
 
 	if err != nil {
-		log.Panic(err)
 		// c.JSON(http.StatusInternalServerError, gin.H{"error": "error occured while checking for the phone number"})
-		res.Status = http.StatusInternalServerError
 		res.Error = err.Error()
 		res.Message = "Error occured while checking for phone number"
 		res.Data = nil
+		res.Status, err = database.ClassifyError(err, res.Message)
 		return res, err
 	}
 
-	if count > 0 {
+	if emailCount > 0 || phoneCount > 0 {
 		// c.JSON(http.StatusInternalServerError, gin.H{"error": "this email or phone number already exists"})
-		res.Status = http.StatusInternalServerError
+		res.Status = http.StatusConflict
 		res.Error = "NA"
 		res.Message = "User with this e-mail or phone already exists"
 		res.Data = nil
+		return res, apperrors.Conflict(res.Message)
+	}
+
+	// hash the password - HashPassword()
+
+	password, err := HashPassword(*user.Password)
+	if err != nil {
+		res.Error = err.Error()
+		res.Message = "Error occured while hashing the password"
+		res.Data = nil
+		res.Status = http.StatusInternalServerError
+		if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+			res.Status = http.StatusBadRequest
+			res.Message = "Validation Error"
+			err = apperrors.Validation(res.Message, apperrors.FieldError{
+				Field:   "Password",
+				Rule:    "max",
+				Message: "must be at most 72 bytes long",
+			})
+		}
 		return res, err
 	}
+	user.Password = &password
 
 	// create some extra details for the user object - basically fillers (created_at, updated_at and ID)
 
@@ -156,26 +193,33 @@ func (s *userService) AddUser(user models.User) (response interfaces.Response, e
 	user.ID = primitive.NewObjectID()
 	user.User_id = uuid.New().String()
 
-	insertErr := s.userRepository.AddUserToMongoDb(ctx, user)
+	change := userChange{Action: auditModels.ActionCreate, After: &user}
+	insertErr := s.transactor.WithTransaction(ctx, func(txCtx context.Context) error {
+		if err := s.userRepository.AddUserToMongoDb(txCtx, user); err != nil {
+			return err
+		}
+		return s.recordChange(txCtx, actor, change)
+	})
 	if insertErr != nil {
 		// msg := "User item was not created"
 		// c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
-		res.Status = http.StatusInternalServerError
 		res.Error = insertErr.Error()
 		res.Message = "User item was not created"
 		res.Data = nil
+		res.Status, err = database.ClassifyError(insertErr, res.Message)
 		return res, err
 	}
+
 	// c.JSON(http.StatusOK, gin.H{"message": "User added successfully", "userID": user.User_id})
 	res.Status = http.StatusOK
 	res.Error = "NA"
 	res.Message = "User Added Successfully"
-	res.Data = "user_id: " + user.User_id
+	res.Data = models.UserRef{User_id: user.User_id}
 	return res, err
 }
 
-func (s *userService) UpdateUser(userId string, user models.User) (response interfaces.Response, err error) {
-	var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+func (s *userService) UpdateUser(ctx context.Context, actor auditModels.Actor, userId string, user models.User) (response interfaces.Response, err error) {
+	ctx, cancel := s.timeouts.Context(ctx, "UpdateUser")
 	defer cancel()
 
 	var res interfaces.Response
@@ -200,26 +244,66 @@ func (s *userService) UpdateUser(userId string, user models.User) (response inte
 
 	filter := bson.M{"user_id": userId}
 
-	result, err := s.userRepository.UpdateOneUserByUserId(ctx, opt, filter, updateObject)
-
-	if result.ModifiedCount == 0 {
-		// c.JSON(http.StatusNotFound, gin.H{"message": "User not found or is already deleted"})
-		res.Status = http.StatusNotFound
-		res.Error = "NA"
-		res.Message = "User not found or is already deleted"
-		res.Data = nil
-		return res, err
-	}
+	var result *mongo.UpdateResult
+	var change *userChange
+	err = s.transactor.WithTransaction(ctx, func(txCtx context.Context) error {
+		change = nil
+		result = nil
+		before, beforeErr := s.userRepository.GetUserByUserId(txCtx, userId)
+		if beforeErr != nil {
+			return beforeErr
+		}
+		if !userChanged(before, user) {
+			return nil
+		}
+		var updateErr error
+		result, updateErr = s.userRepository.UpdateOneUserByUserId(txCtx, opt, filter, updateObject)
+		if updateErr != nil || result.ModifiedCount == 0 {
+			return updateErr
+		}
+
+		after := before
+		if user.First_name != nil {
+			after.First_name = user.First_name
+		}
+		if user.Last_name != nil {
+			after.Last_name = user.Last_name
+		}
+		after.Updated_at = user.Updated_at
+		change = &userChange{Action: auditModels.ActionUpdate, Before: &before, After: &after}
+		return s.recordChange(txCtx, actor, *change)
+	})
 
 	if err != nil {
 		// msg := "User update failed"
 		// c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
-		res.Status = http.StatusInternalServerError
 		res.Error = err.Error()
 		res.Message = "User update failed"
 		res.Data = nil
+		res.Status, err = database.ClassifyError(err, "User not found or is already deleted")
 		return res, err
 	}
+
+	if result != nil && result.MatchedCount == 0 {
+		// c.JSON(http.StatusNotFound, gin.H{"message": "User not found or is already deleted"})
+		res.Status = http.StatusNotFound
+		res.Error = "NA"
+		res.Message = "User not found or is already deleted"
+		res.Data = nil
+		return res, apperrors.NotFound(res.Message)
+	}
+
+	if change == nil {
+		// the user already has the requested names, so nothing was written
+		res.Status = http.StatusOK
+		res.Error = "NA"
+		res.Message = "User is already up to date"
+		res.Data = result
+		if result == nil {
+			res.Data = &mongo.UpdateResult{MatchedCount: 1}
+		}
+		return res, nil
+	}
 	// c.JSON(http.StatusOK, result)
 
 	res.Status = http.StatusOK
@@ -229,21 +313,35 @@ func (s *userService) UpdateUser(userId string, user models.User) (response inte
 	return res, err
 }
 
-func (s *userService) DeleteUser(userId string) (response interfaces.Response, err error) {
-	var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+func (s *userService) DeleteUser(ctx context.Context, actor auditModels.Actor, userId string) (response interfaces.Response, err error) {
+	ctx, cancel := s.timeouts.Context(ctx, "DeleteUser")
 	defer cancel()
 
 	var res interfaces.Response
 	filter := bson.M{"user_id": userId}
 
-	result, err := s.userRepository.DeleteOneUserByUserId(ctx, filter)
+	var result *mongo.DeleteResult
+	var change userChange
+	err = s.transactor.WithTransaction(ctx, func(txCtx context.Context) error {
+		before, beforeErr := s.userRepository.GetUserByUserId(txCtx, userId)
+		if beforeErr != nil {
+			before = models.User{User_id: userId}
+		}
+		var deleteErr error
+		result, deleteErr = s.userRepository.DeleteOneUserByUserId(txCtx, filter)
+		if deleteErr != nil || result.DeletedCount == 0 {
+			return deleteErr
+		}
+		change = userChange{Action: auditModels.ActionDelete, Before: &before}
+		return s.recordChange(txCtx, actor, change)
+	})
 	if err != nil {
 		// c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
-		res.Status = http.StatusInternalServerError
 		res.Error = err.Error()
 		res.Message = "Failed to delete user"
 		res.Data = nil
-		return res, nil
+		res.Status, err = database.ClassifyError(err, "User not found or is already deleted")
+		return res, err
 	}
 
 	if result.DeletedCount == 0 {
@@ -252,23 +350,35 @@ func (s *userService) DeleteUser(userId string) (response interfaces.Response, e
 		res.Error = "NA"
 		res.Message = "User not found or is already deleted"
 		res.Data = nil
-		return res, nil
+		return res, apperrors.NotFound(res.Message)
 	}
 
 	// c.JSON(http.StatusOK, gin.H{"message": "User deleted", "userId": userId})
 	res.Status = http.StatusOK
 	res.Error = "NA"
 	res.Message = "User deleted successfully"
-	res.Data = "user_id: " + userId
+	res.Data = models.UserRef{User_id: userId}
 	return res, nil
 }
 
-func HashPassword(password string) string {
+// userChanged reports whether applying the update to before changes any of
+// the user's names.
+func userChanged(before models.User, update models.User) bool {
+	if update.First_name != nil && (before.First_name == nil || *before.First_name != *update.First_name) {
+		return true
+	}
+	if update.Last_name != nil && (before.Last_name == nil || *before.Last_name != *update.Last_name) {
+		return true
+	}
+	return false
+}
+
+func HashPassword(password string) (string, error) {
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
 	if err != nil {
-		log.Panic(err)
+		return "", err
 	}
-	return string(bytes)
+	return string(bytes), nil
 }
 
 func VerifyPassword(userPassword string, providedPassword string) (bool, string) {
@@ -0,0 +1,102 @@
+package modules
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	auditInterfaces "somdeep-demo-app/src/audit/interfaces"
+	auditModels "somdeep-demo-app/src/audit/models"
+	eventModels "somdeep-demo-app/src/events/models"
+	"somdeep-demo-app/src/timeouts"
+	"somdeep-demo-app/src/user/interfaces"
+	"somdeep-demo-app/src/user/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type transactionKey struct{}
+
+// stubTransactor marks the context of the unit of work, so the stubs below
+// can tell whether they were called inside it.
+type stubTransactor struct{}
+
+func (stubTransactor) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(context.WithValue(ctx, transactionKey{}, true))
+}
+
+func (stubTransactor) Enabled() bool { return true }
+
+func inTransaction(ctx context.Context) bool {
+	return ctx.Value(transactionKey{}) != nil
+}
+
+type stubUserRepository struct {
+	interfaces.UserRepository
+}
+
+func (stubUserRepository) GetUserByUserId(ctx context.Context, userId string) (models.User, error) {
+	return models.User{User_id: userId}, nil
+}
+
+func (stubUserRepository) DeleteOneUserByUserId(ctx context.Context, filter primitive.M) (*mongo.DeleteResult, error) {
+	return &mongo.DeleteResult{DeletedCount: 1}, nil
+}
+
+type stubAuditService struct {
+	auditInterfaces.AuditService
+	err           error
+	entries       []auditModels.AuditEntry
+	inTransaction bool
+}
+
+func (s *stubAuditService) Record(ctx context.Context, actor auditModels.Actor, entries ...auditModels.AuditEntry) error {
+	s.entries = append(s.entries, entries...)
+	s.inTransaction = inTransaction(ctx)
+	return s.err
+}
+
+type stubPublisher struct {
+	events        []eventModels.Event
+	inTransaction bool
+}
+
+func (p *stubPublisher) Publish(ctx context.Context, events ...eventModels.Event) error {
+	p.events = append(p.events, events...)
+	p.inTransaction = inTransaction(ctx)
+	return nil
+}
+
+func (p *stubPublisher) Close() error { return nil }
+
+func TestDeleteUserRecordsTheAuditEntryInTheTransaction(t *testing.T) {
+	audit := &stubAuditService{}
+	publisher := &stubPublisher{}
+	service := NewUserService(stubUserRepository{}, audit, publisher, stubTransactor{}, timeouts.Defaults())
+
+	if _, err := service.DeleteUser(context.Background(), auditModels.Actor{ID: "admin"}, "u-1"); err != nil {
+		t.Fatal(err)
+	}
+	if len(audit.entries) != 1 || audit.entries[0].Entity_id != "u-1" || audit.entries[0].Action != auditModels.ActionDelete {
+		t.Errorf("audit entries = %+v, want one delete of u-1", audit.entries)
+	}
+	if !audit.inTransaction || !publisher.inTransaction {
+		t.Errorf("audit in transaction %v, event in transaction %v, want both", audit.inTransaction, publisher.inTransaction)
+	}
+}
+
+func TestDeleteUserFailsWhenTheAuditEntryIsNotWritten(t *testing.T) {
+	audit := &stubAuditService{err: errors.New("audit collection unavailable")}
+	publisher := &stubPublisher{}
+	service := NewUserService(stubUserRepository{}, audit, publisher, stubTransactor{}, timeouts.Defaults())
+
+	response, err := service.DeleteUser(context.Background(), auditModels.Actor{ID: "admin"}, "u-1")
+	if err == nil || response.Status != http.StatusInternalServerError {
+		t.Errorf("DeleteUser() = %d, %v, want the transaction to fail", response.Status, err)
+	}
+	if len(publisher.events) != 0 {
+		t.Errorf("published %d events for an aborted change", len(publisher.events))
+	}
+}
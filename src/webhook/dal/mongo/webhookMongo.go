@@ -0,0 +1,192 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"somdeep-demo-app/src/database"
+	"somdeep-demo-app/src/webhook/interfaces"
+	"somdeep-demo-app/src/webhook/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type webhookRepository struct {
+	webhookCollection  *database.Collection
+	deliveryCollection *database.Collection
+}
+
+func NewWebhookRepository(tenancy *database.Tenancy) interfaces.WebhookRepository {
+	webhookCollection := database.OpenTenantCollection(tenancy, "webhooks")
+	deliveryCollection := database.OpenTenantCollection(tenancy, "webhook_deliveries")
+	return &webhookRepository{
+		webhookCollection:  webhookCollection,
+		deliveryCollection: deliveryCollection,
+	}
+}
+
+func (r *webhookRepository) AddWebhook(ctx context.Context, webhook models.Webhook) error {
+	_, err := r.webhookCollection.InsertOne(ctx, webhook)
+	return err
+}
+
+func (r *webhookRepository) GetWebhooksByUserId(ctx context.Context, userId string) ([]models.Webhook, error) {
+	return r.findWebhooks(ctx, bson.M{"user_id": userId})
+}
+
+func (r *webhookRepository) GetActiveWebhooksByUserId(ctx context.Context, userId string) ([]models.Webhook, error) {
+	return r.findWebhooks(ctx, bson.M{"user_id": userId, "active": true})
+}
+
+func (r *webhookRepository) findWebhooks(ctx context.Context, filter bson.M) ([]models.Webhook, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	result, err := r.webhookCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	webhooks := []models.Webhook{}
+	if err = result.All(ctx, &webhooks); err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+func (r *webhookRepository) GetWebhookByWebhookId(ctx context.Context, userId string, webhookId string) (models.Webhook, error) {
+	var webhook models.Webhook
+	err := r.webhookCollection.FindOne(ctx, bson.M{"webhook_id": webhookId, "user_id": userId}).Decode(&webhook)
+	return webhook, err
+}
+
+func (r *webhookRepository) UpdateWebhookByWebhookId(ctx context.Context, userId string, webhookId string, updateObject primitive.D) (*mongo.UpdateResult, error) {
+	filter := bson.M{"webhook_id": webhookId, "user_id": userId}
+	return r.webhookCollection.UpdateOne(ctx, filter, bson.D{{Key: "$set", Value: updateObject}})
+}
+
+func (r *webhookRepository) DeleteWebhookByWebhookId(ctx context.Context, userId string, webhookId string) (*mongo.DeleteResult, error) {
+	return r.webhookCollection.DeleteOne(ctx, bson.M{"webhook_id": webhookId, "user_id": userId})
+}
+
+func (r *webhookRepository) AddDeliveries(ctx context.Context, deliveries []models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	documents := make([]interface{}, len(deliveries))
+	for i, delivery := range deliveries {
+		documents[i] = delivery
+	}
+	_, err := r.deliveryCollection.InsertMany(ctx, documents, options.InsertMany().SetOrdered(false))
+	if onlyDuplicates(err) {
+		return nil
+	}
+	return err
+}
+
+// onlyDuplicates reports whether every write error is a duplicate key error.
+func onlyDuplicates(err error) bool {
+	var bulkErr mongo.BulkWriteException
+	if !errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil || len(bulkErr.WriteErrors) == 0 {
+		return false
+	}
+	for _, writeErr := range bulkErr.WriteErrors {
+		if writeErr.Code != 11000 {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *webhookRepository) ClaimDueDelivery(ctx context.Context, now time.Time, lease time.Duration) (*models.WebhookDelivery, error) {
+	filter := bson.M{"status": models.DeliveryPending, "next_attempt_at": bson.M{"$lte": now}}
+	update := bson.M{"$set": bson.M{"next_attempt_at": now.Add(lease)}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "next_attempt_at", Value: 1}, {Key: "_id", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var delivery models.WebhookDelivery
+	err := r.deliveryCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&delivery)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func (r *webhookRepository) RecordAttempt(ctx context.Context, delivery models.WebhookDelivery, attempt models.WebhookAttempt, status string, nextAttemptAt time.Time) error {
+	set := bson.M{
+		"status":           status,
+		"last_status_code": attempt.Status_code,
+		"last_error":       attempt.Error,
+		"next_attempt_at":  nextAttemptAt,
+	}
+	if status == models.DeliverySucceeded {
+		set["delivered_at"] = attempt.Attempted_at
+	}
+	_, err := r.deliveryCollection.UpdateByID(ctx, delivery.ID, bson.M{
+		"$set": set,
+		"$inc": bson.M{"attempts": 1},
+		"$push": bson.M{"log": bson.M{
+			"$each":  []models.WebhookAttempt{attempt},
+			"$slice": -models.MaxDeliveryLog,
+		}},
+	})
+	return err
+}
+
+func (r *webhookRepository) GetDeliveries(ctx context.Context, webhookId string, status string, startIndex int, recordPerPage int) (*mongo.Cursor, error) {
+	query := bson.M{"webhook_id": webhookId}
+	if status != "" {
+		query["status"] = status
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(int64(startIndex)).
+		SetLimit(int64(recordPerPage))
+	return r.deliveryCollection.Find(ctx, query, opts)
+}
+
+func (r *webhookRepository) GetDeliveryByDeliveryId(ctx context.Context, webhookId string, deliveryId string) (models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	err := r.deliveryCollection.FindOne(ctx, bson.M{"delivery_id": deliveryId, "webhook_id": webhookId}).Decode(&delivery)
+	return delivery, err
+}
+
+// RequeueDelivery makes a delivery due now with a fresh retry budget, whatever
+// its current status. The log of earlier attempts is kept.
+func (r *webhookRepository) RequeueDelivery(ctx context.Context, webhookId string, deliveryId string, now time.Time) (bool, error) {
+	result, err := r.deliveryCollection.UpdateOne(ctx,
+		bson.M{"delivery_id": deliveryId, "webhook_id": webhookId},
+		bson.M{
+			"$set":   bson.M{"status": models.DeliveryPending, "attempts": 0, "next_attempt_at": now},
+			"$unset": bson.M{"delivered_at": ""},
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// EnsureIndexes creates the lookup indexes, the unique index that makes fan-out
+// idempotent and a TTL index that expires delivery logs after the retention period.
+func (r *webhookRepository) EnsureIndexes(ctx context.Context, deliveryRetention time.Duration) error {
+	_, err := r.webhookCollection.CreateIndexes(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "webhook_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "active", Value: 1}}},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = r.deliveryCollection.CreateIndexes(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "delivery_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}}},
+		{Keys: bson.D{{Key: "webhook_id", Value: 1}, {Key: "event.id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "webhook_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "created_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(int32(deliveryRetention.Seconds()))},
+	})
+	return err
+}
@@ -0,0 +1,29 @@
+package interfaces
+
+import (
+	"context"
+	"somdeep-demo-app/src/webhook/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type WebhookRepository interface {
+	AddWebhook(ctx context.Context, webhook models.Webhook) error
+	GetWebhooksByUserId(ctx context.Context, userId string) ([]models.Webhook, error)
+	GetActiveWebhooksByUserId(ctx context.Context, userId string) ([]models.Webhook, error)
+	GetWebhookByWebhookId(ctx context.Context, userId string, webhookId string) (models.Webhook, error)
+	UpdateWebhookByWebhookId(ctx context.Context, userId string, webhookId string, updateObject primitive.D) (*mongo.UpdateResult, error)
+	DeleteWebhookByWebhookId(ctx context.Context, userId string, webhookId string) (*mongo.DeleteResult, error)
+
+	// AddDeliveries ignores deliveries that already exist for the same webhook
+	// and event, so fanning the same event out twice is harmless.
+	AddDeliveries(ctx context.Context, deliveries []models.WebhookDelivery) error
+	ClaimDueDelivery(ctx context.Context, now time.Time, lease time.Duration) (*models.WebhookDelivery, error)
+	RecordAttempt(ctx context.Context, delivery models.WebhookDelivery, attempt models.WebhookAttempt, status string, nextAttemptAt time.Time) error
+	GetDeliveries(ctx context.Context, webhookId string, status string, startIndex int, recordPerPage int) (*mongo.Cursor, error)
+	GetDeliveryByDeliveryId(ctx context.Context, webhookId string, deliveryId string) (models.WebhookDelivery, error)
+	RequeueDelivery(ctx context.Context, webhookId string, deliveryId string, now time.Time) (bool, error)
+	EnsureIndexes(ctx context.Context, deliveryRetention time.Duration) error
+}
@@ -0,0 +1,25 @@
+package interfaces
+
+import (
+	"context"
+	"somdeep-demo-app/src/webhook/models"
+)
+
+type Response struct {
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+	Error   string `json:"error"`
+	Data    any    `json:"data"`
+}
+
+type WebhookService interface {
+	AddWebhook(ctx context.Context, userId string, webhook models.Webhook) (response Response, err error)
+	GetWebhooks(ctx context.Context, userId string) (response Response, err error)
+	GetWebhook(ctx context.Context, userId string, webhookId string) (response Response, err error)
+	UpdateWebhook(ctx context.Context, userId string, webhookId string, webhook models.Webhook) (response Response, err error)
+	DeleteWebhook(ctx context.Context, userId string, webhookId string) (response Response, err error)
+	PingWebhook(ctx context.Context, userId string, webhookId string) (response Response, err error)
+	GetDeliveries(ctx context.Context, userId string, webhookId string, status string, recordPerPage int, page int, startIndex int) (response Response, err error)
+	GetDelivery(ctx context.Context, userId string, webhookId string, deliveryId string) (response Response, err error)
+	Redeliver(ctx context.Context, userId string, webhookId string, deliveryId string) (response Response, err error)
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	eventModels "somdeep-demo-app/src/events/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryDead      = "dead"
+)
+
+// WebhookPing is sent by the ping endpoint regardless of the event filter.
+const WebhookPing = "webhook.ping"
+
+// MaxDeliveryLog is how many attempts are kept on a delivery.
+const MaxDeliveryLog = 50
+
+type Webhook struct {
+	ID          primitive.ObjectID `bson:"_id"`
+	Webhook_id  string             `json:"webhook_id"`
+	User_id     string             `json:"user_id"`
+	Url         *string            `json:"url" validate:"required,url,startswith=http,max=2048"`
+	Event_types []string           `json:"event_types" validate:"omitempty,dive,required,max=100"`
+	Secret      *string            `json:"secret,omitempty" validate:"omitempty,min=16,max=256"`
+	Active      *bool              `json:"active"`
+	Created_at  time.Time          `json:"created_at"`
+	Updated_at  time.Time          `json:"updated_at"`
+}
+
+// Subscribes reports whether the webhook wants events of the given type. An
+// empty filter subscribes to everything, "*" matches any type and "customer.*"
+// matches every customer event.
+func (w Webhook) Subscribes(eventType string) bool {
+	if len(w.Event_types) == 0 {
+		return true
+	}
+	for _, pattern := range w.Event_types {
+		if pattern == "*" || pattern == eventType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok && strings.HasPrefix(eventType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+type WebhookDelivery struct {
+	ID               primitive.ObjectID `bson:"_id"`
+	Delivery_id      string             `json:"delivery_id"`
+	Webhook_id       string             `json:"webhook_id"`
+	User_id          string             `json:"user_id"`
+	Event            eventModels.Event  `json:"event"`
+	Status           string             `json:"status"`
+	Attempts         int                `json:"attempts"`
+	Last_status_code int                `json:"last_status_code,omitempty"`
+	Last_error       string             `json:"last_error,omitempty"`
+	Created_at       time.Time          `json:"created_at"`
+	Next_attempt_at  time.Time          `json:"next_attempt_at"`
+	Delivered_at     *time.Time         `json:"delivered_at,omitempty"`
+	Log              []WebhookAttempt   `json:"log"`
+}
+
+// WebhookRef identifies the webhook a delete applied to.
+type WebhookRef struct {
+	Webhook_id string `json:"webhook_id"`
+}
+
+// DeliveryRef identifies the delivery a ping or redelivery queued.
+type DeliveryRef struct {
+	Delivery_id string `json:"delivery_id"`
+	Webhook_id  string `json:"webhook_id"`
+}
+
+// WebhookAttempt is one entry in the delivery log.
+type WebhookAttempt struct {
+	Attempt      int       `json:"attempt"`
+	Url          string    `json:"url"`
+	Status_code  int       `json:"status_code,omitempty"`
+	Error        string    `json:"error,omitempty"`
+	Duration_ms  int64     `json:"duration_ms"`
+	Attempted_at time.Time `json:"attempted_at"`
+}
@@ -0,0 +1,249 @@
+package modules
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"somdeep-demo-app/src/backoff"
+	"somdeep-demo-app/src/logging"
+	"somdeep-demo-app/src/tenant"
+	"somdeep-demo-app/src/tracing"
+	"somdeep-demo-app/src/webhook/interfaces"
+	"somdeep-demo-app/src/webhook/models"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// maxResponseBody is how much of a receiver's response is drained, so the
+// connection can be reused. Bodies are not kept, since they could carry
+// whatever a receiver chooses to echo back.
+const maxResponseBody = 64 << 10
+
+const userAgent = "somdeep-demo-app-webhooks/1"
+
+type DispatcherOptions struct {
+	PollInterval time.Duration
+	BatchSize    int
+	// Lease is how long a claimed delivery is hidden from other dispatchers.
+	// It must be longer than Timeout.
+	Lease       time.Duration
+	Timeout     time.Duration
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// Client sends the requests. The default one refuses internal addresses
+	// and redirects, traces each request and sends the W3C traceparent.
+	Client *http.Client
+	// Tenants lists the tenants whose deliveries are sent. Nil means tenancy
+	// is off.
+	Tenants tenant.Lister
+}
+
+func DefaultDispatcherOptions() DispatcherOptions {
+	return DispatcherOptions{
+		PollInterval: time.Second,
+		BatchSize:    50,
+		Lease:        time.Minute,
+		Timeout:      10 * time.Second,
+		MaxAttempts:  8,
+		BaseBackoff:  10 * time.Second,
+		MaxBackoff:   time.Hour,
+		Client:       egressClient(publicAddress),
+	}
+}
+
+// Dispatcher sends pending webhook deliveries, retrying failures with
+// exponential backoff until MaxAttempts, after which a delivery is dead and
+// only comes back through a manual redelivery.
+type Dispatcher struct {
+	webhookRepository interfaces.WebhookRepository
+	options           DispatcherOptions
+
+	wake chan struct{}
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+func NewDispatcher(webhookRepository interfaces.WebhookRepository, options DispatcherOptions) *Dispatcher {
+	if options.Client == nil {
+		options.Client = egressClient(publicAddress)
+	}
+	return &Dispatcher{
+		webhookRepository: webhookRepository,
+		options:           options,
+		wake:              make(chan struct{}, 1),
+		stop:              make(chan struct{}),
+		done:              make(chan struct{}),
+	}
+}
+
+// Start runs the dispatch loop in the background until Stop is called.
+func (d *Dispatcher) Start() {
+	go d.run()
+}
+
+// Stop lets the current batch finish and returns once the loop has exited or
+// ctx expires. Deliveries that are still pending are picked up after a restart.
+func (d *Dispatcher) Stop(ctx context.Context) error {
+	d.once.Do(func() { close(d.stop) })
+	select {
+	case <-d.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Notify asks the dispatcher to poll now instead of waiting for the next tick.
+func (d *Dispatcher) Notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (d *Dispatcher) run() {
+	defer close(d.done)
+	ticker := time.NewTicker(d.options.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+		for _, ctx := range tenant.Contexts(context.Background(), d.options.Tenants) {
+			for {
+				dispatched, err := d.DispatchBatch(ctx)
+				if err != nil {
+					slog.ErrorContext(ctx, "webhooks: dispatch failed", "error", err)
+					break
+				}
+				if dispatched < d.options.BatchSize {
+					break
+				}
+			}
+		}
+	}
+}
+
+// DispatchBatch claims and sends up to BatchSize due deliveries and returns how
+// many it handled.
+func (d *Dispatcher) DispatchBatch(ctx context.Context) (int, error) {
+	handled := 0
+	for handled < d.options.BatchSize {
+		delivery, err := d.webhookRepository.ClaimDueDelivery(ctx, time.Now(), d.options.Lease)
+		if err != nil {
+			return handled, err
+		}
+		if delivery == nil {
+			return handled, nil
+		}
+		handled++
+		if err = d.dispatch(ctx, *delivery); err != nil {
+			return handled, err
+		}
+	}
+	return handled, nil
+}
+
+func (d *Dispatcher) dispatch(ctx context.Context, delivery models.WebhookDelivery) error {
+	// the delivery carries on the request and trace that caused the event
+	ctx = tracing.Extract(logging.WithRequestId(ctx, delivery.Event.Request_id), delivery.Event.Traceparent, delivery.Event.Tracestate)
+	attempt := models.WebhookAttempt{
+		Attempt:      delivery.Attempts + 1,
+		Attempted_at: time.Now(),
+	}
+
+	webhook, err := d.webhookRepository.GetWebhookByWebhookId(ctx, delivery.User_id, delivery.Webhook_id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		attempt.Error = "webhook was deleted"
+		return d.webhookRepository.RecordAttempt(ctx, delivery, attempt, models.DeliveryDead, attempt.Attempted_at)
+	}
+	if err != nil {
+		return err
+	}
+	if webhook.Active != nil && !*webhook.Active && delivery.Event.Type != models.WebhookPing {
+		attempt.Error = "webhook is disabled"
+		return d.webhookRepository.RecordAttempt(ctx, delivery, attempt, models.DeliveryDead, attempt.Attempted_at)
+	}
+
+	d.send(ctx, webhook, delivery, &attempt)
+
+	if attempt.Error == "" {
+		return d.webhookRepository.RecordAttempt(ctx, delivery, attempt, models.DeliverySucceeded, attempt.Attempted_at)
+	}
+	status := models.DeliveryPending
+	if attempt.Attempt >= d.options.MaxAttempts {
+		status = models.DeliveryDead
+		slog.WarnContext(ctx, "webhooks: giving up on delivery",
+			"delivery_id", delivery.Delivery_id,
+			"webhook_id", webhook.Webhook_id,
+			"attempts", attempt.Attempt,
+			"error", attempt.Error)
+	}
+	nextAttempt := time.Now().Add(backoff.Exponential(d.options.BaseBackoff, d.options.MaxBackoff, delivery.Attempts))
+	return d.webhookRepository.RecordAttempt(ctx, delivery, attempt, status, nextAttempt)
+}
+
+// send posts the event and fills in the outcome on attempt. Any response other
+// than a 2xx counts as a failure.
+func (d *Dispatcher) send(ctx context.Context, webhook models.Webhook, delivery models.WebhookDelivery, attempt *models.WebhookAttempt) {
+	if webhook.Url != nil {
+		attempt.Url = *webhook.Url
+	}
+	body, err := json.Marshal(delivery.Event)
+	if err != nil {
+		attempt.Error = err.Error()
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, d.options.Timeout)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, attempt.Url, bytes.NewReader(body))
+	if err != nil {
+		attempt.Error = err.Error()
+		return
+	}
+
+	timestamp := strconv.FormatInt(attempt.Attempted_at.Unix(), 10)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", userAgent)
+	request.Header.Set(HeaderWebhookId, webhook.Webhook_id)
+	request.Header.Set(HeaderDelivery, delivery.Delivery_id)
+	request.Header.Set(HeaderEvent, delivery.Event.Type)
+	request.Header.Set(HeaderTimestamp, timestamp)
+	if webhook.Secret != nil {
+		request.Header.Set(HeaderSignature, Sign(*webhook.Secret, timestamp, body))
+	}
+
+	started := time.Now()
+	response, err := d.options.Client.Do(request)
+	attempt.Duration_ms = time.Since(started).Milliseconds()
+	if err != nil {
+		attempt.Error = err.Error()
+		return
+	}
+	defer response.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, maxResponseBody))
+	attempt.Status_code = response.StatusCode
+	switch {
+	case response.StatusCode >= 300 && response.StatusCode <= 399:
+		attempt.Error = errRedirect.Error()
+	case response.StatusCode < 200 || response.StatusCode > 299:
+		attempt.Error = fmt.Sprintf("receiver responded with %d", response.StatusCode)
+	}
+}
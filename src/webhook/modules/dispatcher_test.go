@@ -0,0 +1,154 @@
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	eventModels "somdeep-demo-app/src/events/models"
+	"somdeep-demo-app/src/webhook/interfaces"
+	"somdeep-demo-app/src/webhook/models"
+)
+
+// deliveryRepository holds one webhook and hands out its deliveries once,
+// recording the attempts made on them.
+type deliveryRepository struct {
+	interfaces.WebhookRepository
+	webhook    models.Webhook
+	deliveries []models.WebhookDelivery
+	attempts   []models.WebhookAttempt
+	statuses   []string
+}
+
+func (r *deliveryRepository) ClaimDueDelivery(ctx context.Context, now time.Time, lease time.Duration) (*models.WebhookDelivery, error) {
+	if len(r.deliveries) == 0 {
+		return nil, nil
+	}
+	delivery := r.deliveries[0]
+	r.deliveries = r.deliveries[1:]
+	return &delivery, nil
+}
+
+func (r *deliveryRepository) GetWebhookByWebhookId(ctx context.Context, userId string, webhookId string) (models.Webhook, error) {
+	return r.webhook, nil
+}
+
+func (r *deliveryRepository) RecordAttempt(ctx context.Context, delivery models.WebhookDelivery, attempt models.WebhookAttempt, status string, nextAttemptAt time.Time) error {
+	r.attempts = append(r.attempts, attempt)
+	r.statuses = append(r.statuses, status)
+	return nil
+}
+
+// dispatchOnce sends a single delivery of a webhook pointing at url through
+// client and returns the attempt and status recorded for it.
+func dispatchOnce(t *testing.T, url string, client *http.Client) (models.WebhookAttempt, string) {
+	t.Helper()
+	secret := "0123456789abcdef"
+	repository := &deliveryRepository{
+		webhook: models.Webhook{Webhook_id: "wh-1", User_id: "u-1", Url: &url, Secret: &secret},
+		deliveries: []models.WebhookDelivery{{
+			Delivery_id: "d-1",
+			Webhook_id:  "wh-1",
+			User_id:     "u-1",
+			Event:       eventModels.Event{ID: "e-1", Type: "user.created", Data: json.RawMessage(`{}`)},
+		}},
+	}
+	options := DefaultDispatcherOptions()
+	options.Client = client
+	if _, err := NewDispatcher(repository, options).DispatchBatch(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(repository.attempts) != 1 {
+		t.Fatalf("recorded %d attempts, want 1", len(repository.attempts))
+	}
+	return repository.attempts[0], repository.statuses[0]
+}
+
+func allowAll(net.IP) bool { return true }
+
+func TestDispatcherDelivers(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := VerifySignature("0123456789abcdef", r.Header.Get(HeaderTimestamp), body, r.Header.Get(HeaderSignature), time.Minute); err != nil {
+			t.Errorf("signature: %v", err)
+		}
+		w.Write([]byte("internal details the receiver echoes back"))
+	}))
+	defer receiver.Close()
+
+	attempt, status := dispatchOnce(t, receiver.URL, egressClient(allowAll))
+	if status != models.DeliverySucceeded || attempt.Status_code != http.StatusOK || attempt.Error != "" {
+		t.Errorf("attempt = %+v, status %q, want a success", attempt, status)
+	}
+	logged, _ := json.Marshal(attempt)
+	if strings.Contains(string(logged), "internal details") {
+		t.Errorf("the delivery log keeps the response body: %s", logged)
+	}
+}
+
+func TestDispatcherRefusesInternalAddresses(t *testing.T) {
+	var hits atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer receiver.Close()
+
+	attempt, status := dispatchOnce(t, receiver.URL, egressClient(publicAddress))
+	if status != models.DeliveryPending || !strings.Contains(attempt.Error, "is not allowed") {
+		t.Errorf("attempt = %+v, status %q, want a refused dial", attempt, status)
+	}
+	if hits.Load() != 0 {
+		t.Errorf("the receiver on a loopback address was called")
+	}
+}
+
+func TestDispatcherDoesNotFollowRedirects(t *testing.T) {
+	var hits atomic.Int32
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer internal.Close()
+	receiver := httptest.NewServer(http.RedirectHandler(internal.URL, http.StatusTemporaryRedirect))
+	defer receiver.Close()
+
+	attempt, status := dispatchOnce(t, receiver.URL, egressClient(allowAll))
+	if status != models.DeliveryPending || attempt.Status_code != http.StatusTemporaryRedirect || attempt.Error != errRedirect.Error() {
+		t.Errorf("attempt = %+v, status %q, want a refused redirect", attempt, status)
+	}
+	if hits.Load() != 0 {
+		t.Errorf("the redirect was followed")
+	}
+}
+
+func TestPublicAddress(t *testing.T) {
+	tests := []struct {
+		ip     string
+		public bool
+	}{
+		{ip: "93.184.216.34", public: true},
+		{ip: "2606:2800:220:1:248:1893:25c8:1946", public: true},
+		{ip: "127.0.0.1"},
+		{ip: "::1"},
+		{ip: "10.1.2.3"},
+		{ip: "172.16.0.1"},
+		{ip: "192.168.1.1"},
+		{ip: "fd00::1"},
+		{ip: "169.254.169.254"},
+		{ip: "fe80::1"},
+		{ip: "0.0.0.0"},
+		{ip: "224.0.0.1"},
+		{ip: "::ffff:127.0.0.1"},
+	}
+	for _, test := range tests {
+		if got := publicAddress(net.ParseIP(test.ip)); got != test.public {
+			t.Errorf("publicAddress(%s) = %v, want %v", test.ip, got, test.public)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package modules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// errRedirect is reported for a receiver answering with a redirect, which is
+// not followed so a public URL cannot bounce a delivery to an internal one.
+var errRedirect = errors.New("receiver responded with a redirect, which is not followed")
+
+// publicAddress reports whether ip may receive deliveries: anything but
+// loopback, private, link-local (which includes cloud metadata endpoints),
+// multicast and unspecified addresses.
+func publicAddress(ip net.IP) bool {
+	return !(ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() ||
+		ip.IsUnspecified())
+}
+
+// egressClient sends deliveries only to addresses allowed accepts. The check
+// runs on the address actually dialed, after DNS resolution, so a hostname
+// that resolves to an internal address is refused too. Proxies from the
+// environment are ignored since they would hide the address, and redirects
+// are not followed. Each request is traced and carries the W3C traceparent.
+func egressClient(allowed func(ip net.IP) bool) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control: func(network string, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !allowed(ip) {
+				return fmt.Errorf("delivering to %s is not allowed", host)
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = func(ctx context.Context, network string, address string) (net.Conn, error) {
+		return dialer.DialContext(ctx, network, address)
+	}
+	return &http.Client{
+		Transport: otelhttp.NewTransport(transport),
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,83 @@
+package modules
+
+import (
+	"context"
+	"time"
+
+	eventModels "somdeep-demo-app/src/events/models"
+	"somdeep-demo-app/src/webhook/interfaces"
+	"somdeep-demo-app/src/webhook/models"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// webhookFanout is an EventPublisher that turns each event into one pending
+// delivery per matching webhook of the user the event belongs to. It is meant
+// to sit behind the outbox relay, so a failed fan-out is retried, and repeated
+// fan-outs of the same event are ignored by the repository.
+type webhookFanout struct {
+	webhookRepository interfaces.WebhookRepository
+	dispatcher        *Dispatcher
+}
+
+func NewWebhookFanout(webhookRepository interfaces.WebhookRepository, dispatcher *Dispatcher) *webhookFanout {
+	return &webhookFanout{
+		webhookRepository: webhookRepository,
+		dispatcher:        dispatcher,
+	}
+}
+
+func (f *webhookFanout) Publish(ctx context.Context, events ...eventModels.Event) error {
+	var deliveries []models.WebhookDelivery
+	webhooksByUser := map[string][]models.Webhook{}
+	for _, event := range events {
+		userId := event.UserId()
+		if userId == "" {
+			continue
+		}
+		webhooks, cached := webhooksByUser[userId]
+		if !cached {
+			var err error
+			if webhooks, err = f.webhookRepository.GetActiveWebhooksByUserId(ctx, userId); err != nil {
+				return err
+			}
+			webhooksByUser[userId] = webhooks
+		}
+		for _, webhook := range webhooks {
+			if webhook.Subscribes(event.Type) {
+				deliveries = append(deliveries, newDelivery(webhook, event))
+			}
+		}
+	}
+
+	if len(deliveries) == 0 {
+		return nil
+	}
+	if err := f.webhookRepository.AddDeliveries(ctx, deliveries); err != nil {
+		return err
+	}
+	if f.dispatcher != nil {
+		f.dispatcher.Notify()
+	}
+	return nil
+}
+
+func (f *webhookFanout) Close() error {
+	return nil
+}
+
+func newDelivery(webhook models.Webhook, event eventModels.Event) models.WebhookDelivery {
+	now := time.Now()
+	return models.WebhookDelivery{
+		ID:              primitive.NewObjectID(),
+		Delivery_id:     uuid.New().String(),
+		Webhook_id:      webhook.Webhook_id,
+		User_id:         webhook.User_id,
+		Event:           event,
+		Status:          models.DeliveryPending,
+		Created_at:      now,
+		Next_attempt_at: now,
+		Log:             []models.WebhookAttempt{},
+	}
+}
@@ -0,0 +1,56 @@
+package modules
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers sent with every delivery.
+const (
+	HeaderWebhookId = "X-Webhook-Id"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+const signaturePrefix = "sha256="
+
+// Sign returns the X-Webhook-Signature value for a body sent at timestamp
+// (Unix seconds): "sha256=" followed by the hex HMAC-SHA256 of
+// "<timestamp>.<body>" keyed with the webhook secret. Including the timestamp
+// lets receivers reject replayed deliveries.
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature is what a receiver runs on an incoming delivery. A zero
+// tolerance skips the timestamp age check.
+func VerifySignature(secret string, timestamp string, body []byte, signature string, tolerance time.Duration) error {
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return errors.New("webhook: unsupported signature scheme")
+	}
+	if !hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature)) {
+		return errors.New("webhook: signature mismatch")
+	}
+	if tolerance > 0 {
+		sent, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			return errors.New("webhook: invalid timestamp")
+		}
+		age := time.Since(time.Unix(sent, 0))
+		if age > tolerance || age < -tolerance {
+			return errors.New("webhook: timestamp outside tolerance")
+		}
+	}
+	return nil
+}
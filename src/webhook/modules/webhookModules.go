@@ -0,0 +1,474 @@
+package modules
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"somdeep-demo-app/src/apperrors"
+	"somdeep-demo-app/src/database"
+	eventModels "somdeep-demo-app/src/events/models"
+	"somdeep-demo-app/src/timeouts"
+	userInterfaces "somdeep-demo-app/src/user/interfaces"
+	"somdeep-demo-app/src/webhook/interfaces"
+	"somdeep-demo-app/src/webhook/models"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var validate = validator.New()
+
+// eventPatterns are the values accepted in a webhook's event_types filter.
+var eventPatterns = map[string]bool{
+	"*":                         true,
+	"user.*":                    true,
+	"customer.*":                true,
+	eventModels.UserCreated:     true,
+	eventModels.UserUpdated:     true,
+	eventModels.UserDeleted:     true,
+	eventModels.CustomerCreated: true,
+	eventModels.CustomerUpdated: true,
+	eventModels.CustomerDeleted: true,
+}
+
+var deliveryStatuses = map[string]bool{
+	"":                       true,
+	models.DeliveryPending:   true,
+	models.DeliverySucceeded: true,
+	models.DeliveryDead:      true,
+}
+
+type webhookService struct {
+	webhookRepository interfaces.WebhookRepository
+	userRepository    userInterfaces.UserRepository
+	dispatcher        *Dispatcher
+	timeouts          timeouts.Timeouts
+}
+
+// NewWebhookService returns a WebhookService whose methods are each bounded
+// by timeouts as well as the caller's context.
+func NewWebhookService(webhookRepository interfaces.WebhookRepository, userRepository userInterfaces.UserRepository, dispatcher *Dispatcher, timeouts timeouts.Timeouts) interfaces.WebhookService {
+	return &webhookService{
+		webhookRepository: webhookRepository,
+		userRepository:    userRepository,
+		dispatcher:        dispatcher,
+		timeouts:          timeouts,
+	}
+}
+
+// AddWebhook generates a secret when none is given. The secret is only ever
+// returned by this call.
+func (s *webhookService) AddWebhook(ctx context.Context, userId string, webhook models.Webhook) (response interfaces.Response, err error) {
+	ctx, cancel := s.timeouts.Context(ctx, "AddWebhook")
+	defer cancel()
+
+	var res interfaces.Response
+
+	_, err = s.userRepository.GetUserByUserId(ctx, userId)
+	if err != nil {
+		res.Error = err.Error()
+		res.Message = "The user associated with webhook is not present or is deleted"
+		res.Data = nil
+		res.Status, err = database.ClassifyError(err, res.Message)
+		return res, err
+	}
+
+	if err = validateWebhook(webhook); err != nil {
+		res.Status = http.StatusBadRequest
+		res.Error = err.Error()
+		res.Message = "Validation Error"
+		res.Data = nil
+		return res, err
+	}
+
+	if webhook.Secret == nil {
+		secret, secretErr := newSecret()
+		if secretErr != nil {
+			res.Status = http.StatusInternalServerError
+			res.Error = secretErr.Error()
+			res.Message = "Webhook was not created"
+			res.Data = nil
+			return res, secretErr
+		}
+		webhook.Secret = &secret
+	}
+	if webhook.Active == nil {
+		active := true
+		webhook.Active = &active
+	}
+	if webhook.Event_types == nil {
+		webhook.Event_types = []string{}
+	}
+
+	webhook.Created_at = time.Now()
+	webhook.Updated_at = webhook.Created_at
+	webhook.ID = primitive.NewObjectID()
+	webhook.Webhook_id = uuid.New().String()
+	webhook.User_id = userId
+
+	if err = s.webhookRepository.AddWebhook(ctx, webhook); err != nil {
+		res.Status = http.StatusInternalServerError
+		res.Error = err.Error()
+		res.Message = "Webhook was not created"
+		res.Data = nil
+		return res, err
+	}
+
+	res.Status = http.StatusCreated
+	res.Error = "NA"
+	res.Message = "Webhook Added Successfully"
+	res.Data = webhook
+	return res, nil
+}
+
+func (s *webhookService) GetWebhooks(ctx context.Context, userId string) (response interfaces.Response, err error) {
+	ctx, cancel := s.timeouts.Context(ctx, "GetWebhooks")
+	defer cancel()
+
+	var res interfaces.Response
+
+	webhooks, err := s.webhookRepository.GetWebhooksByUserId(ctx, userId)
+	if err != nil {
+		res.Status = http.StatusInternalServerError
+		res.Error = err.Error()
+		res.Message = "error occured while listing webhooks"
+		res.Data = nil
+		return res, err
+	}
+	for i := range webhooks {
+		webhooks[i].Secret = nil
+	}
+
+	res.Status = http.StatusOK
+	res.Error = "NA"
+	res.Message = "Records Fetched Successfully"
+	res.Data = webhooks
+	return res, nil
+}
+
+func (s *webhookService) GetWebhook(ctx context.Context, userId string, webhookId string) (response interfaces.Response, err error) {
+	ctx, cancel := s.timeouts.Context(ctx, "GetWebhook")
+	defer cancel()
+
+	webhook, res, err := s.findWebhook(ctx, userId, webhookId)
+	if err != nil {
+		return res, err
+	}
+	webhook.Secret = nil
+
+	res.Status = http.StatusOK
+	res.Error = "NA"
+	res.Message = "Record Fetched Successfully"
+	res.Data = webhook
+	return res, nil
+}
+
+// UpdateWebhook changes only the fields present in webhook. Setting a new
+// secret takes effect from the next delivery attempt on.
+func (s *webhookService) UpdateWebhook(ctx context.Context, userId string, webhookId string, webhook models.Webhook) (response interfaces.Response, err error) {
+	ctx, cancel := s.timeouts.Context(ctx, "UpdateWebhook")
+	defer cancel()
+
+	var res interfaces.Response
+	var updateObject primitive.D
+	var fields []string
+
+	if webhook.Url != nil {
+		updateObject = append(updateObject, bson.E{Key: "url", Value: webhook.Url})
+		fields = append(fields, "Url")
+	}
+	if webhook.Event_types != nil {
+		updateObject = append(updateObject, bson.E{Key: "event_types", Value: webhook.Event_types})
+		fields = append(fields, "Event_types")
+	}
+	if webhook.Secret != nil {
+		updateObject = append(updateObject, bson.E{Key: "secret", Value: webhook.Secret})
+		fields = append(fields, "Secret")
+	}
+	if webhook.Active != nil {
+		updateObject = append(updateObject, bson.E{Key: "active", Value: webhook.Active})
+	}
+
+	if err = validatePartialWebhook(webhook, fields); err != nil {
+		res.Status = http.StatusBadRequest
+		res.Error = err.Error()
+		res.Message = "Validation Error"
+		res.Data = nil
+		return res, err
+	}
+
+	updateObject = append(updateObject, bson.E{Key: "updated_at", Value: time.Now()})
+
+	result, err := s.webhookRepository.UpdateWebhookByWebhookId(ctx, userId, webhookId, updateObject)
+	if err != nil {
+		res.Status = http.StatusInternalServerError
+		res.Error = err.Error()
+		res.Message = "Webhook update failed"
+		res.Data = nil
+		return res, err
+	}
+
+	if result.MatchedCount == 0 {
+		res.Status = http.StatusNotFound
+		res.Error = "NA"
+		res.Message = "Webhook not found or is already deleted"
+		res.Data = nil
+		return res, apperrors.NotFound(res.Message)
+	}
+
+	return s.GetWebhook(ctx, userId, webhookId)
+}
+
+func (s *webhookService) DeleteWebhook(ctx context.Context, userId string, webhookId string) (response interfaces.Response, err error) {
+	ctx, cancel := s.timeouts.Context(ctx, "DeleteWebhook")
+	defer cancel()
+
+	var res interfaces.Response
+
+	result, err := s.webhookRepository.DeleteWebhookByWebhookId(ctx, userId, webhookId)
+	if err != nil {
+		res.Status = http.StatusInternalServerError
+		res.Error = err.Error()
+		res.Message = "Failed to delete webhook"
+		res.Data = nil
+		return res, err
+	}
+
+	if result.DeletedCount == 0 {
+		res.Status = http.StatusNotFound
+		res.Error = "NA"
+		res.Message = "Webhook not found or is already deleted"
+		res.Data = nil
+		return res, apperrors.NotFound(res.Message)
+	}
+
+	res.Status = http.StatusOK
+	res.Error = "NA"
+	res.Message = "Webhook deleted successfully"
+	res.Data = models.WebhookRef{Webhook_id: webhookId}
+	return res, nil
+}
+
+// PingWebhook queues a webhook.ping delivery, which is sent even when the
+// webhook is disabled or its filter would not match.
+func (s *webhookService) PingWebhook(ctx context.Context, userId string, webhookId string) (response interfaces.Response, err error) {
+	ctx, cancel := s.timeouts.Context(ctx, "PingWebhook")
+	defer cancel()
+
+	webhook, res, err := s.findWebhook(ctx, userId, webhookId)
+	if err != nil {
+		return res, err
+	}
+
+	event, err := eventModels.NewEvent(models.WebhookPing, webhookId, map[string]string{"user_id": userId, "webhook_id": webhookId})
+	if err != nil {
+		res.Status = http.StatusInternalServerError
+		res.Error = err.Error()
+		res.Message = "Failed to queue ping"
+		res.Data = nil
+		return res, err
+	}
+
+	delivery := newDelivery(webhook, event)
+	if err = s.webhookRepository.AddDeliveries(ctx, []models.WebhookDelivery{delivery}); err != nil {
+		res.Status = http.StatusInternalServerError
+		res.Error = err.Error()
+		res.Message = "Failed to queue ping"
+		res.Data = nil
+		return res, err
+	}
+
+	s.dispatcher.Notify()
+	res.Status = http.StatusAccepted
+	res.Error = "NA"
+	res.Message = "Ping queued for delivery"
+	res.Data = models.DeliveryRef{Delivery_id: delivery.Delivery_id, Webhook_id: webhookId}
+	return res, nil
+}
+
+func (s *webhookService) GetDeliveries(ctx context.Context, userId string, webhookId string, status string, recordPerPage int, page int, startIndex int) (response interfaces.Response, err error) {
+	ctx, cancel := s.timeouts.Context(ctx, "GetDeliveries")
+	defer cancel()
+
+	var res interfaces.Response
+
+	if !deliveryStatuses[status] {
+		err = fmt.Errorf("unknown delivery status %q", status)
+		res.Status = http.StatusBadRequest
+		res.Error = err.Error()
+		res.Message = "Validation Error"
+		res.Data = nil
+		return res, err
+	}
+
+	if _, res, err = s.findWebhook(ctx, userId, webhookId); err != nil {
+		return res, err
+	}
+
+	result, err := s.webhookRepository.GetDeliveries(ctx, webhookId, status, startIndex, recordPerPage)
+	if err != nil {
+		res.Status = http.StatusInternalServerError
+		res.Error = err.Error()
+		res.Message = "error occured while listing webhook deliveries"
+		res.Data = nil
+		return res, err
+	}
+
+	deliveries := []models.WebhookDelivery{}
+	if err = result.All(ctx, &deliveries); err != nil {
+		res.Status = http.StatusInternalServerError
+		res.Error = err.Error()
+		res.Message = "error occured while listing webhook deliveries"
+		res.Data = nil
+		return res, err
+	}
+
+	res.Status = http.StatusOK
+	res.Error = "NA"
+	res.Message = "Records Fetched Successfully"
+	res.Data = deliveries
+	return res, nil
+}
+
+func (s *webhookService) GetDelivery(ctx context.Context, userId string, webhookId string, deliveryId string) (response interfaces.Response, err error) {
+	ctx, cancel := s.timeouts.Context(ctx, "GetDelivery")
+	defer cancel()
+
+	var res interfaces.Response
+
+	if _, res, err = s.findWebhook(ctx, userId, webhookId); err != nil {
+		return res, err
+	}
+
+	delivery, err := s.webhookRepository.GetDeliveryByDeliveryId(ctx, webhookId, deliveryId)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		res.Status = http.StatusNotFound
+		res.Error = "NA"
+		res.Message = "Delivery not found"
+		res.Data = nil
+		return res, apperrors.NotFound(res.Message)
+	}
+	if err != nil {
+		res.Status = http.StatusInternalServerError
+		res.Error = err.Error()
+		res.Message = "Error occured while fetching the delivery"
+		res.Data = nil
+		return res, err
+	}
+
+	res.Status = http.StatusOK
+	res.Error = "NA"
+	res.Message = "Record Fetched Successfully"
+	res.Data = delivery
+	return res, nil
+}
+
+// Redeliver sends a delivery again with a fresh retry budget, whether it
+// succeeded, is still retrying or is dead.
+func (s *webhookService) Redeliver(ctx context.Context, userId string, webhookId string, deliveryId string) (response interfaces.Response, err error) {
+	ctx, cancel := s.timeouts.Context(ctx, "Redeliver")
+	defer cancel()
+
+	var res interfaces.Response
+
+	if _, res, err = s.findWebhook(ctx, userId, webhookId); err != nil {
+		return res, err
+	}
+
+	requeued, err := s.webhookRepository.RequeueDelivery(ctx, webhookId, deliveryId, time.Now())
+	if err != nil {
+		res.Status = http.StatusInternalServerError
+		res.Error = err.Error()
+		res.Message = "Failed to requeue delivery"
+		res.Data = nil
+		return res, err
+	}
+
+	if !requeued {
+		res.Status = http.StatusNotFound
+		res.Error = "NA"
+		res.Message = "Delivery not found"
+		res.Data = nil
+		return res, apperrors.NotFound(res.Message)
+	}
+
+	s.dispatcher.Notify()
+	res.Status = http.StatusAccepted
+	res.Error = "NA"
+	res.Message = "Delivery queued for redelivery"
+	res.Data = models.DeliveryRef{Delivery_id: deliveryId, Webhook_id: webhookId}
+	return res, nil
+}
+
+// findWebhook loads a webhook of the user, filling in the response when it
+// cannot be found.
+func (s *webhookService) findWebhook(ctx context.Context, userId string, webhookId string) (models.Webhook, interfaces.Response, error) {
+	var res interfaces.Response
+
+	webhook, err := s.webhookRepository.GetWebhookByWebhookId(ctx, userId, webhookId)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		res.Status = http.StatusNotFound
+		res.Error = "NA"
+		res.Message = "Webhook not found or is already deleted"
+		res.Data = nil
+		return webhook, res, apperrors.NotFound(res.Message)
+	}
+	if err != nil {
+		res.Status = http.StatusInternalServerError
+		res.Error = err.Error()
+		res.Message = "Error occured while fetching the webhook"
+		res.Data = nil
+		return webhook, res, err
+	}
+	return webhook, res, nil
+}
+
+func validateWebhook(webhook models.Webhook) error {
+	if err := validate.Struct(webhook); err != nil {
+		return apperrors.FromValidator("Validation Error", err, webhook)
+	}
+	return validateEventTypes(webhook.Event_types)
+}
+
+func validatePartialWebhook(webhook models.Webhook, fields []string) error {
+	if len(fields) > 0 {
+		if err := validate.StructPartial(webhook, fields...); err != nil {
+			return apperrors.FromValidator("Validation Error", err, webhook)
+		}
+	}
+	return validateEventTypes(webhook.Event_types)
+}
+
+func validateEventTypes(eventTypes []string) error {
+	var unknown []string
+	for _, eventType := range eventTypes {
+		if !eventPatterns[eventType] {
+			unknown = append(unknown, eventType)
+		}
+	}
+	if len(unknown) > 0 {
+		return apperrors.Validation("Validation Error", apperrors.FieldError{
+			Field:   "event_types",
+			Rule:    "oneof",
+			Message: "has unknown event types: " + strings.Join(unknown, ", "),
+		})
+	}
+	return nil
+}
+
+func newSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}